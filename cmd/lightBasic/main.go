@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+	"runtime"
+
+	"github.com/henghuang/opengl-go/examples"
+	_ "github.com/henghuang/opengl-go/examples/lightbasic"
+)
+
+func init() {
+	// GLFW event handling must run on the main OS thread
+	runtime.LockOSThread()
+}
+
+func main() {
+	if err := examples.Run("lightBasic"); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,64 @@
+// Command opengl-go lists and runs the registered examples, and checks
+// their shaders.
+//
+//	opengl-go list
+//	opengl-go run camera
+//	opengl-go shaderlint
+//
+// While an example is running, the number keys 1-9 switch to the example
+// at that position in the list.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+
+	"github.com/henghuang/opengl-go/examples"
+	_ "github.com/henghuang/opengl-go/examples/camera"
+	_ "github.com/henghuang/opengl-go/examples/carbon"
+	_ "github.com/henghuang/opengl-go/examples/glsl"
+	_ "github.com/henghuang/opengl-go/examples/lightbasic"
+	_ "github.com/henghuang/opengl-go/examples/lightcolor"
+	_ "github.com/henghuang/opengl-go/examples/multiplecubes"
+	_ "github.com/henghuang/opengl-go/examples/stencil"
+	_ "github.com/henghuang/opengl-go/examples/texture"
+	_ "github.com/henghuang/opengl-go/examples/transformation"
+)
+
+func init() {
+	// GLFW event handling must run on the main OS thread
+	runtime.LockOSThread()
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: opengl-go list")
+	fmt.Fprintln(os.Stderr, "       opengl-go run <example>")
+	fmt.Fprintln(os.Stderr, "       opengl-go shaderlint [flags] [shader files]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "list":
+		for i, name := range examples.Names() {
+			fmt.Printf("%d  %s\n", i+1, name)
+		}
+	case "run":
+		if len(os.Args) != 3 {
+			usage()
+		}
+		if err := examples.Run(os.Args[2]); err != nil {
+			log.Fatalln(err)
+		}
+	case "shaderlint":
+		os.Exit(shaderlint(os.Args[2:]))
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/henghuang/opengl-go/examples/shaders"
+	"github.com/henghuang/opengl-go/glutil"
+	"github.com/henghuang/opengl-go/glutil/glsl"
+)
+
+// defineFlags collects -D NAME[=VALUE] flags.
+type defineFlags map[string]string
+
+func (d defineFlags) String() string {
+	var s []string
+	for name, value := range d {
+		s = append(s, name+"="+value)
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+func (d defineFlags) Set(v string) error {
+	parts := strings.SplitN(v, "=", 2)
+	if parts[0] == "" {
+		return fmt.Errorf("empty define")
+	}
+	if len(parts) == 1 {
+		parts = append(parts, "")
+	}
+	d[parts[0]] = parts[1]
+	return nil
+}
+
+// lookupMethods are the glutil.Program methods, and the glutil vertex
+// attribute constructors, that look up a name given as their first
+// argument.
+var lookupMethods = map[string]glsl.LookupKind{
+	"Uniform":        glsl.UniformLookup,
+	"SetFloat":       glsl.UniformLookup,
+	"SetVec2":        glsl.UniformLookup,
+	"SetVec3":        glsl.UniformLookup,
+	"SetVec4":        glsl.UniformLookup,
+	"SetMat3":        glsl.UniformLookup,
+	"SetMat4":        glsl.UniformLookup,
+	"SetInt":         glsl.UniformLookup,
+	"SetUint":        glsl.UniformLookup,
+	"SetBool":        glsl.UniformLookup,
+	"Attrib":         glsl.AttribLookup,
+	"VertexAttrib":   glsl.AttribLookup,
+	"PositionAttrib": glsl.AttribLookup,
+	"UVAttrib":       glsl.AttribLookup,
+	"NormalAttrib":   glsl.AttribLookup,
+	"TangentAttrib":  glsl.AttribLookup,
+	"ColorAttrib":    glsl.AttribLookup,
+	"Block":          glsl.BlockLookup,
+}
+
+// glLookups are the gl functions that take a gl.Str name.
+var glLookups = map[string]glsl.LookupKind{
+	"GetUniformLocation":   glsl.UniformLookup,
+	"GetAttribLocation":    glsl.AttribLookup,
+	"BindAttribLocation":   glsl.AttribLookup,
+	"GetFragDataLocation":  glsl.FragDataLookup,
+	"BindFragDataLocation": glsl.FragDataLookup,
+	"GetUniformBlockIndex": glsl.BlockLookup,
+}
+
+// goPackage is what shaderlint finds in the Go files of one directory.
+type goPackage struct {
+	programs [][]string // shader files of each program, in call order
+	lookups  []glsl.Lookup
+}
+
+// shaderlint checks shader programs, and the names Go code looks up in
+// them. With file arguments it lints those files as one program;
+// otherwise it finds programs and lookups in the Go sources under -src.
+// It returns the exit status: 1 if there were errors.
+func shaderlint(args []string) int {
+	flags := flag.NewFlagSet("shaderlint", flag.ExitOnError)
+	dir := flags.String("dir", shaders.Dir, "directory the shader files are read from")
+	src := flags.String("src", ".", "Go source tree to scan for programs and lookups")
+	defines := defineFlags{}
+	flags.Var(defines, "D", "define `NAME[=VALUE]` for the shaders; may be repeated")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: opengl-go shaderlint [-dir dir] [-src dir] [-D NAME[=VALUE]] [shader files]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	loader := glutil.NewShaderLoader(os.DirFS(*dir))
+	loader.Defines = defines
+	l := &linter{dir: *dir, loader: loader, shaders: map[string]*glsl.Shader{}}
+
+	if flags.NArg() > 0 {
+		l.lintProgram(flags.Args())
+		return l.status()
+	}
+
+	packages, err := scanGo(*src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	dirs := make([]string, 0, len(packages))
+	for d := range packages {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+
+	linted := map[string]bool{}
+	for _, d := range dirs {
+		pkg := packages[d]
+		var stages []*glsl.Shader
+		for _, files := range pkg.programs {
+			key := strings.Join(files, " + ")
+			if !linted[key] {
+				linted[key] = true
+				l.lintProgram(files)
+			}
+			for _, f := range files {
+				if s := l.shader(f, false); s != nil {
+					stages = append(stages, s)
+				}
+			}
+		}
+		if len(pkg.programs) == 0 && len(pkg.lookups) > 0 {
+			// Programs made elsewhere, such as shader variants: check
+			// against everything any shader may declare.
+			stages = l.allShaders()
+		}
+		l.report(glsl.CheckLookups(pkg.lookups, stages...))
+	}
+	return l.status()
+}
+
+type linter struct {
+	dir     string
+	loader  *glutil.ShaderLoader
+	shaders map[string]*glsl.Shader
+	errors  int
+}
+
+func (l *linter) status() int {
+	if l.errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (l *linter) report(problems []glsl.Problem) {
+	for _, p := range problems {
+		fmt.Println(p)
+		if p.Severity == glsl.Error {
+			l.errors++
+		}
+	}
+}
+
+func (l *linter) fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	l.errors++
+}
+
+func (l *linter) lintProgram(files []string) {
+	var stages []*glsl.Shader
+	for _, f := range files {
+		s := l.shader(f, false)
+		if s == nil {
+			return
+		}
+		stages = append(stages, s)
+	}
+	l.report(glsl.Lint(stages...))
+}
+
+// shader loads and parses a shader file once. With all set, every
+// conditional branch is kept; see glsl.LexAll.
+func (l *linter) shader(name string, all bool) *glsl.Shader {
+	key := fmt.Sprintf("%s %t", name, all)
+	if s, ok := l.shaders[key]; ok {
+		return s
+	}
+	l.shaders[key] = nil
+
+	stage, ok := glsl.StageOf(name)
+	if !ok {
+		l.fail(fmt.Errorf("%s: unknown shader stage", filepath.Join(l.dir, name)))
+		return nil
+	}
+	source, err := l.loader.Load(name)
+	if err != nil {
+		l.fail(fmt.Errorf("%s: %v", filepath.Join(l.dir, name), err))
+		return nil
+	}
+	lex := glsl.Lex
+	if all {
+		lex = glsl.LexAll
+	}
+	toks, err := lex(source.Code)
+	var s *glsl.Shader
+	if err == nil {
+		s, err = glsl.ParseTokens(stage, toks)
+	}
+	if err != nil {
+		l.fail(fmt.Errorf("%s: %v", filepath.Join(l.dir, name), err))
+		return nil
+	}
+	for _, f := range source.Files {
+		s.Files = append(s.Files, filepath.Join(l.dir, f.Name))
+	}
+	l.shaders[key] = s
+	return s
+}
+
+// allShaders parses every shader file in the directory, keeping all
+// conditional branches.
+func (l *linter) allShaders() []*glsl.Shader {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		l.fail(err)
+		return nil
+	}
+	var stages []*glsl.Shader
+	for _, e := range entries {
+		if _, ok := glsl.StageOf(e.Name()); !ok || e.IsDir() {
+			continue
+		}
+		if s := l.shader(e.Name(), true); s != nil {
+			stages = append(stages, s)
+		}
+	}
+	return stages
+}
+
+// scanGo parses the Go files under root and returns, per directory, the
+// programs made from literal shader file names and the names looked up
+// through gl.Str("name\x00") or glutil.Program methods.
+func scanGo(root string) (map[string]*goPackage, error) {
+	fset := token.NewFileSet()
+	packages := map[string]*goPackage{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor" || d.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		pkg := packages[filepath.Dir(path)]
+		if pkg == nil {
+			pkg = &goPackage{}
+			packages[filepath.Dir(path)] = pkg
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				pkg.scanCall(fset, call)
+			}
+			return true
+		})
+		return nil
+	})
+	return packages, err
+}
+
+func (pkg *goPackage) scanCall(fset *token.FileSet, call *ast.CallExpr) {
+	var files []string
+	for _, arg := range call.Args {
+		if s, ok := stringLit(arg); ok {
+			if _, ok := glsl.StageOf(s); ok {
+				files = append(files, s)
+			}
+		}
+	}
+	if len(files) >= 2 {
+		pkg.programs = append(pkg.programs, files)
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return
+	}
+	if kind, ok := glLookups[sel.Sel.Name]; ok {
+		for _, arg := range call.Args {
+			if name, ok := glStr(arg); ok {
+				pkg.lookups = append(pkg.lookups, glsl.Lookup{Kind: kind, Name: name, Pos: fset.Position(arg.Pos()).String()})
+			}
+		}
+		return
+	}
+	if kind, ok := lookupMethods[sel.Sel.Name]; ok {
+		if name, ok := stringLit(call.Args[0]); ok {
+			pkg.lookups = append(pkg.lookups, glsl.Lookup{Kind: kind, Name: name, Pos: fset.Position(call.Args[0].Pos()).String()})
+		}
+	}
+}
+
+// glStr returns name for the expression gl.Str("name\x00").
+func glStr(e ast.Expr) (string, bool) {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Str" {
+		return "", false
+	}
+	if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "gl" {
+		return "", false
+	}
+	s, ok := stringLit(call.Args[0])
+	return strings.TrimSuffix(s, "\x00"), ok
+}
+
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
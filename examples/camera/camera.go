@@ -0,0 +1,365 @@
+package camera
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"math"
+	"os"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/henghuang/opengl-go/examples"
+	"github.com/henghuang/opengl-go/examples/shaders"
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+const windowWidth = 800
+const windowHeight = 600
+
+func init() {
+	examples.Register("camera", func() examples.Example {
+		return &demo{
+			//camera init
+			cameraPos:   mgl32.Vec3([3]float32{0, 0, 3}),
+			cameraFront: mgl32.Vec3([3]float32{0, 0, -1}),
+			cameraUp:    mgl32.Vec3([3]float32{0, 1, 0}),
+			// camera mouse inint
+			yaw:   -90, //init cameraFront = {0, 0, -1})
+			pitch: 0,
+		}
+	})
+}
+
+type demo struct {
+	res    glutil.Resources
+	blocks glutil.UniformBlocks
+
+	window *glfw.Window
+
+	program *glutil.Program
+	mesh    *glutil.Mesh
+	texture uint32
+	sky     *examples.Skybox
+
+	frame       examples.Frame
+	frameBuffer *glutil.UniformBuffer
+
+	cameraPos, cameraFront, cameraUp mgl32.Vec3
+	deltaTime                        float64 // Time between current frame and last frame
+
+	yaw, pitch   float32
+	lastX, lastY float32
+	firstMouse   bool
+}
+
+func (d *demo) Init(window *glfw.Window) error {
+	d.window = window
+
+	// set mouse call back
+	window.SetCursorPosCallback(d.mouseMoveCallback)
+
+	// Configure the vertex and fragment shaders
+	loader := glutil.NewShaderLoader(shaders.FS)
+	program, err := loader.NewProgram("cube.vert", "cube.frag")
+	if err != nil {
+		return err
+	}
+	d.program = examples.CheckUniforms(glutil.Reflect(d.res.Program(program)))
+
+	gl.UseProgram(program)
+
+	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/windowHeight, 0.1, 10.0)
+	camera := mgl32.LookAtV(d.cameraPos, d.cameraPos.Add(d.cameraFront), d.cameraUp)
+	d.frame = examples.Frame{Projection: projection, Camera: camera}
+	frame, err := d.blocks.New("Frame", glutil.Std140, &d.frame)
+	if err != nil {
+		return err
+	}
+	d.frameBuffer = frame
+	d.res.Add(d.blocks.Delete)
+	d.blocks.Attach(d.program)
+
+	model := mgl32.Ident4()
+	d.program.SetMat4("model", model)
+
+	d.program.SetInt("tex", 0)
+
+	// Pack the top-left quarter of each picture into one atlas texture.
+	// Even cubes show the first and odd cubes the second.
+	builder := glutil.AtlasBuilder{Padding: 2, Gutter: 4, MipLevels: 2}
+	for _, file := range atlasFiles {
+		img, err := loadQuarter(file)
+		if err != nil {
+			return err
+		}
+		builder.Add(file, img)
+	}
+	atlas, err := builder.Build()
+	if err != nil {
+		return err
+	}
+	texture, err := atlas.Texture(glutil.TextureOptions{Mipmaps: true})
+	if err != nil {
+		return err
+	}
+	d.texture = d.res.Texture(texture)
+
+	// Configure the vertex data: a copy of the cube per atlas region.
+	var vertices []float32
+	for _, file := range atlasFiles {
+		cube := append([]float32(nil), cubeVertices...)
+		atlas.Regions[file].RemapUVs(cube, 5, 3)
+		vertices = append(vertices, cube...)
+	}
+	layout := glutil.VertexLayout{glutil.PositionAttrib("vert"), glutil.UVAttrib("vertTexCoord")}
+	vertices, indices, err := glutil.Weld(vertices, layout.Stride()/4, 0)
+	if err != nil {
+		return err
+	}
+	mesh, err := glutil.NewIndexedMesh(layout, vertices, indices)
+	if err != nil {
+		return err
+	}
+	d.mesh = mesh
+	d.res.Add(mesh.Delete)
+
+	// The sky is a painted panorama turned into a cube map.
+	cubemap, err := glutil.NewCubemapImages([]image.Image{skyPanorama(1024, 512)}, glutil.TextureOptions{})
+	if err != nil {
+		return err
+	}
+	d.sky, err = examples.NewSkybox(loader, cubemap)
+	if err != nil {
+		return err
+	}
+	d.res.Add(d.sky.Delete)
+
+	// Configure global settings
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+	gl.ClearColor(1.0, 1.0, 1.0, 1.0)
+
+	return nil
+}
+
+func (d *demo) Update(dt float64) {
+	//make sure to have same speed in different machine
+	d.deltaTime = dt
+	d.processInput(d.window)
+}
+
+func (d *demo) Render() {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	d.program.Use()
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture)
+
+	// center is z-axi
+	d.frame.Camera = mgl32.LookAtV(d.cameraPos, d.cameraPos.Add(d.cameraFront), d.cameraUp)
+	if err := d.frameBuffer.Update(&d.frame); err != nil {
+		log.Println(err)
+	}
+
+	cube := d.mesh.Count / int32(len(atlasFiles))
+	for i, each := range cubePositions {
+		model_t := mgl32.Translate3D(each[0], each[1], each[2])
+		model_r := mgl32.HomogRotate3D(float32(i)*20, mgl32.Vec3{0, 1, 0})
+		model := model_r.Mul4(model_t)
+
+		d.program.SetMat4("model", model)
+		d.mesh.DrawRange(d.program, int32(i%len(atlasFiles))*cube, cube)
+	}
+
+	d.sky.Draw(d.frame.Projection, d.frame.Camera)
+}
+
+func (d *demo) Shutdown() {
+	d.res.Free()
+}
+
+var atlasFiles = []string{"square.png", "square2.png"}
+
+// loadQuarter decodes an image file, or examples.StandInImage if it is
+// missing, and returns its top-left quarter.
+func loadQuarter(file string) (image.Image, error) {
+	var img image.Image
+	f, err := os.Open(file)
+	switch {
+	case os.IsNotExist(err):
+		img = examples.StandInImage()
+	case err != nil:
+		return nil, err
+	default:
+		defer f.Close()
+		if img, err = glutil.DecodeImage(file, f); err != nil {
+			return nil, err
+		}
+	}
+	b := img.Bounds()
+	quarter := image.NewNRGBA(image.Rect(0, 0, b.Dx()/2, b.Dy()/2))
+	draw.Draw(quarter, quarter.Bounds(), img, b.Min, draw.Src)
+	return quarter, nil
+}
+
+// cubeVertices is a unit cube with X, Y, Z, U, V per vertex and UVs
+// spanning 0-1 on each face, remapped into an atlas region on load.
+var cubeVertices = []float32{
+	// Bottom
+	-0.5, -0.5, -0.5, 0.0, 0.0,
+	0.5, -0.5, -0.5, 1.0, 0.0,
+	-0.5, -0.5, 0.5, 0.0, 1.0,
+	0.5, -0.5, -0.5, 1.0, 0.0,
+	0.5, -0.5, 0.5, 1.0, 1.0,
+	-0.5, -0.5, 0.5, 0.0, 1.0,
+
+	// Top
+	-0.5, 0.5, -0.5, 0.0, 0.0,
+	-0.5, 0.5, 0.5, 0.0, 1.0,
+	0.5, 0.5, -0.5, 1.0, 0.0,
+	0.5, 0.5, -0.5, 1.0, 0.0,
+	-0.5, 0.5, 0.5, 0.0, 1.0,
+	0.5, 0.5, 0.5, 1.0, 1.0,
+
+	// Front
+	-0.5, -0.5, 0.5, 1.0, 0.0,
+	0.5, -0.5, 0.5, 0.0, 0.0,
+	-0.5, 0.5, 0.5, 1.0, 1.0,
+	0.5, -0.5, 0.5, 0.0, 0.0,
+	0.5, 0.5, 0.5, 0.0, 1.0,
+	-0.5, 0.5, 0.5, 1.0, 1.0,
+
+	// Back
+	-0.5, -0.5, -0.5, 0.0, 0.0,
+	-0.5, 0.5, -0.5, 0.0, 1.0,
+	0.5, -0.5, -0.5, 1.0, 0.0,
+	0.5, -0.5, -0.5, 1.0, 0.0,
+	-0.5, 0.5, -0.5, 0.0, 1.0,
+	0.5, 0.5, -0.5, 1.0, 1.0,
+
+	// Left
+	-0.5, -0.5, 0.5, 0.0, 1.0,
+	-0.5, 0.5, -0.5, 1.0, 0.0,
+	-0.5, -0.5, -0.5, 0.0, 0.0,
+	-0.5, -0.5, 0.5, 0.0, 1.0,
+	-0.5, 0.5, 0.5, 1.0, 1.0,
+	-0.5, 0.5, -0.5, 1.0, 0.0,
+
+	// Right
+	0.5, -0.5, 0.5, 1.0, 1.0,
+	0.5, -0.5, -0.5, 1.0, 0.0,
+	0.5, 0.5, -0.5, 0.0, 0.0,
+	0.5, -0.5, 0.5, 1.0, 1.0,
+	0.5, 0.5, -0.5, 0.0, 0.0,
+	0.5, 0.5, 0.5, 0.0, 1.0,
+}
+
+// skyPanorama paints an equirectangular sky: blue overhead, a pale
+// horizon, brown ground below and a sun ahead and to the right.
+func skyPanorama(width, height int) image.Image {
+	zenith := [3]float64{40, 90, 190}
+	horizon := [3]float64{200, 220, 240}
+	ground := [3]float64{90, 80, 70}
+	sunLon, sunLat := 0.5, 0.4
+	sun := mgl32.Vec3{float32(math.Sin(sunLon) * math.Cos(sunLat)), float32(math.Sin(sunLat)), float32(-math.Cos(sunLon) * math.Cos(sunLat))}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		lat := math.Pi/2 - math.Pi*(float64(y)+0.5)/float64(height)
+		var c [3]float64
+		if lat >= 0 {
+			c = mix(horizon, zenith, math.Sqrt(lat/(math.Pi/2)))
+		} else {
+			c = mix(horizon, ground, math.Sqrt(-lat/(math.Pi/2)))
+		}
+		for x := 0; x < width; x++ {
+			// Longitude 0 is the middle of the panorama, looking down -Z.
+			lon := 2*math.Pi*(float64(x)+0.5)/float64(width) - math.Pi
+			dir := mgl32.Vec3{float32(math.Sin(lon) * math.Cos(lat)), float32(math.Sin(lat)), float32(-math.Cos(lon) * math.Cos(lat))}
+			glow := math.Pow(math.Max(float64(dir.Dot(sun)), 0), 64)
+			if dir.Dot(sun) > 0.9995 {
+				glow = 1
+			}
+			p := mix(c, [3]float64{255, 250, 220}, glow)
+			img.SetRGBA(x, y, color.RGBA{uint8(p[0]), uint8(p[1]), uint8(p[2]), 255})
+		}
+	}
+	return img
+}
+
+func mix(a, b [3]float64, t float64) [3]float64 {
+	return [3]float64{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t, a[2] + (b[2]-a[2])*t}
+}
+
+var cubePositions = [][]float32{
+	[]float32{0.0, 0.0, 0.0},
+	[]float32{2.0, 5.0, -15.0},
+	[]float32{-1.5, -2.2, -2.5},
+	[]float32{-3.8, -2.0, -12.},
+	[]float32{2.4, -0.4, -3.5},
+	[]float32{-1.7, 3.0, -7.5},
+	[]float32{1.3, -2.0, -2.5},
+	[]float32{1.5, 2.0, -2.5},
+	[]float32{1.5, 0.2, -1.5},
+	[]float32{-1.3, 1.0, -1.5},
+}
+
+func (d *demo) processInput(win *glfw.Window) {
+	cameraSpeed := float32(2.5 * d.deltaTime)
+	if win.GetKey(glfw.KeyW) == glfw.Press {
+		d.cameraPos = d.cameraPos.Add(d.cameraFront.Mul(cameraSpeed))
+	}
+	if win.GetKey(glfw.KeyS) == glfw.Press {
+		d.cameraPos = d.cameraPos.Sub(d.cameraFront.Mul(cameraSpeed))
+	}
+	if win.GetKey(glfw.KeyA) == glfw.Press {
+		cameraRight := d.cameraFront.Cross(d.cameraUp).Normalize()
+		d.cameraPos = d.cameraPos.Add(cameraRight.Mul(cameraSpeed))
+	}
+	if win.GetKey(glfw.KeyD) == glfw.Press {
+		cameraRight := d.cameraFront.Cross(d.cameraUp).Normalize()
+		d.cameraPos = d.cameraPos.Sub(cameraRight.Mul(cameraSpeed))
+	}
+}
+
+// refer to https://learnopengl.com/Getting-started/Camera
+func (d *demo) mouseMoveCallback(w *glfw.Window, xpos float64, ypos float64) {
+	//only handle left mouse button
+	sensitivity := float32(0.1)
+	if w.GetMouseButton(glfw.MouseButtonLeft) == glfw.Press {
+		if d.firstMouse {
+			d.lastX = float32(xpos)
+			d.lastY = float32(ypos)
+			d.firstMouse = false
+		}
+		xoffset := float32(xpos) - d.lastX
+		yoffset := d.lastY - float32(ypos)
+		d.lastX = float32(xpos)
+		d.lastY = float32(ypos)
+
+		xoffset *= sensitivity
+		yoffset *= sensitivity
+
+		d.yaw += xoffset
+		d.pitch += yoffset
+
+		// if pitch > 89.0 {
+		// 	pitch = 89.0
+		// }
+		// if pitch < -89.0 {
+		// 	pitch = -89.0
+		// }
+
+		x := math.Cos(float64(mgl32.DegToRad(d.yaw))) * math.Cos(float64(mgl32.DegToRad(d.pitch)))
+		y := math.Sin(float64(mgl32.DegToRad(d.pitch)))
+		z := math.Sin(float64(mgl32.DegToRad(d.yaw))) * math.Cos(float64(mgl32.DegToRad(d.pitch)))
+		d.cameraFront = mgl32.Vec3([3]float32{float32(x), float32(y), float32(z)}).Normalize()
+		// fmt.Println(x, y, z)
+	} else if w.GetMouseButton(glfw.MouseButtonLeft) == glfw.Release {
+		d.firstMouse = true
+	}
+
+}
@@ -0,0 +1,153 @@
+package carbon
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/henghuang/opengl-go/examples"
+	"github.com/henghuang/opengl-go/examples/shaders"
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+const windowWidth = 800
+const windowHeight = 600
+
+func init() {
+	examples.Register("carbon", func() examples.Example { return &demo{} })
+}
+
+type demo struct {
+	res    glutil.Resources
+	blocks glutil.UniformBlocks
+
+	program *glutil.Program
+	mesh    *glutil.Mesh
+	texture uint32
+
+	angle float64
+	model mgl32.Mat4
+}
+
+func (d *demo) Init(window *glfw.Window) error {
+	// Configure the vertex and fragment shaders
+	loader := glutil.NewShaderLoader(shaders.FS)
+	program, err := loader.NewProgram("cube.vert", "cube.frag")
+	if err != nil {
+		return err
+	}
+	d.program = examples.CheckUniforms(glutil.Reflect(d.res.Program(program)))
+
+	gl.UseProgram(program)
+
+	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/windowHeight, 0.1, 10.0)
+	camera := mgl32.LookAtV(mgl32.Vec3{3, 3, 3}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+	if _, err := d.blocks.New("Frame", glutil.Std140, examples.Frame{Projection: projection, Camera: camera}); err != nil {
+		return err
+	}
+	d.res.Add(d.blocks.Delete)
+	d.blocks.Attach(d.program)
+
+	model := mgl32.Ident4()
+	d.program.SetMat4("model", model)
+
+	d.program.SetInt("tex", 0)
+
+	// Load the texture
+	texture, err := examples.LoadTexture(&d.res, "square.png", glutil.TextureOptions{})
+	if err != nil {
+		return err
+	}
+	d.texture = texture
+
+	// Configure the vertex data
+	layout := glutil.VertexLayout{glutil.PositionAttrib("vert"), glutil.UVAttrib("vertTexCoord")}
+	vertices, indices, err := glutil.Weld(cubeVertices, layout.Stride()/4, 0)
+	if err != nil {
+		return err
+	}
+	mesh, err := glutil.NewIndexedMesh(layout, vertices, indices)
+	if err != nil {
+		return err
+	}
+	d.mesh = mesh
+	d.res.Add(mesh.Delete)
+
+	// Configure global settings
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+	gl.ClearColor(1.0, 1.0, 1.0, 1.0)
+
+	return nil
+}
+
+func (d *demo) Update(dt float64) {
+	d.angle += dt
+	d.model = mgl32.HomogRotate3D(float32(d.angle), mgl32.Vec3{0, 1, 0})
+}
+
+func (d *demo) Render() {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	d.program.Use()
+	d.program.SetMat4("model", d.model)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture)
+
+	d.mesh.Draw(d.program)
+}
+
+func (d *demo) Shutdown() {
+	d.res.Free()
+}
+
+var cubeVertices = []float32{
+	//  X, Y, Z, U, V
+	// Bottom
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+
+	// Top
+	-1.0, 1.0, -1.0, 0.0, 0.0,
+	-1.0, 1.0, 1.0, 0.0, 1.0,
+	1.0, 1.0, -1.0, 1.0, 0.0,
+	1.0, 1.0, -1.0, 1.0, 0.0,
+	-1.0, 1.0, 1.0, 0.0, 1.0,
+	1.0, 1.0, 1.0, 1.0, 1.0,
+
+	// Front
+	-1.0, -1.0, 1.0, 1.0, 0.0,
+	1.0, -1.0, 1.0, 0.0, 0.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+	1.0, -1.0, 1.0, 0.0, 0.0,
+	1.0, 1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+
+	// Back
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	-1.0, 1.0, -1.0, 0.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	-1.0, 1.0, -1.0, 0.0, 1.0,
+	1.0, 1.0, -1.0, 1.0, 1.0,
+
+	// Left
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, -1.0, 1.0, 0.0,
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+	-1.0, 1.0, -1.0, 1.0, 0.0,
+
+	// Right
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, 1.0, -1.0, 0.0, 0.0,
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	1.0, 1.0, -1.0, 0.0, 0.0,
+	1.0, 1.0, 1.0, 0.0, 1.0,
+}
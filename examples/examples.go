@@ -0,0 +1,55 @@
+// Package examples keeps a registry of the demo scenes and runs them in a
+// single window. Each demo lives in its own package under examples/ and
+// registers itself from an init function, so importing it for side effects
+// is enough to make it available:
+//
+//	import _ "github.com/henghuang/opengl-go/examples/camera"
+package examples
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// Example is a demo scene. Init is called once the window's GL context is
+// current, Update and Render once per frame, and Shutdown before another
+// example takes over the window.
+type Example interface {
+	Init(window *glfw.Window) error
+	Update(dt float64)
+	Render()
+	Shutdown()
+}
+
+var registry = map[string]func() Example{}
+
+// Register makes an example available by name. newExample is called each
+// time the example is started so it always begins from a fresh state.
+// Register panics if name is registered twice.
+func Register(name string, newExample func() Example) {
+	if _, dup := registry[name]; dup {
+		panic("examples: Register called twice for " + name)
+	}
+	registry[name] = newExample
+}
+
+// Names returns the registered example names in sorted order. Run maps
+// the number keys 1-9 onto this order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookup(name string) (Example, error) {
+	newExample, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown example %q", name)
+	}
+	return newExample(), nil
+}
@@ -0,0 +1,14 @@
+package examples
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Frame is the per-frame camera and light shared by every program of an
+// example through the Frame uniform block in shaders/frame.glsl. Upload it
+// with glutil.Std140.
+type Frame struct {
+	Projection mgl32.Mat4
+	Camera     mgl32.Mat4
+	ViewPos    mgl32.Vec3
+	LightPos   mgl32.Vec3
+	LightColor mgl32.Vec3
+}
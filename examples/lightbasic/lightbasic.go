@@ -0,0 +1,238 @@
+package lightbasic
+
+import (
+	"log"
+	"math"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/henghuang/opengl-go/examples"
+	"github.com/henghuang/opengl-go/examples/shaders"
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+const windowWidth = 800
+const windowHeight = 600
+
+var lightPos = mgl32.Vec3{0, 0.25, 2}
+var viewPos = mgl32.Vec3{3, 3, 3}
+
+func init() {
+	examples.Register("lightBasic", func() examples.Example { return &demo{} })
+}
+
+type demo struct {
+	res    glutil.Resources
+	blocks glutil.UniformBlocks
+
+	window *glfw.Window
+
+	phong                 *glutil.ReloadableProgram
+	program, programLight *glutil.Program
+	normals               *glutil.Program
+	mesh                  *glutil.Mesh
+	texture2              uint32
+
+	frame       examples.Frame
+	frameBuffer *glutil.UniformBuffer
+
+	time                   float64
+	lightX, lightY, lightZ float32
+
+	showNormals, nDown bool
+}
+
+func (d *demo) Init(window *glfw.Window) error {
+	d.window = window
+
+	// Configure the vertex and fragment shaders
+	// Edit lit.vert or phong.frag while the demo runs to see the change.
+	loader := glutil.NewShaderLoader(shaders.Source())
+	phong, err := loader.NewReloadableProgram("lit.vert", "phong.frag")
+	if err != nil {
+		return err
+	}
+	d.phong = phong
+	d.res.Add(phong.Delete)
+	program := phong.ID()
+	d.program = examples.CheckUniforms(phong.Program())
+	programLight, err := loader.NewProgram("lit.vert", "cube.frag")
+	if err != nil {
+		return err
+	}
+	d.programLight = examples.CheckUniforms(glutil.Reflect(d.res.Program(programLight)))
+	// vertex normals drawn by a geometry shader, toggled with N
+	normals, err := loader.Build("lit.vert", "normals.geom", "color.frag")
+	if err != nil {
+		return err
+	}
+	d.normals = examples.CheckUniforms(glutil.Reflect(d.res.Program(normals)))
+	d.normals.SetMat4("model", mgl32.Ident4())
+	d.normals.SetFloat("normalLength", 0.2)
+	d.normals.SetVec4("color", mgl32.Vec4{1, 1, 0, 1})
+	// first
+	gl.UseProgram(program)
+	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/windowHeight, 0.1, 10.0)
+	camera := mgl32.LookAtV(viewPos, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+
+	model := mgl32.Ident4()
+	d.program.SetMat4("model", model)
+
+	// objectColor := mgl64.Vec3([3]float64{0.5, 0.5, 0.31})
+	d.program.SetVec3("objectColor", mgl32.Vec3{1, 0.5, 0.31})
+
+	d.frame = examples.Frame{
+		Projection: projection,
+		Camera:     camera,
+		ViewPos:    viewPos,
+		LightPos:   lightPos,
+		LightColor: mgl32.Vec3{1, 1, 1},
+	}
+	frame, err := d.blocks.New("Frame", glutil.Std140, &d.frame)
+	if err != nil {
+		return err
+	}
+	d.frameBuffer = frame
+	d.res.Add(d.blocks.Delete)
+	d.blocks.Attach(d.program, d.programLight, d.normals)
+
+	//second
+	gl.UseProgram(programLight)
+	lightModel := mgl32.Ident4()
+	d.programLight.SetMat4("model", lightModel)
+
+	d.programLight.SetInt("tex", 1) //set bind to which texture index
+
+	// Load the texture
+	// texture, err := glutil.NewTexture("square.png")
+	texture2, err := examples.LoadTexture(&d.res, "square2.png", glutil.TextureOptions{})
+	if err != nil {
+		return err
+	}
+	d.texture2 = texture2
+
+	// Configure the vertex data
+
+	// one mesh for the cube, its normals and the light; each program gets
+	// its own vao
+	layout := glutil.VertexLayout{
+		glutil.PositionAttrib("vert"),
+		glutil.UVAttrib("vertTexCoord"),
+		glutil.NormalAttrib("aNormal"),
+	}
+	vertices, indices, err := glutil.Weld(cubeVertices, layout.Stride()/4, 0)
+	if err != nil {
+		return err
+	}
+	mesh, err := glutil.NewIndexedMesh(layout, vertices, indices)
+	if err != nil {
+		return err
+	}
+	d.mesh = mesh
+	d.res.Add(mesh.Delete)
+
+	// Configure global settings
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+	gl.ClearColor(0, 0, 0, 1)
+
+	return nil
+}
+
+func (d *demo) Update(dt float64) {
+	d.phong.Poll()
+
+	nDown := d.window.GetKey(glfw.KeyN) == glfw.Press
+	if nDown && !d.nDown {
+		d.showNormals = !d.showNormals
+	}
+	d.nDown = nDown
+
+	d.time += dt
+	d.lightX = float32(2.0 * math.Sin(d.time))
+	d.lightY = float32(-0.25)
+	d.lightZ = float32(1.5 * math.Cos(d.time))
+}
+
+func (d *demo) Render() {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture2)
+
+	// Render 1
+	d.program.Use()
+	d.frame.LightPos = mgl32.Vec3{d.lightX, d.lightY, d.lightZ}
+	if err := d.frameBuffer.Update(&d.frame); err != nil {
+		log.Println(err)
+	}
+
+	d.mesh.Draw(d.program)
+
+	if d.showNormals {
+		d.normals.Use()
+		d.mesh.Draw(d.normals)
+	}
+
+	// Render2
+	newModel := mgl32.Translate3D(d.lightX, d.lightY, d.lightZ).Mul4(mgl32.Scale3D(0.2, 0.2, 0.2))
+	d.programLight.Use()
+	d.programLight.SetMat4("model", newModel)
+	d.mesh.Draw(d.programLight)
+}
+
+func (d *demo) Shutdown() {
+	d.res.Free()
+}
+
+var cubeVertices = []float32{
+	//  X, Y, Z, U, V,X,Y,Z norm
+	// Bottom
+	-0.5, -0.5, -0.5, 0.0, 0.0, 0.0, 0.0, -1.0,
+	0.5, -0.5, -0.5, 1, 0.0, 0.0, 0.0, -1.0,
+	-0.5, -0.5, 0.5, 0.0, 1, 0.0, 0.0, -1.0,
+	0.5, -0.5, -0.5, 1, 0.0, 0.0, 0.0, -1.0,
+	0.5, -0.5, 0.5, 1.0, 1.0, 0.0, 0.0, -1.0,
+	-0.5, -0.5, 0.5, 0.0, 1, 0.0, 0.0, -1.0,
+
+	// Top
+	-0.5, 0.5, -0.5, 0.0, 0.0, 0.0, 0.0, 1.0,
+	-0.5, 0.5, 0.5, 0.0, 1, 0.0, 0.0, 1.0,
+	0.5, 0.5, -0.5, 1, 0.0, 0.0, 0.0, 1.0,
+	0.5, 0.5, -0.5, 1, 0.0, 0.0, 0.0, 1.0,
+	-0.5, 0.5, 0.5, 0.0, 1, 0.0, 0.0, 1.0,
+	0.5, 0.5, 0.5, 1, 1, 0.0, 0.0, 1.0,
+
+	// Front
+	-0.5, -0.5, 0.5, 1, 0.0, -1.0, 0.0, 0.0,
+	0.5, -0.5, 0.5, 0.0, 0.0, -1.0, 0.0, 0.0,
+	-0.5, 0.5, 0.5, 1, 1, -1.0, 0.0, 0.0,
+	0.5, -0.5, 0.5, 0.0, 0.0, -1.0, 0.0, 0.0,
+	0.5, 0.5, 0.5, 0.0, 1, -1.0, 0.0, 0.0,
+	-0.5, 0.5, 0.5, 1, 1, -1.0, 0.0, 0.0,
+
+	// Back
+	-0.5, -0.5, -0.5, 0.0, 0.0, 1.0, 0.0, 0.0,
+	-0.5, 0.5, -0.5, 0.0, 1, 1.0, 0.0, 0.0,
+	0.5, -0.5, -0.5, 1, 0.0, 1.0, 0.0, 0.0,
+	0.5, -0.5, -0.5, 1, 0.0, 1.0, 0.0, 0.0,
+	-0.5, 0.5, -0.5, 0.0, 1, 1.0, 0.0, 0.0,
+	0.5, 0.5, -0.5, 1, 1, 1.0, 0.0, 0.0,
+
+	// Left
+	-0.5, -0.5, 0.5, 0.0, 1, 0.0, -1.0, 0.0,
+	-0.5, 0.5, -0.5, 1, 0.0, 0.0, -1.0, 0.0,
+	-0.5, -0.5, -0.5, 0.0, 0.0, 0.0, -1.0, 0.0,
+	-0.5, -0.5, 0.5, 0.0, 1, 0.0, -1.0, 0.0,
+	-0.5, 0.5, 0.5, 1, 1, 0.0, -1.0, 0.0,
+	-0.5, 0.5, -0.5, 1, 0.0, 0.0, -1.0, 0.0,
+
+	// Right
+	0.5, -0.5, 0.5, 1, 1, 0.0, 1.0, 0.0,
+	0.5, -0.5, -0.5, 1, 0.0, 0.0, 1.0, 0.0,
+	0.5, 0.5, -0.5, 0.0, 0.0, 0.0, 1.0, 0.0,
+	0.5, -0.5, 0.5, 1, 1, 0.0, 1.0, 0.0,
+	0.5, 0.5, -0.5, 0.0, 0.0, 0.0, 1.0, 0.0,
+	0.5, 0.5, 0.5, 0.0, 1, 0.0, 1.0, 0.0,
+}
@@ -0,0 +1,180 @@
+package lightcolor
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/henghuang/opengl-go/examples"
+	"github.com/henghuang/opengl-go/examples/shaders"
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+const windowWidth = 800
+const windowHeight = 600
+
+func init() {
+	examples.Register("lightColor", func() examples.Example { return &demo{} })
+}
+
+type demo struct {
+	res    glutil.Resources
+	blocks glutil.UniformBlocks
+
+	program, programLight *glutil.Program
+	mesh                  *glutil.Mesh
+	texture, texture2     uint32
+
+	angle float64
+	model mgl32.Mat4
+}
+
+func (d *demo) Init(window *glfw.Window) error {
+	// Configure the vertex and fragment shaders
+	loader := glutil.NewShaderLoader(shaders.FS)
+	program, err := loader.NewProgram("cube.vert", "cube.frag")
+	if err != nil {
+		return err
+	}
+	d.program = examples.CheckUniforms(glutil.Reflect(d.res.Program(program)))
+	programLight, err := loader.NewProgram("cube.vert", "cube.frag")
+	if err != nil {
+		return err
+	}
+	d.programLight = examples.CheckUniforms(glutil.Reflect(d.res.Program(programLight)))
+	// first
+	gl.UseProgram(program)
+	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/windowHeight, 0.1, 10.0)
+	camera := mgl32.LookAtV(mgl32.Vec3{3, 3, 3}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+	if _, err := d.blocks.New("Frame", glutil.Std140, examples.Frame{Projection: projection, Camera: camera}); err != nil {
+		return err
+	}
+	d.res.Add(d.blocks.Delete)
+	d.blocks.Attach(d.program, d.programLight)
+
+	model := mgl32.Ident4()
+	d.program.SetMat4("model", model)
+
+	d.program.SetInt("tex", 0)
+
+	//second
+	gl.UseProgram(programLight)
+	lightModel := mgl32.Ident4()
+	d.programLight.SetMat4("model", lightModel)
+
+	d.programLight.SetInt("tex", 1) //set bind to which texture index
+
+	// Load the texture
+	texture, err := examples.LoadTexture(&d.res, "square.png", glutil.TextureOptions{})
+	if err != nil {
+		return err
+	}
+	d.texture = texture
+	texture2, err := examples.LoadTexture(&d.res, "square2.png", glutil.TextureOptions{})
+	if err != nil {
+		return err
+	}
+	d.texture2 = texture2
+
+	// Configure the vertex data
+
+	// one mesh for the cube and the light; each program gets its own vao
+	layout := glutil.VertexLayout{glutil.PositionAttrib("vert"), glutil.UVAttrib("vertTexCoord")}
+	vertices, indices, err := glutil.Weld(cubeVertices, layout.Stride()/4, 0)
+	if err != nil {
+		return err
+	}
+	mesh, err := glutil.NewIndexedMesh(layout, vertices, indices)
+	if err != nil {
+		return err
+	}
+	d.mesh = mesh
+	d.res.Add(mesh.Delete)
+
+	// Configure global settings
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+	gl.ClearColor(0, 0, 0, 1.0)
+
+	return nil
+}
+
+func (d *demo) Update(dt float64) {
+	d.angle += dt
+	d.model = mgl32.HomogRotate3D(float32(d.angle), mgl32.Vec3{0, 1, 0})
+}
+
+func (d *demo) Render() {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture)
+
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture2)
+
+	// Render 1
+	d.program.Use()
+	d.program.SetMat4("model", d.model)
+	d.mesh.Draw(d.program)
+
+	// Render2
+	newModel := d.model.Mul4(mgl32.Translate3D(0, 0, -3)).Mul4(mgl32.Scale3D(0.2, 0.2, 0.2))
+	d.programLight.Use()
+	d.programLight.SetMat4("model", newModel)
+	d.mesh.Draw(d.programLight)
+}
+
+func (d *demo) Shutdown() {
+	d.res.Free()
+}
+
+var cubeVertices = []float32{
+	//  X, Y, Z, U, V
+	// Bottom
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+
+	// Top
+	-1.0, 1.0, -1.0, 0.0, 0.0,
+	-1.0, 1.0, 1.0, 0.0, 1.0,
+	1.0, 1.0, -1.0, 1.0, 0.0,
+	1.0, 1.0, -1.0, 1.0, 0.0,
+	-1.0, 1.0, 1.0, 0.0, 1.0,
+	1.0, 1.0, 1.0, 1.0, 1.0,
+
+	// Front
+	-1.0, -1.0, 1.0, 1.0, 0.0,
+	1.0, -1.0, 1.0, 0.0, 0.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+	1.0, -1.0, 1.0, 0.0, 0.0,
+	1.0, 1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+
+	// Back
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	-1.0, 1.0, -1.0, 0.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	-1.0, 1.0, -1.0, 0.0, 1.0,
+	1.0, 1.0, -1.0, 1.0, 1.0,
+
+	// Left
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, -1.0, 1.0, 0.0,
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+	-1.0, 1.0, -1.0, 1.0, 0.0,
+
+	// Right
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, 1.0, -1.0, 0.0, 0.0,
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	1.0, 1.0, -1.0, 0.0, 0.0,
+	1.0, 1.0, 1.0, 0.0, 1.0,
+}
@@ -0,0 +1,168 @@
+package multiplecubes
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/henghuang/opengl-go/examples"
+	"github.com/henghuang/opengl-go/examples/shaders"
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+const windowWidth = 800
+const windowHeight = 600
+
+func init() {
+	examples.Register("multipleCubes", func() examples.Example { return &demo{} })
+}
+
+type demo struct {
+	res    glutil.Resources
+	blocks glutil.UniformBlocks
+
+	program *glutil.Program
+	mesh    *glutil.Mesh
+	texture *glutil.PendingTexture
+
+	angle float64
+}
+
+func (d *demo) Init(window *glfw.Window) error {
+	// Configure the vertex and fragment shaders
+	loader := glutil.NewShaderLoader(shaders.FS)
+	program, err := loader.NewProgram("cube.vert", "cube.frag")
+	if err != nil {
+		return err
+	}
+	d.program = examples.CheckUniforms(glutil.Reflect(d.res.Program(program)))
+
+	gl.UseProgram(program)
+
+	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/windowHeight, 0.1, 10.0)
+	camera := mgl32.LookAtV(mgl32.Vec3{3, 3, 5}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+	if _, err := d.blocks.New("Frame", glutil.Std140, examples.Frame{Projection: projection, Camera: camera}); err != nil {
+		return err
+	}
+	d.res.Add(d.blocks.Delete)
+	d.blocks.Attach(d.program)
+
+	model := mgl32.Ident4()
+	d.program.SetMat4("model", model)
+
+	d.program.SetInt("tex", 0)
+
+	// Load the texture in the background, with mipmaps so the distant
+	// cubes don't shimmer
+	d.texture = examples.LoadTextureAsync(&d.res, "square.png", glutil.TextureOptions{Mipmaps: true, Anisotropy: 8})
+
+	// Configure the vertex data
+	layout := glutil.VertexLayout{glutil.PositionAttrib("vert"), glutil.UVAttrib("vertTexCoord")}
+	vertices, indices, err := glutil.Weld(cubeVertices, layout.Stride()/4, 0)
+	if err != nil {
+		return err
+	}
+	mesh, err := glutil.NewIndexedMesh(layout, vertices, indices)
+	if err != nil {
+		return err
+	}
+	d.mesh = mesh
+	d.res.Add(mesh.Delete)
+
+	// Configure global settings
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+	gl.ClearColor(1.0, 1.0, 1.0, 1.0)
+
+	return nil
+}
+
+func (d *demo) Update(dt float64) {
+	d.angle += dt
+}
+
+func (d *demo) Render() {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	d.program.Use()
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture.Texture())
+
+	// camera := mgl32.LookAtV(mgl32.Vec3{3, float32(d.angle), 5}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+	// d.frameBuffer.Update(examples.Frame{Projection: projection, Camera: camera})
+
+	for i, each := range cubePositions {
+		model_t := mgl32.Translate3D(each[0], each[1], each[2])
+		model_r := mgl32.HomogRotate3D(float32(i)*20, mgl32.Vec3{0, 1, 0})
+		model := model_r.Mul4(model_t)
+
+		d.program.SetMat4("model", model)
+		d.mesh.Draw(d.program)
+	}
+}
+
+func (d *demo) Shutdown() {
+	d.res.Free()
+}
+
+var cubeVertices = []float32{
+	// Bottom
+	-0.5, -0.5, -0.5, 0.0, 0.0,
+	0.5, -0.5, -0.5, 0.5, 0.0,
+	-0.5, -0.5, 0.5, 0.0, 0.5,
+	0.5, -0.5, -0.5, 0.5, 0.0,
+	0.5, -0.5, 0.5, 0.5, 0.5,
+	-0.5, -0.5, 0.5, 0.0, 0.5,
+
+	// Top
+	-0.5, 0.5, -0.5, 0.0, 0.0,
+	-0.5, 0.5, 0.5, 0.0, 0.5,
+	0.5, 0.5, -0.5, 0.5, 0.0,
+	0.5, 0.5, -0.5, 0.5, 0.0,
+	-0.5, 0.5, 0.5, 0.0, 0.5,
+	0.5, 0.5, 0.5, 0.5, 0.5,
+
+	// Front
+	-0.5, -0.5, 0.5, 0.5, 0.0,
+	0.5, -0.5, 0.5, 0.0, 0.0,
+	-0.5, 0.5, 0.5, 0.5, 0.5,
+	0.5, -0.5, 0.5, 0.0, 0.0,
+	0.5, 0.5, 0.5, 0.0, 0.5,
+	-0.5, 0.5, 0.5, 0.5, 0.5,
+
+	// Back
+	-0.5, -0.5, -0.5, 0.0, 0.0,
+	-0.5, 0.5, -0.5, 0.0, 0.5,
+	0.5, -0.5, -0.5, 0.5, 0.0,
+	0.5, -0.5, -0.5, 0.5, 0.0,
+	-0.5, 0.5, -0.5, 0.0, 0.5,
+	0.5, 0.5, -0.5, 0.5, 0.5,
+
+	// Left
+	-0.5, -0.5, 0.5, 0.0, 0.5,
+	-0.5, 0.5, -0.5, 0.5, 0.0,
+	-0.5, -0.5, -0.5, 0.0, 0.0,
+	-0.5, -0.5, 0.5, 0.0, 0.5,
+	-0.5, 0.5, 0.5, 0.5, 0.5,
+	-0.5, 0.5, -0.5, 0.5, 0.0,
+
+	// Right
+	0.5, -0.5, 0.5, 0.5, 0.5,
+	0.5, -0.5, -0.5, 0.5, 0.0,
+	0.5, 0.5, -0.5, 0.0, 0.0,
+	0.5, -0.5, 0.5, 0.5, 0.5,
+	0.5, 0.5, -0.5, 0.0, 0.0,
+	0.5, 0.5, 0.5, 0.0, 0.5,
+}
+
+var cubePositions = [][]float32{
+	[]float32{0.0, 0.0, 0.0},
+	[]float32{2.0, 5.0, -15.0},
+	[]float32{-1.5, -2.2, -2.5},
+	[]float32{-3.8, -2.0, -12.},
+	[]float32{2.4, -0.4, -3.5},
+	[]float32{-1.7, 3.0, -7.5},
+	[]float32{1.3, -2.0, -2.5},
+	[]float32{1.5, 2.0, -2.5},
+	[]float32{1.5, 0.2, -1.5},
+	[]float32{-1.3, 1.0, -1.5},
+}
@@ -0,0 +1,233 @@
+package examples
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/henghuang/opengl-go/glutil"
+	"github.com/henghuang/opengl-go/glutil/texgen"
+)
+
+const windowWidth = 800
+const windowHeight = 600
+
+// Run opens a window and runs the named example until the window is
+// closed. Pressing 1-9 switches to the example at that position in Names
+// and F12 saves a screenshot. Run must be called from the main OS thread.
+func Run(name string) error {
+	current, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	next := ""
+	screenshot := false
+	app := &glutil.App{
+		Title:  name,
+		Width:  windowWidth,
+		Height: windowHeight,
+		Init: func(a *glutil.App) error {
+			a.Window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+				if action == glfw.Press && key == glfw.KeyF12 {
+					screenshot = true
+				}
+				if action != glfw.Press || key < glfw.Key1 || key > glfw.Key9 {
+					return
+				}
+				names := Names()
+				if i := int(key - glfw.Key1); i < len(names) {
+					next = names[i]
+				}
+			})
+			openProgramCache()
+			Uploads = glutil.NewTextureQueue(runtime.NumCPU())
+			Uploads.Budget = uploadBudget
+			return current.Init(a.Window)
+		},
+		Update: func(a *glutil.App, dt float64) error {
+			if next != "" && next != name {
+				e, err := lookup(next)
+				if err != nil {
+					return err
+				}
+				current.Shutdown()
+				checkTextures(name)
+				resetState(a.Window)
+				current, name = e, next
+				a.Window.SetTitle(name)
+				if err := current.Init(a.Window); err != nil {
+					return err
+				}
+			}
+			next = ""
+
+			Uploads.Upload()
+			current.Update(dt)
+			return nil
+		},
+		Render: func(a *glutil.App) error {
+			current.Render()
+			checkUniforms()
+			if screenshot {
+				screenshot = false
+				saveScreenshot(a.Window, name)
+			}
+			return nil
+		},
+		Shutdown: func(a *glutil.App) error {
+			current.Shutdown()
+			Uploads.Delete()
+			return nil
+		},
+	}
+
+	return app.Run()
+}
+
+// resetState puts back the GL and window state examples are known to
+// change so the next one starts from the defaults.
+func resetState(window *glfw.Window) {
+	window.SetCursorPosCallback(nil)
+
+	gl.UseProgram(0)
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	for _, unit := range []uint32{gl.TEXTURE1, gl.TEXTURE0} {
+		gl.ActiveTexture(unit)
+		gl.BindTexture(gl.TEXTURE_2D, 0)
+		gl.BindTexture(gl.TEXTURE_CUBE_MAP, 0)
+	}
+	gl.Disable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+
+	gl.Disable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+	gl.Disable(gl.STENCIL_TEST)
+	gl.StencilMask(0xFF)
+	gl.StencilFunc(gl.ALWAYS, 0, 0xFF)
+	gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+	gl.ClearColor(0, 0, 0, 0)
+}
+
+// unchecked are the programs CheckUniforms recorded since the last frame.
+var unchecked []*glutil.Program
+
+// CheckUniforms records p so the uniforms it still has unset after the
+// example's first frame are logged. It returns p.
+func CheckUniforms(p *glutil.Program) *glutil.Program {
+	unchecked = append(unchecked, p)
+	return p
+}
+
+// checkUniforms logs the unset uniforms of the programs recorded by
+// CheckUniforms and forgets them.
+func checkUniforms() {
+	for _, p := range unchecked {
+		p.Check()
+	}
+	unchecked = nil
+}
+
+// Textures is the texture cache the examples share, so pictures loaded
+// twice with the same options are one texture.
+var Textures glutil.TextureCache
+
+// LoadTexture loads file through Textures and releases it when res is
+// freed. A file missing from disk is replaced by StandInImage.
+func LoadTexture(res *glutil.Resources, file string, opts glutil.TextureOptions) (uint32, error) {
+	if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		log.Printf("%s not found, using a generated texture", file)
+		texture, err := glutil.NewTextureFromImage(StandInImage(), opts)
+		if err != nil {
+			return 0, err
+		}
+		return res.Texture(texture), nil
+	}
+	texture, err := Textures.Load(file, opts)
+	if err != nil {
+		return 0, err
+	}
+	res.Add(func() { Textures.Release(texture) })
+	return texture, nil
+}
+
+// StandInImage returns the generated picture examples use for images
+// missing from disk, so they run outside the repository root.
+func StandInImage() image.Image {
+	return texgen.UVDebug(256, 256, 8)
+}
+
+// uploadBudget is the time each frame may spend uploading textures loaded
+// with LoadTextureAsync.
+const uploadBudget = 4 * time.Millisecond
+
+// Uploads decodes the textures of LoadTextureAsync in the background and
+// uploads them at the start of each frame.
+var Uploads *glutil.TextureQueue
+
+// LoadTextureAsync starts loading file through Uploads and deletes the
+// texture when res is freed. The placeholder is bound until it is ready.
+func LoadTextureAsync(res *glutil.Resources, file string, opts glutil.TextureOptions) *glutil.PendingTexture {
+	t := Uploads.Load(file, opts)
+	res.Add(t.Delete)
+	return t
+}
+
+// checkTextures logs textures an example left in Textures after shutting
+// down.
+func checkTextures(name string) {
+	if n := Textures.Len(); n > 0 {
+		log.Printf("%s: %d textures (%d KB) still held after shutdown", name, n, Textures.MemoryUsage()/1024)
+	}
+}
+
+// saveScreenshot writes the frame just rendered to a PNG file in the
+// working directory, logging where it went or why it failed.
+func saveScreenshot(window *glfw.Window, name string) {
+	width, height := window.GetFramebufferSize()
+	img, err := glutil.ReadFramebuffer(0, gl.BACK, image.Rect(0, 0, width, height), glutil.ReadRGBA)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	file := fmt.Sprintf("%s-%s.png", name, time.Now().Format("20060102-150405"))
+	f, err := os.Create(file)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		log.Println(err)
+		return
+	}
+	if err := f.Close(); err != nil {
+		log.Println(err)
+		return
+	}
+	log.Printf("saved %s", file)
+}
+
+// openProgramCache caches linked programs in the user cache directory so
+// later runs skip compiling them. Failing to open it only costs speed.
+func openProgramCache() {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return
+	}
+	cache, err := glutil.OpenProgramCache(filepath.Join(dir, "opengl-go", "programs"))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	glutil.DefaultProgramCache = cache
+}
@@ -0,0 +1,7 @@
+#version 330
+uniform sampler2D tex;
+in vec2 fragTexCoord;
+layout(location = 0) out vec4 outputColor;
+void main() {
+    outputColor = texture(tex, fragTexCoord);
+}
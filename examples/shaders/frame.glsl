@@ -0,0 +1,9 @@
+// Per-frame camera and light shared by every program through one uniform
+// buffer. Mirrors examples.Frame.
+layout(std140) uniform Frame {
+    mat4 projection;
+    mat4 camera;
+    vec3 viewPos;
+    vec3 lightPos;
+    vec3 lightColor;
+};
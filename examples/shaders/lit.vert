@@ -0,0 +1,14 @@
+#version 330
+#include "transform.glsl"
+layout(location = 0) in vec3 vert;
+layout(location = 1) in vec2 vertTexCoord;
+layout(location = 2) in vec3 aNormal; //norm vector
+out vec2 fragTexCoord;
+out vec3 Normal;
+out vec3 FragPos;
+void main() {
+    fragTexCoord = vertTexCoord;
+	gl_Position = projection * camera * model * vec4(vert, 1);
+	FragPos = vec3(model * vec4(vert, 1.0));
+	Normal = aNormal;
+}
@@ -0,0 +1,23 @@
+#version 330
+#include "frame.glsl"
+uniform vec3 objectColor;
+in vec3 Normal;
+in vec3 FragPos;
+layout(location = 0) out vec4 outputColor;
+void main() {
+	vec3 norm = normalize(Normal);
+	vec3 lightDir = normalize(lightPos - FragPos);
+	float diff = max(dot(norm, lightDir), 0.0);
+	vec3 diffuse = diff * lightColor;
+
+	float specularStrength = 0.5;
+	vec3 viewDir = normalize(viewPos - FragPos);
+	vec3 reflectDir = reflect(-lightDir, norm);
+	float spec = pow(max(dot(viewDir, reflectDir), 0.0), 256);
+	vec3 specular = specularStrength * spec * lightColor;
+
+	float ambientStrength = 0.1;
+	vec3 ambient = ambientStrength * lightColor;
+	vec3 result = (ambient+ diffuse+specular) * objectColor;
+	outputColor = vec4(result, 1);
+}
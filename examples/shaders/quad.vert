@@ -0,0 +1,8 @@
+#version 330
+in vec3 vert;
+in vec2 vertTexCoord;
+out vec2 fragTexCoord;
+void main() {
+    fragTexCoord = vertTexCoord;
+    gl_Position = vec4(vert, 1);
+}
@@ -0,0 +1,41 @@
+// Package shaders embeds the GLSL sources shared by the examples.
+package shaders
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+// FS holds every .vert, .geom, .frag and .glsl file in this directory.
+//
+//go:embed *.vert *.geom *.frag *.glsl
+var FS embed.FS
+
+// Dir is where the shader sources live relative to the repository root.
+const Dir = "examples/shaders"
+
+// Source returns the shader directory on disk when the examples run from
+// the repository root, so edits can be hot reloaded, and FS otherwise.
+func Source() fs.FS {
+	if info, err := os.Stat(Dir); err == nil && info.IsDir() {
+		return os.DirFS(Dir)
+	}
+	return FS
+}
+
+// Feature flags understood by uber.vert and uber.frag. NormalMap needs Lit.
+const (
+	Textured   = "TEXTURED"
+	Lit        = "LIT"
+	NormalMap  = "NORMAL_MAP"
+	SolidColor = "SOLID_COLOR"
+)
+
+// Uber returns a variant cache for uber.vert and uber.frag read through
+// loader.
+func Uber(loader *glutil.ShaderLoader) *glutil.ShaderVariants {
+	return glutil.NewShaderVariants(loader, "uber.vert", "uber.frag", Textured, Lit, NormalMap, SolidColor)
+}
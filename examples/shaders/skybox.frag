@@ -0,0 +1,7 @@
+#version 330
+uniform samplerCube sky;
+in vec3 fragDir;
+layout(location = 0) out vec4 outputColor;
+void main() {
+    outputColor = texture(sky, fragDir);
+}
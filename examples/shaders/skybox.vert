@@ -0,0 +1,11 @@
+#version 330
+uniform mat4 projection;
+uniform mat4 view;
+in vec3 vert;
+out vec3 fragDir;
+void main() {
+    fragDir = vert;
+    // z = w puts the sky on the far plane, where a LEQUAL depth test lets it
+    // fill only what the scene left empty.
+    gl_Position = (projection * view * vec4(vert, 1)).xyww;
+}
@@ -0,0 +1,2 @@
+#include "frame.glsl"
+uniform mat4 model;
@@ -0,0 +1,60 @@
+#version 330
+// One fragment shader for every material; see uber.vert.
+#include "frame.glsl"
+#if defined(NORMAL_MAP) && !defined(LIT)
+#error NORMAL_MAP needs LIT
+#endif
+#ifdef SOLID_COLOR
+uniform vec4 color;
+#endif
+#ifdef TEXTURED
+uniform sampler2D tex;
+#endif
+#if defined(TEXTURED) || defined(NORMAL_MAP)
+in vec2 fragTexCoord;
+#endif
+#ifdef LIT
+in vec3 Normal;
+in vec3 FragPos;
+#endif
+#ifdef NORMAL_MAP
+uniform sampler2D normalMap;
+in vec3 Tangent;
+#endif
+layout(location = 0) out vec4 outputColor;
+
+vec3 surfaceNormal() {
+#ifdef NORMAL_MAP
+    vec3 n = normalize(Normal);
+    vec3 t = normalize(Tangent - dot(Tangent, n) * n);
+    mat3 tbn = mat3(t, cross(n, t), n);
+    return normalize(tbn * (texture(normalMap, fragTexCoord).rgb * 2.0 - 1.0));
+#elif defined(LIT)
+    return normalize(Normal);
+#else
+    return vec3(0, 0, 1);
+#endif
+}
+
+void main() {
+    vec4 base = vec4(1);
+#ifdef SOLID_COLOR
+    base *= color;
+#endif
+#ifdef TEXTURED
+    base *= texture(tex, fragTexCoord);
+#endif
+#ifdef LIT
+    vec3 norm = surfaceNormal();
+    vec3 lightDir = normalize(lightPos - FragPos);
+    float diff = max(dot(norm, lightDir), 0.0);
+
+    vec3 viewDir = normalize(viewPos - FragPos);
+    vec3 reflectDir = reflect(-lightDir, norm);
+    float spec = pow(max(dot(viewDir, reflectDir), 0.0), 256);
+
+    vec3 light = (0.1 + diff + 0.5 * spec) * lightColor;
+    base.rgb *= light;
+#endif
+    outputColor = base;
+}
@@ -0,0 +1,31 @@
+#version 330
+// One vertex shader for every material. Features are #defined by
+// glutil.ShaderVariants: TEXTURED, LIT, NORMAL_MAP, SOLID_COLOR.
+#include "transform.glsl"
+layout(location = 0) in vec3 vert;
+#if defined(TEXTURED) || defined(NORMAL_MAP)
+layout(location = 1) in vec2 vertTexCoord;
+out vec2 fragTexCoord;
+#endif
+#ifdef LIT
+layout(location = 2) in vec3 aNormal;
+out vec3 Normal;
+out vec3 FragPos;
+#endif
+#ifdef NORMAL_MAP
+layout(location = 3) in vec3 aTangent;
+out vec3 Tangent;
+#endif
+void main() {
+#if defined(TEXTURED) || defined(NORMAL_MAP)
+    fragTexCoord = vertTexCoord;
+#endif
+#ifdef LIT
+    FragPos = vec3(model * vec4(vert, 1.0));
+    Normal = mat3(model) * aNormal;
+#endif
+#ifdef NORMAL_MAP
+    Tangent = mat3(model) * aTangent;
+#endif
+    gl_Position = projection * camera * model * vec4(vert, 1);
+}
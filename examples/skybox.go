@@ -0,0 +1,82 @@
+package examples
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+// Skybox draws a cube map texture around the camera as a background.
+// Draw it after the scene.
+type Skybox struct {
+	res     glutil.Resources
+	program *glutil.Program
+	mesh    *glutil.Mesh
+	cubemap uint32
+}
+
+// NewSkybox builds the skybox pass from skybox.vert and skybox.frag read
+// through loader. It takes ownership of cubemap, a cube map texture from
+// glutil.NewCubemap.
+func NewSkybox(loader *glutil.ShaderLoader, cubemap uint32) (*Skybox, error) {
+	s := &Skybox{}
+	s.cubemap = s.res.Texture(cubemap)
+	program, err := loader.NewProgram("skybox.vert", "skybox.frag")
+	if err != nil {
+		s.res.Free()
+		return nil, err
+	}
+	s.program = CheckUniforms(glutil.Reflect(s.res.Program(program)))
+	s.program.Use()
+	s.program.SetInt("sky", 0)
+
+	layout := glutil.VertexLayout{glutil.PositionAttrib("vert")}
+	vertices, indices, err := glutil.Weld(skyboxVertices, layout.Stride()/4, 0)
+	if err != nil {
+		s.res.Free()
+		return nil, err
+	}
+	mesh, err := glutil.NewIndexedMesh(layout, vertices, indices)
+	if err != nil {
+		s.res.Free()
+		return nil, err
+	}
+	s.mesh = mesh
+	s.res.Add(mesh.Delete)
+
+	// Filter across face edges instead of showing the seams.
+	gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+	return s, nil
+}
+
+// Draw renders the sky with the camera's rotation only, so it stays put
+// as the camera moves. It leaves the depth function as it found it.
+func (s *Skybox) Draw(projection, camera mgl32.Mat4) {
+	var depthFunc int32
+	gl.GetIntegerv(gl.DEPTH_FUNC, &depthFunc)
+	gl.DepthFunc(gl.LEQUAL)
+
+	s.program.Use()
+	s.program.SetMat4("projection", projection)
+	s.program.SetMat4("view", camera.Mat3().Mat4())
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, s.cubemap)
+	s.mesh.Draw(s.program)
+
+	gl.DepthFunc(uint32(depthFunc))
+}
+
+// Delete frees the program, vertex data and cube map.
+func (s *Skybox) Delete() {
+	s.res.Free()
+}
+
+// skyboxVertices is a unit cube seen from inside, as X, Y, Z triangles.
+var skyboxVertices = []float32{
+	-1, 1, -1, -1, -1, -1, 1, -1, -1, 1, -1, -1, 1, 1, -1, -1, 1, -1, // -Z
+	-1, -1, 1, -1, -1, -1, -1, 1, -1, -1, 1, -1, -1, 1, 1, -1, -1, 1, // -X
+	1, -1, -1, 1, -1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, 1, -1, -1, // +X
+	-1, -1, 1, -1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, 1, -1, -1, 1, // +Z
+	-1, 1, -1, 1, 1, -1, 1, 1, 1, 1, 1, 1, -1, 1, 1, -1, 1, -1, // +Y
+	-1, -1, -1, -1, -1, 1, 1, -1, -1, 1, -1, -1, -1, -1, 1, 1, -1, 1, // -Y
+}
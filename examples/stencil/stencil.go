@@ -0,0 +1,181 @@
+// Package stencil draws a border around a spinning cube.
+package stencil
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/henghuang/opengl-go/examples"
+	"github.com/henghuang/opengl-go/examples/shaders"
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+const windowWidth = 800
+const windowHeight = 600
+
+func init() {
+	examples.Register("stencil", func() examples.Example { return &demo{} })
+}
+
+type demo struct {
+	res    glutil.Resources
+	blocks glutil.UniformBlocks
+
+	program, borderProgram *glutil.Program
+	mesh                   *glutil.Mesh
+	texture                uint32
+
+	angle float64
+	model mgl32.Mat4
+}
+
+func (d *demo) Init(window *glfw.Window) error {
+	// Configure the vertex and fragment shaders
+	variants := shaders.Uber(glutil.NewShaderLoader(shaders.FS))
+	d.res.Add(variants.Delete)
+	program, err := variants.Get(shaders.Textured)
+	if err != nil {
+		return err
+	}
+	d.program = examples.CheckUniforms(program)
+	borderProgram, err := variants.Get(shaders.SolidColor)
+	if err != nil {
+		return err
+	}
+	d.borderProgram = examples.CheckUniforms(borderProgram)
+	d.program.Use()
+	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/windowHeight, 0.1, 10.0)
+	camera := mgl32.LookAtV(mgl32.Vec3{3, 3, 3}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+	if _, err := d.blocks.New("Frame", glutil.Std140, examples.Frame{Projection: projection, Camera: camera}); err != nil {
+		return err
+	}
+	d.res.Add(d.blocks.Delete)
+	d.blocks.Attach(d.program, d.borderProgram)
+
+	model := mgl32.Ident4()
+	d.program.SetMat4("model", model)
+
+	d.program.SetInt("tex", 0)
+
+	// Load the texture
+	texture, err := examples.LoadTexture(&d.res, "square.png", glutil.TextureOptions{})
+	if err != nil {
+		return err
+	}
+	d.texture = texture
+
+	//border objects setting
+	d.borderProgram.Use()
+	d.borderProgram.SetVec4("color", mgl32.Vec4{0, 1, 0, 1})
+	borderModel := mgl32.Ident4()
+	d.borderProgram.SetMat4("model", borderModel)
+
+	// Configure the vertex data
+	layout := glutil.VertexLayout{glutil.PositionAttrib("vert"), glutil.UVAttrib("vertTexCoord")}
+	vertices, indices, err := glutil.Weld(cubeVertices, layout.Stride()/4, 0)
+	if err != nil {
+		return err
+	}
+	mesh, err := glutil.NewIndexedMesh(layout, vertices, indices)
+	if err != nil {
+		return err
+	}
+	d.mesh = mesh
+	d.res.Add(mesh.Delete)
+
+	// Configure global settings
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+	gl.ClearColor(1.0, 1.0, 1.0, 1.0)
+	//init stencil test
+	gl.Enable(gl.STENCIL_TEST)
+	gl.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+
+	return nil
+}
+
+func (d *demo) Update(dt float64) {
+	d.angle += dt
+	d.model = mgl32.HomogRotate3D(float32(d.angle), mgl32.Vec3{0, 1, 0})
+}
+
+func (d *demo) Render() {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT) //note the STENCIL_BUFFER_BIT
+
+	//draw box
+	gl.StencilFunc(gl.ALWAYS, 1, 0xFF) // Because the fragments always pass the stencil test, the stencil buffer is updated with the reference value wherever we've drawn them
+	gl.StencilMask(0xFF)
+
+	d.program.Use()
+	d.program.SetMat4("model", d.model)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture)
+	d.mesh.Draw(d.program)
+
+	//draw boder
+	gl.StencilFunc(gl.NOTEQUAL, 1, 0xFF) //pass if not NOTEQUAL to 1, only draw when pass
+	gl.StencilMask(0x00)                 //disable write
+	gl.Disable(gl.DEPTH_TEST)
+	d.borderProgram.Use()
+	borderModel := d.model.Mul4(mgl32.Scale3D(1.02, 1.02, 1.02))
+	d.borderProgram.SetMat4("model", borderModel)
+	d.mesh.Draw(d.borderProgram)
+
+	gl.StencilMask(0xFF)
+	gl.Enable(gl.DEPTH_TEST)
+}
+
+func (d *demo) Shutdown() {
+	d.res.Free()
+}
+
+var cubeVertices = []float32{
+	//  X, Y, Z, U, V
+	// Bottom
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+
+	// Top
+	-1.0, 1.0, -1.0, 0.0, 0.0,
+	-1.0, 1.0, 1.0, 0.0, 1.0,
+	1.0, 1.0, -1.0, 1.0, 0.0,
+	1.0, 1.0, -1.0, 1.0, 0.0,
+	-1.0, 1.0, 1.0, 0.0, 1.0,
+	1.0, 1.0, 1.0, 1.0, 1.0,
+
+	// Front
+	-1.0, -1.0, 1.0, 1.0, 0.0,
+	1.0, -1.0, 1.0, 0.0, 0.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+	1.0, -1.0, 1.0, 0.0, 0.0,
+	1.0, 1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+
+	// Back
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	-1.0, 1.0, -1.0, 0.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	-1.0, 1.0, -1.0, 0.0, 1.0,
+	1.0, 1.0, -1.0, 1.0, 1.0,
+
+	// Left
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, -1.0, 1.0, 0.0,
+	-1.0, -1.0, -1.0, 0.0, 0.0,
+	-1.0, -1.0, 1.0, 0.0, 1.0,
+	-1.0, 1.0, 1.0, 1.0, 1.0,
+	-1.0, 1.0, -1.0, 1.0, 0.0,
+
+	// Right
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	1.0, -1.0, -1.0, 1.0, 0.0,
+	1.0, 1.0, -1.0, 0.0, 0.0,
+	1.0, -1.0, 1.0, 1.0, 1.0,
+	1.0, 1.0, -1.0, 0.0, 0.0,
+	1.0, 1.0, 1.0, 0.0, 1.0,
+}
@@ -0,0 +1,142 @@
+package texture
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/henghuang/opengl-go/examples"
+	"github.com/henghuang/opengl-go/examples/shaders"
+	"github.com/henghuang/opengl-go/glutil"
+)
+
+const windowWidth = 800
+const windowHeight = 600
+
+func init() {
+	examples.Register("texture", func() examples.Example { return &demo{} })
+}
+
+type demo struct {
+	res glutil.Resources
+
+	program *glutil.Program
+	mesh    *glutil.Mesh
+	texture uint32
+}
+
+func (d *demo) Init(window *glfw.Window) error {
+	// Configure the vertex and fragment shaders
+	loader := glutil.NewShaderLoader(shaders.FS)
+	program, err := loader.NewProgram("quad.vert", "cube.frag")
+	if err != nil {
+		return err
+	}
+	d.program = examples.CheckUniforms(glutil.Reflect(d.res.Program(program)))
+
+	gl.UseProgram(program)
+
+	d.program.SetInt("tex", 0)
+
+	// Load the texture, flipped so that UV (0, 0) is the bottom-left of
+	// the picture
+	texture, err := examples.LoadTexture(&d.res, "square.png", glutil.TextureOptions{FlipY: true})
+	if err != nil {
+		return err
+	}
+	d.texture = texture
+
+	// Configure the vertex data
+	layout := glutil.VertexLayout{glutil.PositionAttrib("vert"), glutil.UVAttrib("vertTexCoord")}
+	vertices, indices, err := glutil.Weld(cubeVertices, layout.Stride()/4, 0)
+	if err != nil {
+		return err
+	}
+	mesh, err := glutil.NewIndexedMesh(layout, vertices, indices)
+	if err != nil {
+		return err
+	}
+	d.mesh = mesh
+	d.res.Add(mesh.Delete)
+
+	// Configure global settings
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+	gl.ClearColor(1.0, 1.0, 1.0, 1.0)
+
+	return nil
+}
+
+func (d *demo) Update(dt float64) {}
+
+func (d *demo) Render() {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	d.program.Use()
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture)
+
+	d.mesh.Draw(d.program)
+}
+
+func (d *demo) Shutdown() {
+	d.res.Free()
+}
+
+var cubeVertices = []float32{
+	//  X, Y, Z, U, V
+	// with FlipY the bottom-left of the picture is (0, 0) and the top-right (1, 1)
+	-1.0, -1.0, 0, 0.0, 0.0,
+	1.0, -1.0, 0, 1.0, 0.0,
+	-1.0, 1.0, 0, 0.0, 1.0,
+	1.0, -1.0, 0, 1.0, 0.0,
+	1.0, 1.0, 0, 1.0, 1.0,
+	-1.0, 1.0, 0, 0.0, 1.0,
+
+	// Bottom
+	// -1.0, -1.0, -1.0, 0.0, 0.0,
+	// 1.0, -1.0, -1.0, 1.0, 0.0,
+	// -1.0, -1.0, 1.0, 0.0, 1.0,
+	// 1.0, -1.0, -1.0, 1.0, 0.0,
+	// 1.0, -1.0, 1.0, 1.0, 1.0,
+	// -1.0, -1.0, 1.0, 0.0, 1.0,
+
+	// // Top
+	// -1.0, 1.0, -1.0, 0.0, 0.0,
+	// -1.0, 1.0, 1.0, 0.0, 1.0,
+	// 1.0, 1.0, -1.0, 1.0, 0.0,
+	// 1.0, 1.0, -1.0, 1.0, 0.0,
+	// -1.0, 1.0, 1.0, 0.0, 1.0,
+	// 1.0, 1.0, 1.0, 1.0, 1.0,
+
+	// // Front
+	// -1.0, -1.0, 1.0, 1.0, 0.0,
+	// 1.0, -1.0, 1.0, 0.0, 0.0,
+	// -1.0, 1.0, 1.0, 1.0, 1.0,
+	// 1.0, -1.0, 1.0, 0.0, 0.0,
+	// 1.0, 1.0, 1.0, 0.0, 1.0,
+	// -1.0, 1.0, 1.0, 1.0, 1.0,
+
+	// // Back
+	// -1.0, -1.0, -1.0, 0.0, 0.0,
+	// -1.0, 1.0, -1.0, 0.0, 1.0,
+	// 1.0, -1.0, -1.0, 1.0, 0.0,
+	// 1.0, -1.0, -1.0, 1.0, 0.0,
+	// -1.0, 1.0, -1.0, 0.0, 1.0,
+	// 1.0, 1.0, -1.0, 1.0, 1.0,
+
+	// // Left
+	// -1.0, -1.0, 1.0, 0.0, 1.0,
+	// -1.0, 1.0, -1.0, 1.0, 0.0,
+	// -1.0, -1.0, -1.0, 0.0, 0.0,
+	// -1.0, -1.0, 1.0, 0.0, 1.0,
+	// -1.0, 1.0, 1.0, 1.0, 1.0,
+	// -1.0, 1.0, -1.0, 1.0, 0.0,
+
+	// // Right
+	// 1.0, -1.0, 1.0, 1.0, 1.0,
+	// 1.0, -1.0, -1.0, 1.0, 0.0,
+	// 1.0, 1.0, -1.0, 0.0, 0.0,
+	// 1.0, -1.0, 1.0, 1.0, 1.0,
+	// 1.0, 1.0, -1.0, 0.0, 0.0,
+	// 1.0, 1.0, 1.0, 0.0, 1.0,
+}
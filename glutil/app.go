@@ -0,0 +1,140 @@
+package glutil
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// App owns a window and its frame loop and calls back into the program at
+// each stage of its life. Every callback is optional. An error returned
+// from any of them stops the loop and is returned from Run after the app
+// has been shut down.
+//
+// Run is the simplest way to use an App. Programs that have their own main
+// loop can call Open once, Frame for every frame and Close at the end
+// instead.
+type App struct {
+	Title         string
+	Width, Height int
+	Resizable     bool
+
+	// Init is called once the GL context is current.
+	Init func(a *App) error
+	// Update advances the app by dt seconds.
+	Update func(a *App, dt float64) error
+	// Render draws a frame. The buffers are swapped afterwards.
+	Render func(a *App) error
+	// Resize is called with the new framebuffer size after the viewport
+	// has been set to cover it.
+	Resize func(a *App, width, height int) error
+	// Shutdown is called before Resources are freed and the window is
+	// destroyed. It is called even if Init failed.
+	Shutdown func(a *App) error
+
+	// Window is the app's window, valid between Open and Close.
+	Window *glfw.Window
+	// Resources are freed after Shutdown, most recent first.
+	Resources Resources
+
+	previousTime float64
+	resized      bool
+}
+
+// Run opens the app, runs frames until the window is closed or a callback
+// fails, and closes the app. It must be called from the main OS thread.
+func (a *App) Run() error {
+	if err := a.Open(); err != nil {
+		a.Close()
+		return err
+	}
+	for !a.Window.ShouldClose() {
+		if err := a.Frame(); err != nil {
+			a.Close()
+			return err
+		}
+	}
+	return a.Close()
+}
+
+// Open initializes GLFW, creates the window and calls Init. If Open fails
+// the caller must still call Close to release what was created.
+func (a *App) Open() error {
+	window, err := newWindow(a.Width, a.Height, a.Title, a.Resizable)
+	if err != nil {
+		return err
+	}
+	a.Window = window
+
+	window.SetFramebufferSizeCallback(func(w *glfw.Window, width, height int) {
+		a.resized = true
+	})
+
+	if a.Init != nil {
+		if err := a.Init(a); err != nil {
+			return fmt.Errorf("init: %v", err)
+		}
+	}
+	a.previousTime = glfw.GetTime()
+
+	return nil
+}
+
+// Frame handles a pending resize, then updates, renders and presents one
+// frame and polls for events.
+func (a *App) Frame() error {
+	if a.resized {
+		a.resized = false
+		width, height := a.Window.GetFramebufferSize()
+		gl.Viewport(0, 0, int32(width), int32(height))
+		if a.Resize != nil {
+			if err := a.Resize(a, width, height); err != nil {
+				return fmt.Errorf("resize: %v", err)
+			}
+		}
+	}
+
+	time := glfw.GetTime()
+	elapsed := time - a.previousTime
+	a.previousTime = time
+
+	if a.Update != nil {
+		if err := a.Update(a, elapsed); err != nil {
+			return fmt.Errorf("update: %v", err)
+		}
+	}
+	if a.Render != nil {
+		if err := a.Render(a); err != nil {
+			return fmt.Errorf("render: %v", err)
+		}
+	}
+
+	// Maintenance
+	a.Window.SwapBuffers()
+	glfw.PollEvents()
+
+	return nil
+}
+
+// Close calls Shutdown, frees Resources, destroys the window and
+// terminates GLFW. It is safe to call more than once.
+func (a *App) Close() error {
+	if a.Window == nil {
+		return nil
+	}
+
+	var err error
+	if a.Shutdown != nil {
+		if serr := a.Shutdown(a); serr != nil {
+			err = fmt.Errorf("shutdown: %v", serr)
+		}
+	}
+	a.Resources.Free()
+
+	a.Window.Destroy()
+	a.Window = nil
+	glfw.Terminate()
+
+	return err
+}
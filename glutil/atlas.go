@@ -0,0 +1,227 @@
+package glutil
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// AtlasBuilder packs named images into one atlas image. The zero value
+// packs with no padding or gutters into atlases of up to 4096x4096.
+type AtlasBuilder struct {
+	// Padding is the number of transparent pixels between neighbouring
+	// images and around the edge of the atlas.
+	Padding int
+	// Gutter is the number of pixels each image's edge is repeated
+	// outward, so bilinear filtering samples the image's own colours
+	// instead of its neighbours'.
+	Gutter int
+	// MipLevels is the number of mip levels below the base that must not
+	// mix neighbouring images either. Images, their gutters and padding
+	// are aligned to multiples of 2^MipLevels pixels, and the gutter is
+	// widened to at least that, so every such level keeps a texel of
+	// gutter around each image.
+	MipLevels int
+	// MaxSize is the largest width or height the atlas may grow to. Zero
+	// means 4096.
+	MaxSize int
+
+	entries []atlasEntry
+}
+
+type atlasEntry struct {
+	name string
+	img  image.Image
+}
+
+// Add queues img to be packed under name.
+func (b *AtlasBuilder) Add(name string, img image.Image) {
+	b.entries = append(b.entries, atlasEntry{name, img})
+}
+
+// AtlasRegion is where one image landed in an atlas.
+type AtlasRegion struct {
+	Name string
+	// Rect is the image in atlas pixels, without its gutter.
+	Rect image.Rectangle
+	// UVMin and UVMax are the texture coordinates of Rect's corners, with
+	// v counted from the first row, as the atlas is uploaded without FlipY.
+	UVMin, UVMax mgl32.Vec2
+}
+
+// Atlas is a packed image and the regions of the images in it.
+type Atlas struct {
+	Image   *image.NRGBA
+	Regions map[string]AtlasRegion
+}
+
+// Build packs the added images. Packing is deterministic: images are
+// placed tallest first, then widest, then by name, on shelves filled left
+// to right, in the smallest power-of-two atlas they fit, growing the width
+// before the height.
+func (b *AtlasBuilder) Build() (*Atlas, error) {
+	maxSize := b.MaxSize
+	if maxSize == 0 {
+		maxSize = 4096
+	}
+	if b.Padding < 0 || b.Gutter < 0 || b.MipLevels < 0 {
+		return nil, fmt.Errorf("atlas: negative padding, gutter or mip levels")
+	}
+	if b.MipLevels > 30 || 1<<b.MipLevels > maxSize {
+		return nil, fmt.Errorf("atlas: %d mip levels do not fit in %dx%d", b.MipLevels, maxSize, maxSize)
+	}
+	align := 1 << b.MipLevels
+	padding, gutter := alignUp(b.Padding, align), alignUp(b.Gutter, align)
+	if b.MipLevels > 0 && gutter == 0 {
+		gutter = align
+	}
+	seen := map[string]bool{}
+	cells := make([]image.Point, len(b.entries))
+	area := 0
+	for i, e := range b.entries {
+		if seen[e.name] {
+			return nil, fmt.Errorf("atlas: image %q added twice", e.name)
+		}
+		seen[e.name] = true
+		size := e.img.Bounds().Size()
+		if size.X <= 0 || size.Y <= 0 {
+			return nil, fmt.Errorf("atlas: image %q is empty", e.name)
+		}
+		size = image.Pt(alignUp(size.X, align), alignUp(size.Y, align))
+		cells[i] = size.Add(image.Pt(2*gutter+padding, 2*gutter+padding))
+		area += cells[i].X * cells[i].Y
+	}
+
+	order := make([]int, len(b.entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, c := cells[order[i]], cells[order[j]]
+		if a.Y != c.Y {
+			return a.Y > c.Y
+		}
+		if a.X != c.X {
+			return a.X > c.X
+		}
+		return b.entries[order[i]].name < b.entries[order[j]].name
+	})
+
+	width, height := 1, 1
+	for width*height < area {
+		if width <= height {
+			width *= 2
+		} else {
+			height *= 2
+		}
+	}
+	var at []image.Point
+	for {
+		if width > maxSize || height > maxSize {
+			return nil, fmt.Errorf("atlas: images do not fit in %dx%d", maxSize, maxSize)
+		}
+		var ok bool
+		if at, ok = packShelves(cells, order, width, height, padding); ok {
+			break
+		}
+		if width <= height {
+			width *= 2
+		} else {
+			height *= 2
+		}
+	}
+
+	atlas := &Atlas{Image: image.NewNRGBA(image.Rect(0, 0, width, height)), Regions: map[string]AtlasRegion{}}
+	for i, e := range b.entries {
+		r := e.img.Bounds().Sub(e.img.Bounds().Min).Add(at[i]).Add(image.Pt(gutter, gutter))
+		outer := image.Rectangle{at[i], at[i].Add(cells[i]).Sub(image.Pt(padding, padding))}
+		drawGutter(atlas.Image, r, outer, e.img)
+		atlas.Regions[e.name] = AtlasRegion{
+			Name:  e.name,
+			Rect:  r,
+			UVMin: mgl32.Vec2{float32(r.Min.X) / float32(width), float32(r.Min.Y) / float32(height)},
+			UVMax: mgl32.Vec2{float32(r.Max.X) / float32(width), float32(r.Max.Y) / float32(height)},
+		}
+	}
+	return atlas, nil
+}
+
+// packShelves places cells, in order, on shelves as tall as their first
+// cell, starting padding pixels in from the edge. It returns the top-left
+// corner of each cell, or false if they overflow width x height. When
+// padding and every cell are multiples of a power of two, so are the
+// corners.
+func packShelves(cells []image.Point, order []int, width, height, padding int) ([]image.Point, bool) {
+	at := make([]image.Point, len(cells))
+	x, y, shelf := padding, padding, 0
+	for _, i := range order {
+		c := cells[i]
+		if x+c.X > width {
+			x, y, shelf = padding, y+shelf, 0
+		}
+		if x+c.X > width || y+c.Y > height {
+			return nil, false
+		}
+		at[i] = image.Pt(x, y)
+		x += c.X
+		if c.Y > shelf {
+			shelf = c.Y
+		}
+	}
+	return at, true
+}
+
+// drawGutter draws img into r of dst and repeats its edge pixels outward
+// to fill outer.
+func drawGutter(dst *image.NRGBA, r, outer image.Rectangle, img image.Image) {
+	src := img.Bounds()
+	draw.Draw(dst, r, img, src.Min, draw.Src)
+	for y := outer.Min.Y; y < outer.Max.Y; y++ {
+		for x := outer.Min.X; x < outer.Max.X; x++ {
+			if (image.Point{x, y}).In(r) {
+				continue
+			}
+			cx, cy := clampInt(x, r.Min.X, r.Max.X-1), clampInt(y, r.Min.Y, r.Max.Y-1)
+			dst.SetNRGBA(x, y, dst.NRGBAAt(cx, cy))
+		}
+	}
+}
+
+// alignUp rounds v up to a multiple of align, a power of two.
+func alignUp(v, align int) int {
+	return (v + align - 1) &^ (align - 1)
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// Texture uploads the atlas image into a new 2D texture bound to texture
+// unit 0. FlipY is rejected because the region UVs count rows from the
+// top.
+func (a *Atlas) Texture(opts TextureOptions) (uint32, error) {
+	if opts.FlipY {
+		return 0, fmt.Errorf("atlas: FlipY would invert the region UVs")
+	}
+	return NewTextureFromImage(a.Image, opts)
+}
+
+// RemapUVs maps the texture coordinates of interleaved float32 vertices
+// from the unit square into the region. Each vertex is stride floats with
+// U and V at offset, as for VertexAttrib.
+func (r AtlasRegion) RemapUVs(vertices []float32, stride, offset int) {
+	size := r.UVMax.Sub(r.UVMin)
+	for i := offset; i+1 < len(vertices); i += stride {
+		vertices[i] = r.UVMin[0] + vertices[i]*size[0]
+		vertices[i+1] = r.UVMin[1] + vertices[i+1]*size[1]
+	}
+}
@@ -0,0 +1,212 @@
+package glutil
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// atlasImage returns a w x h image with bounds starting at min whose
+// pixels encode id and their position, so every pixel is distinct.
+func atlasImage(id uint8, min image.Point, w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rectangle{min, min.Add(image.Pt(w, h))})
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(min.X+x, min.Y+y, color.NRGBA{id, uint8(x), uint8(y), 255})
+		}
+	}
+	return img
+}
+
+func TestAtlasBuild(t *testing.T) {
+	images := map[string]*image.NRGBA{
+		"a": atlasImage(1, image.Pt(0, 0), 4, 4),
+		"b": atlasImage(2, image.Pt(5, 5), 2, 6),
+		"c": atlasImage(3, image.Pt(0, 0), 3, 3),
+	}
+	b := AtlasBuilder{Padding: 1, Gutter: 1}
+	for _, name := range []string{"a", "b", "c"} {
+		b.Add(name, images[name])
+	}
+	atlas, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cells are the images plus 3 pixels each way: b 5x9, a 7x7 and c
+	// 6x6. Their 130 pixels need a 16x16 atlas; b and a share the first
+	// shelf and c starts the second at y 10.
+	if got, want := atlas.Image.Bounds(), image.Rect(0, 0, 16, 16); got != want {
+		t.Fatalf("atlas bounds = %v, want %v", got, want)
+	}
+	want := map[string]image.Rectangle{
+		"a": image.Rect(7, 2, 11, 6),
+		"b": image.Rect(2, 2, 4, 8),
+		"c": image.Rect(2, 11, 5, 14),
+	}
+	for name, r := range want {
+		region := atlas.Regions[name]
+		if region.Name != name || region.Rect != r {
+			t.Errorf("region %q = %q %v, want %v", name, region.Name, region.Rect, r)
+		}
+		uvMin := mgl32.Vec2{float32(r.Min.X) / 16, float32(r.Min.Y) / 16}
+		uvMax := mgl32.Vec2{float32(r.Max.X) / 16, float32(r.Max.Y) / 16}
+		if region.UVMin != uvMin || region.UVMax != uvMax {
+			t.Errorf("region %q UVs = %v %v, want %v %v", name, region.UVMin, region.UVMax, uvMin, uvMax)
+		}
+	}
+	if len(atlas.Regions) != len(want) {
+		t.Errorf("%d regions, want %d", len(atlas.Regions), len(want))
+	}
+
+	// Every pixel is its image's nearest pixel within the gutter and
+	// transparent elsewhere.
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			var wantPix color.NRGBA
+			for name, r := range want {
+				if (image.Point{x, y}).In(r.Inset(-1)) {
+					src := images[name]
+					cx := clampInt(x, r.Min.X, r.Max.X-1) - r.Min.X + src.Rect.Min.X
+					cy := clampInt(y, r.Min.Y, r.Max.Y-1) - r.Min.Y + src.Rect.Min.Y
+					wantPix = src.NRGBAAt(cx, cy)
+				}
+			}
+			if got := atlas.Image.NRGBAAt(x, y); got != wantPix {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, wantPix)
+			}
+		}
+	}
+
+	// The layout does not depend on the order images are added in.
+	b = AtlasBuilder{Padding: 1, Gutter: 1}
+	for _, name := range []string{"c", "b", "a"} {
+		b.Add(name, images[name])
+	}
+	again, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, atlas) {
+		t.Error("atlas changed with the order images were added")
+	}
+}
+
+func TestAtlasBuildGrows(t *testing.T) {
+	// Two 6x5 images hold 60 pixels, so the search starts at 8x8, but
+	// two 5-high shelves do not fit in it: the width doubles first.
+	b := AtlasBuilder{}
+	b.Add("a", atlasImage(1, image.Pt(0, 0), 6, 5))
+	b.Add("b", atlasImage(2, image.Pt(0, 0), 6, 5))
+	atlas, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := atlas.Image.Bounds(), image.Rect(0, 0, 16, 8); got != want {
+		t.Errorf("atlas bounds = %v, want %v", got, want)
+	}
+	if got, want := atlas.Regions["b"].Rect, image.Rect(6, 0, 12, 5); got != want {
+		t.Errorf("region b = %v, want %v", got, want)
+	}
+}
+
+func TestAtlasBuildMipLevels(t *testing.T) {
+	images := map[string]*image.NRGBA{
+		"a": atlasImage(1, image.Pt(0, 0), 5, 3),
+		"b": atlasImage(2, image.Pt(3, 1), 2, 7),
+		"c": atlasImage(3, image.Pt(0, 0), 1, 1),
+	}
+	b := AtlasBuilder{Padding: 1, Gutter: 1, MipLevels: 2}
+	for name, img := range images {
+		b.Add(name, img)
+	}
+	atlas, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each image starts on a texel of mip level 2, 4x4 pixels, and its
+	// edge fills the texels around it and the rest of its last texels,
+	// so no level-2 texel holds two images.
+	owner := map[image.Point]string{}
+	for name, region := range atlas.Regions {
+		r := region.Rect
+		if r.Min.X%4 != 0 || r.Min.Y%4 != 0 {
+			t.Errorf("region %q at %v is not 4-pixel aligned", name, r)
+		}
+		if r.Size() != images[name].Rect.Size() {
+			t.Errorf("region %q is %v, want %v", name, r.Size(), images[name].Rect.Size())
+		}
+		outer := image.Rect(r.Min.X-4, r.Min.Y-4, alignUp(r.Max.X, 4)+4, alignUp(r.Max.Y, 4)+4)
+		for y := outer.Min.Y; y < outer.Max.Y; y++ {
+			for x := outer.Min.X; x < outer.Max.X; x++ {
+				p := image.Pt(x, y)
+				if other, ok := owner[p]; ok {
+					t.Fatalf("regions %q and %q share pixel %v", name, other, p)
+				}
+				owner[p] = name
+				src := images[name]
+				cx := clampInt(x, r.Min.X, r.Max.X-1) - r.Min.X + src.Rect.Min.X
+				cy := clampInt(y, r.Min.Y, r.Max.Y-1) - r.Min.Y + src.Rect.Min.Y
+				if got, want := atlas.Image.NRGBAAt(x, y), src.NRGBAAt(cx, cy); got != want {
+					t.Errorf("region %q pixel %v = %v, want %v", name, p, got, want)
+				}
+			}
+		}
+	}
+	bounds := atlas.Image.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, ok := owner[image.Pt(x, y)]; !ok && atlas.Image.NRGBAAt(x, y) != (color.NRGBA{}) {
+				t.Errorf("padding pixel (%d, %d) = %v", x, y, atlas.Image.NRGBAAt(x, y))
+			}
+		}
+	}
+}
+
+func TestAtlasBuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder AtlasBuilder
+		images  map[string]image.Image
+	}{
+		{"negative padding", AtlasBuilder{Padding: -1}, map[string]image.Image{"a": atlasImage(1, image.Pt(0, 0), 1, 1)}},
+		{"empty image", AtlasBuilder{}, map[string]image.Image{"a": image.NewNRGBA(image.Rect(0, 0, 0, 4))}},
+		{"too big", AtlasBuilder{MaxSize: 8}, map[string]image.Image{"a": atlasImage(1, image.Pt(0, 0), 9, 1)}},
+		{"gutter too big", AtlasBuilder{MaxSize: 8, Gutter: 1}, map[string]image.Image{"a": atlasImage(1, image.Pt(0, 0), 7, 1)}},
+		{"negative mip levels", AtlasBuilder{MipLevels: -1}, map[string]image.Image{"a": atlasImage(1, image.Pt(0, 0), 1, 1)}},
+		{"mip gutter too big", AtlasBuilder{MaxSize: 8, MipLevels: 2}, map[string]image.Image{"a": atlasImage(1, image.Pt(0, 0), 1, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.builder
+			for name, img := range tt.images {
+				b.Add(name, img)
+			}
+			if _, err := b.Build(); err == nil {
+				t.Error("Build succeeded")
+			}
+		})
+	}
+
+	b := AtlasBuilder{}
+	b.Add("a", atlasImage(1, image.Pt(0, 0), 1, 1))
+	b.Add("a", atlasImage(2, image.Pt(0, 0), 1, 1))
+	if _, err := b.Build(); err == nil {
+		t.Error("Build accepted a name added twice")
+	}
+}
+
+func TestAtlasRegionRemapUVs(t *testing.T) {
+	r := AtlasRegion{UVMin: mgl32.Vec2{0.25, 0.5}, UVMax: mgl32.Vec2{0.75, 1}}
+	// Position x and UV, stride 3, UV at offset 1.
+	vertices := []float32{9, 0, 0, 9, 1, 1, 9, 0.5, 0.25}
+	r.RemapUVs(vertices, 3, 1)
+	want := []float32{9, 0.25, 0.5, 9, 0.75, 1, 9, 0.5, 0.625}
+	if !reflect.DeepEqual(vertices, want) {
+		t.Errorf("RemapUVs = %v, want %v", vertices, want)
+	}
+}
@@ -0,0 +1,185 @@
+package glutil
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Software decoder for BPTC float (BC6H) blocks, which hold RGB
+// half-floats: two endpoints per region, interpolated with the BC7
+// weights.
+
+// bc6hMode describes one of the fourteen BC6H block modes.
+type bc6hMode struct {
+	regions      int
+	transformed  bool   // endpoints after the first are deltas from it
+	endpointBits int    // bits of the first endpoint
+	deltaBits    [3]int // bits of the others, per channel
+	bits         []bc6hBit
+}
+
+// bc6hBit places one block bit into an endpoint channel.
+type bc6hBit struct {
+	endpoint, channel, bit uint8
+}
+
+// bc6hModes maps the mode field, 2 bits or, when those are 2 or 3, 5
+// bits, to its mode. The layouts list endpoint bits in block order in
+// the notation of the BPTC specification: r2[3:0] is bits 0 to 3 of the
+// red channel of endpoint 2, and a field written low:high is stored
+// reversed, its highest bit first. Missing mode fields are reserved.
+var bc6hModes = map[int]bc6hMode{
+	0x00: {2, true, 10, [3]int{5, 5, 5}, bc6hLayout("g2[4] b2[4] b3[4] r0[9:0] g0[9:0] b0[9:0] r1[4:0] g3[4] g2[3:0] g1[4:0] b3[0] g3[3:0] b1[4:0] b3[1] b2[3:0] r2[4:0] b3[2] r3[4:0] b3[3]")},
+	0x01: {2, true, 7, [3]int{6, 6, 6}, bc6hLayout("g2[5] g3[4] g3[5] r0[6:0] b3[0] b3[1] b2[4] g0[6:0] b2[5] b3[2] g2[4] b0[6:0] b3[3] b3[5] b3[4] r1[5:0] g2[3:0] g1[5:0] g3[3:0] b1[5:0] b2[3:0] r2[5:0] r3[5:0]")},
+	0x02: {2, true, 11, [3]int{5, 4, 4}, bc6hLayout("r0[9:0] g0[9:0] b0[9:0] r1[4:0] r0[10] g2[3:0] g1[3:0] g0[10] b3[0] g3[3:0] b1[3:0] b0[10] b3[1] b2[3:0] r2[4:0] b3[2] r3[4:0] b3[3]")},
+	0x06: {2, true, 11, [3]int{4, 5, 4}, bc6hLayout("r0[9:0] g0[9:0] b0[9:0] r1[3:0] r0[10] g3[4] g2[3:0] g1[4:0] g0[10] g3[3:0] b1[3:0] b0[10] b3[1] b2[3:0] r2[3:0] b3[0] b3[2] r3[3:0] g2[4] b3[3]")},
+	0x0a: {2, true, 11, [3]int{4, 4, 5}, bc6hLayout("r0[9:0] g0[9:0] b0[9:0] r1[3:0] r0[10] b2[4] g2[3:0] g1[3:0] g0[10] b3[0] g3[3:0] b1[4:0] b0[10] b2[3:0] r2[3:0] b3[1] b3[2] r3[3:0] b3[4] b3[3]")},
+	0x0e: {2, true, 9, [3]int{5, 5, 5}, bc6hLayout("r0[8:0] b2[4] g0[8:0] g2[4] b0[8:0] b3[4] r1[4:0] g3[4] g2[3:0] g1[4:0] b3[0] g3[3:0] b1[4:0] b3[1] b2[3:0] r2[4:0] b3[2] r3[4:0] b3[3]")},
+	0x12: {2, true, 8, [3]int{6, 5, 5}, bc6hLayout("r0[7:0] g3[4] b2[4] g0[7:0] b3[2] g2[4] b0[7:0] b3[3] b3[4] r1[5:0] g2[3:0] g1[4:0] b3[0] g3[3:0] b1[4:0] b3[1] b2[3:0] r2[5:0] r3[5:0]")},
+	0x16: {2, true, 8, [3]int{5, 6, 5}, bc6hLayout("r0[7:0] b3[0] b2[4] g0[7:0] g2[5] g2[4] b0[7:0] g3[5] b3[4] r1[4:0] g3[4] g2[3:0] g1[5:0] g3[3:0] b1[4:0] b3[1] b2[3:0] r2[4:0] b3[2] r3[4:0] b3[3]")},
+	0x1a: {2, true, 8, [3]int{5, 5, 6}, bc6hLayout("r0[7:0] b3[1] b2[4] g0[7:0] b2[5] g2[4] b0[7:0] b3[5] b3[4] r1[4:0] g3[4] g2[3:0] g1[4:0] b3[0] g3[3:0] b1[5:0] b2[3:0] r2[4:0] b3[2] r3[4:0] b3[3]")},
+	0x1e: {2, false, 6, [3]int{6, 6, 6}, bc6hLayout("r0[5:0] g3[4] b3[0] b3[1] b2[4] g0[5:0] g2[5] b2[5] b3[2] g2[4] b0[5:0] g3[5] b3[3] b3[5] b3[4] r1[5:0] g2[3:0] g1[5:0] g3[3:0] b1[5:0] b2[3:0] r2[5:0] r3[5:0]")},
+	0x03: {1, false, 10, [3]int{10, 10, 10}, bc6hLayout("r0[9:0] g0[9:0] b0[9:0] r1[9:0] g1[9:0] b1[9:0]")},
+	0x07: {1, true, 11, [3]int{9, 9, 9}, bc6hLayout("r0[9:0] g0[9:0] b0[9:0] r1[8:0] r0[10] g1[8:0] g0[10] b1[8:0] b0[10]")},
+	0x0b: {1, true, 12, [3]int{8, 8, 8}, bc6hLayout("r0[9:0] g0[9:0] b0[9:0] r1[7:0] r0[10:11] g1[7:0] g0[10:11] b1[7:0] b0[10:11]")},
+	0x0f: {1, true, 16, [3]int{4, 4, 4}, bc6hLayout("r0[9:0] g0[9:0] b0[9:0] r1[3:0] r0[10:15] g1[3:0] g0[10:15] b1[3:0] b0[10:15]")},
+}
+
+// bc6hLayout parses a mode's endpoint bit layout.
+func bc6hLayout(layout string) []bc6hBit {
+	var bits []bc6hBit
+	for _, field := range strings.Fields(layout) {
+		channel := strings.IndexByte("rgb", field[0])
+		endpoint := int(field[1] - '0')
+		r := strings.Split(strings.Trim(field[2:], "[]"), ":")
+		first, err := strconv.Atoi(r[len(r)-1])
+		if err != nil || channel < 0 || endpoint > 3 {
+			panic("glutil: bad BC6H layout field " + field)
+		}
+		last, _ := strconv.Atoi(r[0])
+		step := 1
+		if last < first {
+			step = -1
+		}
+		for bit := first; ; bit += step {
+			bits = append(bits, bc6hBit{uint8(endpoint), uint8(channel), uint8(bit)})
+			if bit == last {
+				break
+			}
+		}
+	}
+	return bits
+}
+
+// decodeBC6H decodes a BPTC float (BC6H) block into RGB texels. Reserved
+// mode blocks decode to black.
+func decodeBC6H(block []byte, signed bool, out *[16][3]float32) {
+	b := newBlockBits(block)
+	mode := b.read(2)
+	if mode > 1 {
+		mode |= b.read(3) << 2
+	}
+	m, ok := bc6hModes[mode]
+	if !ok {
+		*out = [16][3]float32{}
+		return
+	}
+	var endpoints [4][3]int
+	for _, f := range m.bits {
+		endpoints[f.endpoint][f.channel] |= b.read(1) << f.bit
+	}
+	partition := 0
+	if m.regions == 2 {
+		partition = b.read(5)
+	}
+
+	n := 2 * m.regions
+	mask := 1<<m.endpointBits - 1
+	for c := 0; c < 3; c++ {
+		if signed {
+			endpoints[0][c] = signExtend(endpoints[0][c], m.endpointBits)
+		}
+		for e := 1; e < n; e++ {
+			if m.transformed {
+				endpoints[e][c] = (endpoints[0][c] + signExtend(endpoints[e][c], m.deltaBits[c])) & mask
+			}
+			if signed {
+				endpoints[e][c] = signExtend(endpoints[e][c], m.endpointBits)
+			}
+		}
+		for e := 0; e < n; e++ {
+			endpoints[e][c] = bc6hUnquantize(endpoints[e][c], m.endpointBits, signed)
+		}
+	}
+
+	indexBits := 4
+	if m.regions == 2 {
+		indexBits = 3
+	}
+	weights := bc7Weights[indexBits]
+	for i := 0; i < 16; i++ {
+		region, bits := 0, indexBits
+		if m.regions == 2 {
+			region = int(bc7Partitions2[partition] >> i & 1)
+		}
+		if i == 0 || region == 1 && i == int(bc7Anchors2[partition]) {
+			bits--
+		}
+		w := weights[b.read(bits)]
+		e0, e1 := endpoints[2*region], endpoints[2*region+1]
+		for c := 0; c < 3; c++ {
+			v := (e0[c]*(64-w) + e1[c]*w + 32) >> 6
+			out[i][c] = halfToFloat(bc6hHalf(v, signed))
+		}
+	}
+}
+
+// signExtend treats the low bits of v as a two's complement number.
+func signExtend(v, bits int) int {
+	v &= 1<<bits - 1
+	if v>>(bits-1) != 0 {
+		v -= 1 << bits
+	}
+	return v
+}
+
+// bc6hUnquantize scales an endpoint of the given bits to 16 bits, or to
+// 15 bits and a sign when signed.
+func bc6hUnquantize(v, bits int, signed bool) int {
+	if !signed {
+		switch {
+		case bits >= 15, v == 0:
+			return v
+		case v == 1<<bits-1:
+			return 0xffff
+		}
+		return (v<<16 + 0x8000) >> bits
+	}
+	if bits >= 16 {
+		return v
+	}
+	sign := 1
+	if v < 0 {
+		sign, v = -1, -v
+	}
+	switch {
+	case v == 0:
+	case v >= 1<<(bits-1)-1:
+		v = 0x7fff
+	default:
+		v = (v<<15 + 0x4000) >> (bits - 1)
+	}
+	return sign * v
+}
+
+// bc6hHalf scales an interpolated value to the half-float bits it
+// stands for.
+func bc6hHalf(v int, signed bool) uint16 {
+	if !signed {
+		return uint16(v * 31 >> 6)
+	}
+	if v < 0 {
+		return 0x8000 | uint16(-v*31>>5)
+	}
+	return uint16(v * 31 >> 5)
+}
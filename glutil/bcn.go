@@ -0,0 +1,424 @@
+package glutil
+
+import "encoding/binary"
+
+// Software decoders for the BCn (S3TC, RGTC and BPTC) block formats. Each
+// decodes one 4x4 block into texels in row-major order. BC6H, which
+// decodes to floats, is in bc6h.go.
+
+// texel4x4 is a decoded block: 16 RGBA texels, row by row.
+type texel4x4 [16][4]uint8
+
+// decodeBC1 decodes a BC1 colour block. In three-colour mode, index 3 is
+// transparent black when punchthrough is set and opaque black otherwise;
+// BC2 and BC3 colour blocks always use four colours.
+func decodeBC1(block []byte, out *texel4x4, punchthrough, fourColor bool) {
+	c0 := binary.LittleEndian.Uint16(block[0:])
+	c1 := binary.LittleEndian.Uint16(block[2:])
+	var colors [4][4]uint8
+	colors[0] = rgb565(c0)
+	colors[1] = rgb565(c1)
+	if c0 > c1 || fourColor {
+		for c := 0; c < 3; c++ {
+			colors[2][c] = uint8((2*int(colors[0][c]) + int(colors[1][c]) + 1) / 3)
+			colors[3][c] = uint8((int(colors[0][c]) + 2*int(colors[1][c]) + 1) / 3)
+		}
+		colors[2][3], colors[3][3] = 0xff, 0xff
+	} else {
+		for c := 0; c < 3; c++ {
+			colors[2][c] = uint8((int(colors[0][c]) + int(colors[1][c])) / 2)
+		}
+		colors[2][3] = 0xff
+		if !punchthrough {
+			colors[3][3] = 0xff
+		}
+	}
+	indices := binary.LittleEndian.Uint32(block[4:])
+	for i := 0; i < 16; i++ {
+		out[i] = colors[indices>>(2*i)&3]
+	}
+}
+
+func rgb565(c uint16) [4]uint8 {
+	r, g, b := uint8(c>>11&0x1f), uint8(c>>5&0x3f), uint8(c&0x1f)
+	return [4]uint8{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2, 0xff}
+}
+
+// decodeBC2 decodes explicit 4-bit alpha followed by a BC1 colour block.
+func decodeBC2(block []byte, out *texel4x4) {
+	decodeBC1(block[8:], out, false, true)
+	alpha := binary.LittleEndian.Uint64(block)
+	for i := 0; i < 16; i++ {
+		a := uint8(alpha >> (4 * i) & 0xf)
+		out[i][3] = a<<4 | a
+	}
+}
+
+// decodeBC3 decodes a BC4 alpha block followed by a BC1 colour block.
+func decodeBC3(block []byte, out *texel4x4) {
+	decodeBC1(block[8:], out, false, true)
+	var alpha [16]uint8
+	decodeBC4Channel(block, &alpha)
+	for i := range alpha {
+		out[i][3] = alpha[i]
+	}
+}
+
+// decodeBC4Channel decodes one unsigned BC4 channel: two endpoints and a
+// 3-bit index per texel.
+func decodeBC4Channel(block []byte, out *[16]uint8) {
+	a0, a1 := int(block[0]), int(block[1])
+	var values [8]int
+	values[0], values[1] = a0, a1
+	if a0 > a1 {
+		for i := 1; i < 7; i++ {
+			values[i+1] = ((7-i)*a0 + i*a1 + 3) / 7
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			values[i+1] = ((5-i)*a0 + i*a1 + 2) / 5
+		}
+		values[6], values[7] = 0, 255
+	}
+	indices := bc4Indices(block)
+	for i := 0; i < 16; i++ {
+		out[i] = uint8(values[indices>>(3*i)&7])
+	}
+}
+
+// decodeBC4ChannelSigned decodes one signed BC4 channel into [-1, 1].
+func decodeBC4ChannelSigned(block []byte, out *[16]float32) {
+	a0, a1 := int(int8(block[0])), int(int8(block[1]))
+	if a0 == -128 {
+		a0 = -127
+	}
+	if a1 == -128 {
+		a1 = -127
+	}
+	var values [8]int
+	values[0], values[1] = a0, a1
+	if a0 > a1 {
+		for i := 1; i < 7; i++ {
+			values[i+1] = ((7-i)*a0 + i*a1) / 7
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			values[i+1] = ((5-i)*a0 + i*a1) / 5
+		}
+		values[6], values[7] = -127, 127
+	}
+	indices := bc4Indices(block)
+	for i := 0; i < 16; i++ {
+		out[i] = float32(values[indices>>(3*i)&7]) / 127
+	}
+}
+
+// bc4Indices returns the 48 index bits that follow the two endpoints.
+func bc4Indices(block []byte) uint64 {
+	var b [8]byte
+	copy(b[:6], block[2:8])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// bc7Mode describes one of the eight BC7 block modes.
+type bc7Mode struct {
+	subsets, partitionBits, rotationBits, indexSelectionBits int
+	colorBits, alphaBits, endpointPBits, sharedPBits         int
+	indexBits, index2Bits                                    int
+}
+
+var bc7Modes = [8]bc7Mode{
+	{3, 4, 0, 0, 4, 0, 1, 0, 3, 0},
+	{2, 6, 0, 0, 6, 0, 0, 1, 3, 0},
+	{3, 6, 0, 0, 5, 0, 0, 0, 2, 0},
+	{2, 6, 0, 0, 7, 0, 1, 0, 2, 0},
+	{1, 0, 2, 1, 5, 6, 0, 0, 2, 3},
+	{1, 0, 2, 0, 7, 8, 0, 0, 2, 2},
+	{1, 0, 0, 0, 7, 7, 1, 0, 4, 0},
+	{2, 6, 0, 0, 5, 5, 1, 0, 2, 0},
+}
+
+// bc7Weights are the interpolation weights, out of 64, for 2, 3 and 4-bit
+// indices.
+var bc7Weights = [5][]int{
+	2: {0, 21, 43, 64},
+	3: {0, 9, 18, 27, 37, 46, 55, 64},
+	4: {0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64},
+}
+
+// bc7Partitions2 gives, for each two-subset partition, a bit per texel
+// that is set for texels in subset 1.
+var bc7Partitions2 = [64]uint16{
+	0xcccc, 0x8888, 0xeeee, 0xecc8, 0xc880, 0xfeec, 0xfec8, 0xec80,
+	0xc800, 0xffec, 0xfe80, 0xe800, 0xffe8, 0xff00, 0xfff0, 0xf000,
+	0xf710, 0x008e, 0x7100, 0x08ce, 0x008c, 0x7310, 0x3100, 0x8cce,
+	0x088c, 0x3110, 0x6666, 0x366c, 0x17e8, 0x0ff0, 0x718e, 0x399c,
+	0xaaaa, 0xf0f0, 0x5a5a, 0x33cc, 0x3c3c, 0x55aa, 0x9696, 0xa55a,
+	0x73ce, 0x13c8, 0x324c, 0x3bdc, 0x6996, 0xc33c, 0x9966, 0x0660,
+	0x0272, 0x04e4, 0x4e40, 0x2720, 0xc936, 0x936c, 0x39c6, 0x639c,
+	0x9336, 0x9cc6, 0x817e, 0xe718, 0xccf0, 0x0fcc, 0x7744, 0xee22,
+}
+
+// bc7Partitions3 gives the subset of each texel for the three-subset
+// partitions.
+var bc7Partitions3 = [64][16]uint8{
+	{0, 0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 1, 2, 2, 2, 2},
+	{0, 0, 0, 1, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 2, 0, 0, 1, 2, 2, 1, 1, 2, 2, 1, 1},
+	{0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 1, 1, 0, 1, 1, 1},
+	{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2},
+	{0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 2, 2, 0, 0, 2, 2},
+	{0, 0, 2, 2, 0, 0, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1},
+	{0, 0, 1, 1, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1},
+	{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2},
+	{0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 2, 2},
+	{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2},
+	{0, 1, 1, 2, 0, 1, 1, 2, 0, 1, 1, 2, 0, 1, 1, 2},
+	{0, 1, 2, 2, 0, 1, 2, 2, 0, 1, 2, 2, 0, 1, 2, 2},
+	{0, 0, 1, 1, 0, 1, 1, 2, 1, 1, 2, 2, 1, 2, 2, 2},
+	{0, 0, 1, 1, 2, 0, 0, 1, 2, 2, 0, 0, 2, 2, 2, 0},
+	{0, 0, 0, 1, 0, 0, 1, 1, 0, 1, 1, 2, 1, 1, 2, 2},
+	{0, 1, 1, 1, 0, 0, 1, 1, 2, 0, 0, 1, 2, 2, 0, 0},
+	{0, 0, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2},
+	{0, 0, 2, 2, 0, 0, 2, 2, 0, 0, 2, 2, 1, 1, 1, 1},
+	{0, 1, 1, 1, 0, 1, 1, 1, 0, 2, 2, 2, 0, 2, 2, 2},
+	{0, 0, 0, 1, 0, 0, 0, 1, 2, 2, 2, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 0, 0, 1, 1, 0, 1, 2, 2, 0, 1, 2, 2},
+	{0, 0, 0, 0, 1, 1, 0, 0, 2, 2, 1, 0, 2, 2, 1, 0},
+	{0, 1, 2, 2, 0, 1, 2, 2, 0, 0, 1, 1, 0, 0, 0, 0},
+	{0, 0, 1, 2, 0, 0, 1, 2, 1, 1, 2, 2, 2, 2, 2, 2},
+	{0, 1, 1, 0, 1, 2, 2, 1, 1, 2, 2, 1, 0, 1, 1, 0},
+	{0, 0, 0, 0, 0, 1, 1, 0, 1, 2, 2, 1, 1, 2, 2, 1},
+	{0, 0, 2, 2, 1, 1, 0, 2, 1, 1, 0, 2, 0, 0, 2, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 2, 0, 0, 2, 2, 2, 2, 2},
+	{0, 0, 1, 1, 0, 1, 2, 2, 0, 1, 2, 2, 0, 0, 1, 1},
+	{0, 0, 0, 0, 2, 0, 0, 0, 2, 2, 1, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 2, 2, 2},
+	{0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 1, 2, 0, 0, 1, 1},
+	{0, 0, 1, 1, 0, 0, 1, 2, 0, 0, 2, 2, 0, 2, 2, 2},
+	{0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0},
+	{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 0, 0, 0, 0},
+	{0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2, 0},
+	{0, 1, 2, 0, 2, 0, 1, 2, 1, 2, 0, 1, 0, 1, 2, 0},
+	{0, 0, 1, 1, 2, 2, 0, 0, 1, 1, 2, 2, 0, 0, 1, 1},
+	{0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 0, 0, 0, 0, 1, 1},
+	{0, 1, 0, 1, 0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 2, 1, 2, 1, 2, 1},
+	{0, 0, 2, 2, 1, 1, 2, 2, 0, 0, 2, 2, 1, 1, 2, 2},
+	{0, 0, 2, 2, 0, 0, 1, 1, 0, 0, 2, 2, 0, 0, 1, 1},
+	{0, 2, 2, 0, 1, 2, 2, 1, 0, 2, 2, 0, 1, 2, 2, 1},
+	{0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 0, 1, 0, 1},
+	{0, 0, 0, 0, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1},
+	{0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 2, 2, 2, 2},
+	{0, 2, 2, 2, 0, 1, 1, 1, 0, 2, 2, 2, 0, 1, 1, 1},
+	{0, 0, 0, 2, 1, 1, 1, 2, 0, 0, 0, 2, 1, 1, 1, 2},
+	{0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2},
+	{0, 2, 2, 2, 0, 1, 1, 1, 0, 1, 1, 1, 0, 2, 2, 2},
+	{0, 0, 0, 2, 1, 1, 1, 2, 1, 1, 1, 2, 0, 0, 0, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 1, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 2, 2, 0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 2, 2},
+	{0, 0, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2, 0, 0, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2},
+	{0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 1},
+	{0, 2, 2, 2, 1, 2, 2, 2, 0, 2, 2, 2, 1, 2, 2, 2},
+	{0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 1, 1, 1, 2, 0, 1, 1, 2, 2, 0, 1, 2, 2, 2, 0},
+}
+
+// Anchor texels, whose index has an implied leading zero bit: of subset 1
+// in two-subset partitions, and of subsets 1 and 2 in three-subset ones.
+// Texel 0 anchors subset 0.
+var (
+	bc7Anchors2 = [64]uint8{
+		15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15,
+		15, 2, 8, 2, 2, 8, 8, 15, 2, 8, 2, 2, 8, 8, 2, 2,
+		15, 15, 6, 8, 2, 8, 15, 15, 2, 8, 2, 2, 2, 15, 15, 6,
+		6, 2, 6, 8, 15, 15, 2, 2, 15, 15, 15, 15, 15, 2, 2, 15,
+	}
+	bc7Anchors3a = [64]uint8{
+		3, 3, 15, 15, 8, 3, 15, 15, 8, 8, 6, 6, 6, 5, 3, 3,
+		3, 3, 8, 15, 3, 3, 6, 10, 5, 8, 8, 6, 8, 5, 15, 15,
+		8, 15, 3, 5, 6, 10, 8, 15, 15, 3, 15, 5, 15, 15, 15, 15,
+		3, 15, 5, 5, 5, 8, 5, 10, 5, 10, 8, 13, 15, 12, 3, 3,
+	}
+	bc7Anchors3b = [64]uint8{
+		15, 8, 8, 3, 15, 15, 3, 8, 15, 15, 15, 15, 15, 15, 15, 8,
+		15, 8, 15, 3, 15, 8, 15, 8, 3, 15, 6, 10, 15, 15, 10, 8,
+		15, 3, 15, 10, 10, 8, 9, 10, 6, 15, 8, 15, 3, 6, 6, 8,
+		15, 3, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 3, 15, 15, 8,
+	}
+)
+
+// blockBits reads a 128-bit little-endian block least significant bit
+// first.
+type blockBits struct {
+	lo, hi uint64
+	pos    uint
+}
+
+func newBlockBits(block []byte) *blockBits {
+	return &blockBits{lo: binary.LittleEndian.Uint64(block), hi: binary.LittleEndian.Uint64(block[8:])}
+}
+
+func (b *blockBits) read(n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		var bit uint64
+		if b.pos < 64 {
+			bit = b.lo >> b.pos & 1
+		} else {
+			bit = b.hi >> (b.pos - 64) & 1
+		}
+		v |= int(bit) << i
+		b.pos++
+	}
+	return v
+}
+
+// decodeBC7 decodes a BPTC (BC7) block. Reserved mode blocks decode to
+// transparent black.
+func decodeBC7(block []byte, out *texel4x4) {
+	mode := 0
+	for mode < 8 && block[0]>>mode&1 == 0 {
+		mode++
+	}
+	if mode == 8 {
+		*out = texel4x4{}
+		return
+	}
+	m := bc7Modes[mode]
+	b := newBlockBits(block)
+	b.pos = uint(mode + 1)
+	partition := b.read(m.partitionBits)
+	rotation := b.read(m.rotationBits)
+	indexSelection := b.read(m.indexSelectionBits)
+
+	var endpoints [3][2][4]int
+	for c := 0; c < 3; c++ {
+		for s := 0; s < m.subsets; s++ {
+			for e := 0; e < 2; e++ {
+				endpoints[s][e][c] = b.read(m.colorBits)
+			}
+		}
+	}
+	if m.alphaBits > 0 {
+		for s := 0; s < m.subsets; s++ {
+			for e := 0; e < 2; e++ {
+				endpoints[s][e][3] = b.read(m.alphaBits)
+			}
+		}
+	}
+
+	colorBits, alphaBits := m.colorBits, m.alphaBits
+	if m.endpointPBits > 0 || m.sharedPBits > 0 {
+		var pbits [3][2]int
+		for s := 0; s < m.subsets; s++ {
+			if m.sharedPBits > 0 {
+				p := b.read(1)
+				pbits[s] = [2]int{p, p}
+			} else {
+				pbits[s] = [2]int{b.read(1), b.read(1)}
+			}
+		}
+		for s := 0; s < m.subsets; s++ {
+			for e := 0; e < 2; e++ {
+				for c := 0; c < 4; c++ {
+					endpoints[s][e][c] = endpoints[s][e][c]<<1 | pbits[s][e]
+				}
+			}
+		}
+		colorBits++
+		if alphaBits > 0 {
+			alphaBits++
+		}
+	}
+	for s := 0; s < m.subsets; s++ {
+		for e := 0; e < 2; e++ {
+			for c := 0; c < 3; c++ {
+				endpoints[s][e][c] = expandBits(endpoints[s][e][c], colorBits)
+			}
+			if alphaBits > 0 {
+				endpoints[s][e][3] = expandBits(endpoints[s][e][3], alphaBits)
+			} else {
+				endpoints[s][e][3] = 255
+			}
+		}
+	}
+
+	subset := func(i int) int {
+		switch m.subsets {
+		case 2:
+			return int(bc7Partitions2[partition] >> i & 1)
+		case 3:
+			return int(bc7Partitions3[partition][i])
+		}
+		return 0
+	}
+	anchor := func(i, s int) bool {
+		switch {
+		case i == 0:
+			return true
+		case m.subsets == 2 && s == 1:
+			return i == int(bc7Anchors2[partition])
+		case m.subsets == 3 && s == 1:
+			return i == int(bc7Anchors3a[partition])
+		case m.subsets == 3 && s == 2:
+			return i == int(bc7Anchors3b[partition])
+		}
+		return false
+	}
+
+	var indices, indices2 [16]int
+	for i := 0; i < 16; i++ {
+		n := m.indexBits
+		if anchor(i, subset(i)) {
+			n--
+		}
+		indices[i] = b.read(n)
+	}
+	if m.index2Bits > 0 {
+		for i := 0; i < 16; i++ {
+			n := m.index2Bits
+			if i == 0 {
+				n--
+			}
+			indices2[i] = b.read(n)
+		}
+	}
+
+	for i := 0; i < 16; i++ {
+		s := subset(i)
+		colorIndex, colorWeights := indices[i], bc7Weights[m.indexBits]
+		alphaIndex, alphaWeights := indices[i], bc7Weights[m.indexBits]
+		if m.index2Bits > 0 {
+			alphaIndex, alphaWeights = indices2[i], bc7Weights[m.index2Bits]
+			if indexSelection == 1 {
+				colorIndex, alphaIndex = alphaIndex, colorIndex
+				colorWeights, alphaWeights = alphaWeights, colorWeights
+			}
+		}
+		e0, e1 := endpoints[s][0], endpoints[s][1]
+		var t [4]uint8
+		for c := 0; c < 3; c++ {
+			t[c] = interpolate64(e0[c], e1[c], colorWeights[colorIndex])
+		}
+		t[3] = interpolate64(e0[3], e1[3], alphaWeights[alphaIndex])
+		if rotation > 0 {
+			t[rotation-1], t[3] = t[3], t[rotation-1]
+		}
+		out[i] = t
+	}
+}
+
+// expandBits widens an n-bit value, 4 <= n <= 8, to 8 bits by repeating
+// its top bits.
+func expandBits(v, n int) int {
+	return v<<(8-n) | v>>(2*n-8)
+}
+
+func interpolate64(a, b, w int) uint8 {
+	return uint8(((64-w)*a + w*b + 32) >> 6)
+}
@@ -0,0 +1,169 @@
+package glutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math/bits"
+)
+
+// BMP compression methods.
+const (
+	bmpRGB            = 0
+	bmpBitFields      = 3
+	bmpAlphaBitFields = 6
+	bmpMaxImageLength = 1 << 28
+)
+
+// DecodeBMP decodes an uncompressed Windows or OS/2 bitmap at 1, 4, 8, 16,
+// 24 or 32 bits per pixel, including bit-field masks and alpha.
+func DecodeBMP(r io.Reader) (image.Image, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("bmp: %v", err)
+	}
+	if len(b) < 26 || string(b[:2]) != "BM" {
+		return nil, errors.New("bmp: not a BMP file")
+	}
+	le := binary.LittleEndian
+	dataOffset := int(le.Uint32(b[10:]))
+	headerSize := int(le.Uint32(b[14:]))
+	if 14+headerSize > len(b) {
+		return nil, errors.New("bmp: truncated header")
+	}
+
+	var width, height, depth, compression, colors int
+	paletteEntry := 4
+	h := b[14:]
+	switch {
+	case headerSize == 12:
+		width = int(int16(le.Uint16(h[4:])))
+		height = int(int16(le.Uint16(h[6:])))
+		depth = int(le.Uint16(h[10:]))
+		paletteEntry = 3
+	case headerSize >= 40:
+		width = int(int32(le.Uint32(h[4:])))
+		height = int(int32(le.Uint32(h[8:])))
+		depth = int(le.Uint16(h[14:]))
+		compression = int(le.Uint32(h[16:]))
+		colors = int(le.Uint32(h[32:]))
+	default:
+		return nil, fmt.Errorf("bmp: unsupported header size %d", headerSize)
+	}
+
+	// Positive heights are stored bottom row first.
+	topDown := height < 0
+	if topDown {
+		height = -height
+	}
+	if width <= 0 || height == 0 || width*height > bmpMaxImageLength {
+		return nil, fmt.Errorf("bmp: bad size %dx%d", width, height)
+	}
+
+	// Bit-field masks follow a 40-byte header, or are part of a longer one.
+	var masks [4]uint32
+	hasMasks := false
+	switch compression {
+	case bmpRGB:
+	case bmpBitFields, bmpAlphaBitFields:
+		n := 3
+		if compression == bmpAlphaBitFields || headerSize >= 56 {
+			n = 4
+		}
+		at := 14 + 40
+		if 14+40+4*n > len(b) {
+			return nil, errors.New("bmp: truncated bit-field masks")
+		}
+		for i := 0; i < n; i++ {
+			masks[i] = le.Uint32(b[at+4*i:])
+		}
+		hasMasks = true
+	default:
+		return nil, fmt.Errorf("bmp: unsupported compression %d", compression)
+	}
+	if !hasMasks {
+		switch depth {
+		case 16:
+			masks = [4]uint32{0x7c00, 0x03e0, 0x001f, 0}
+		case 32:
+			masks = [4]uint32{0xff0000, 0x00ff00, 0x0000ff, 0}
+		}
+	}
+
+	var palette []color.NRGBA
+	if depth <= 8 {
+		if colors == 0 || colors > 1<<depth {
+			colors = 1 << depth
+		}
+		at := 14 + headerSize
+		if at+colors*paletteEntry > len(b) {
+			return nil, errors.New("bmp: truncated palette")
+		}
+		for i := 0; i < colors; i++ {
+			e := b[at+i*paletteEntry:]
+			palette = append(palette, color.NRGBA{e[2], e[1], e[0], 0xff})
+		}
+	}
+
+	switch depth {
+	case 1, 4, 8, 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("bmp: unsupported depth %d", depth)
+	}
+	stride := (width*depth + 31) / 32 * 4
+	if dataOffset < 0 || dataOffset+stride*height > len(b) {
+		return nil, errors.New("bmp: truncated pixel data")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for row := 0; row < height; row++ {
+		src := b[dataOffset+row*stride:]
+		y := height - 1 - row
+		if topDown {
+			y = row
+		}
+		for x := 0; x < width; x++ {
+			var c color.NRGBA
+			switch depth {
+			case 1, 4, 8:
+				bit := x * depth
+				index := int(src[bit/8]>>(8-depth-bit%8)) & (1<<depth - 1)
+				if index >= len(palette) {
+					return nil, errors.New("bmp: color index out of range")
+				}
+				c = palette[index]
+			case 24:
+				c = color.NRGBA{src[3*x+2], src[3*x+1], src[3*x], 0xff}
+			case 16:
+				c = bmpMasked(uint32(le.Uint16(src[2*x:])), masks)
+			case 32:
+				c = bmpMasked(le.Uint32(src[4*x:]), masks)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img, nil
+}
+
+// bmpMasked extracts the channels of v selected by the red, green, blue
+// and alpha masks. Without an alpha mask the pixel is opaque.
+func bmpMasked(v uint32, masks [4]uint32) color.NRGBA {
+	var c [4]uint8
+	for i, m := range masks {
+		if m == 0 {
+			if i == 3 {
+				c[i] = 0xff
+			}
+			continue
+		}
+		shift := bits.TrailingZeros32(m)
+		width := bits.OnesCount32(m)
+		field := uint64(v&m) >> shift
+		max := uint64(1)<<width - 1
+		c[i] = uint8((field*255 + max/2) / max)
+	}
+	return color.NRGBA{c[0], c[1], c[2], c[3]}
+}
@@ -0,0 +1,267 @@
+package glutil
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// stageOrder is the order of the stages in the pipeline.
+var stageOrder = []uint32{
+	gl.VERTEX_SHADER,
+	gl.TESS_CONTROL_SHADER,
+	gl.TESS_EVALUATION_SHADER,
+	gl.GEOMETRY_SHADER,
+	gl.FRAGMENT_SHADER,
+}
+
+// ProgramBuilder links any combination of vertex, tessellation, geometry
+// and fragment shaders into a program:
+//
+//	program, err := loader.Builder("lit.vert", "normals.geom", "color.frag").Link()
+//
+// Programs with tessellation stages must be drawn with gl.PATCHES.
+type ProgramBuilder struct {
+	stages  []builderStage
+	defines map[string]string
+	cache   *ProgramCache
+	err     error
+}
+
+type builderStage struct {
+	shaderType uint32
+	name       string
+	source     *ShaderSource
+}
+
+// Builder loads the named files, taking each stage from the file
+// extension (see ShaderType). Load errors are returned by Link.
+func (l *ShaderLoader) Builder(files ...string) *ProgramBuilder {
+	b := &ProgramBuilder{}
+	for _, name := range files {
+		shaderType, ok := ShaderType(name)
+		if !ok {
+			b.fail(fmt.Errorf("%s: unknown shader stage, want .vert, .tesc, .tese, .geom or .frag", name))
+			continue
+		}
+		b.File(l, shaderType, name)
+	}
+	return b
+}
+
+// Build loads the named files and links them; see Builder.
+func (l *ShaderLoader) Build(files ...string) (uint32, error) {
+	return l.Builder(files...).Link()
+}
+
+// Source adds a stage from source code.
+func (b *ProgramBuilder) Source(shaderType uint32, source string) *ProgramBuilder {
+	return b.Stage(shaderType, StageName(shaderType), &ShaderSource{Code: source})
+}
+
+// File adds a stage loaded through l. The builder also takes l's defines
+// into its cache key, and l's cache if it has none yet.
+func (b *ProgramBuilder) File(l *ShaderLoader, shaderType uint32, name string) *ProgramBuilder {
+	source, err := l.LoadStage(shaderType, name)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+	if b.cache == nil {
+		b.cache = l.Cache
+	}
+	for k, v := range l.Defines {
+		if b.defines == nil {
+			b.defines = map[string]string{}
+		}
+		b.defines[k] = v
+	}
+	return b.Stage(shaderType, name, source)
+}
+
+// Cache makes Link look the program up in c before compiling, and store it
+// there after linking. A nil c disables caching.
+func (b *ProgramBuilder) Cache(c *ProgramCache) *ProgramBuilder {
+	b.cache = c
+	return b
+}
+
+// Stage adds a preprocessed stage. name is used in error messages.
+func (b *ProgramBuilder) Stage(shaderType uint32, name string, source *ShaderSource) *ProgramBuilder {
+	b.stages = append(b.stages, builderStage{shaderType, name, source})
+	return b
+}
+
+func (b *ProgramBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Validate checks that the stages form a pipeline GL can link: exactly
+// one of each stage, a vertex shader, and a tessellation evaluation shader
+// whenever there is a tessellation control shader.
+func (b *ProgramBuilder) Validate() error {
+	if b.err != nil {
+		return b.err
+	}
+	have := map[uint32]string{}
+	for _, s := range b.stages {
+		if !contains32(stageOrder, s.shaderType) {
+			return fmt.Errorf("%s: unsupported shader stage %s", s.name, StageName(s.shaderType))
+		}
+		if prev, ok := have[s.shaderType]; ok {
+			return fmt.Errorf("two %s shaders: %s and %s", StageName(s.shaderType), prev, s.name)
+		}
+		have[s.shaderType] = s.name
+	}
+	if _, ok := have[gl.VERTEX_SHADER]; !ok {
+		return fmt.Errorf("program has no vertex shader")
+	}
+	if name, ok := have[gl.TESS_CONTROL_SHADER]; ok {
+		if _, ok := have[gl.TESS_EVALUATION_SHADER]; !ok {
+			return fmt.Errorf("%s: tessellation control shader without a tessellation evaluation shader", name)
+		}
+	}
+	return nil
+}
+
+// Link validates, compiles and links the stages. Compile failures are
+// returned as a *ShaderError and link failures as a *LinkError. With a
+// cache, a stored binary for the same sources, defines and driver is used
+// instead when the driver accepts it.
+func (b *ProgramBuilder) Link() (uint32, error) {
+	if err := b.Validate(); err != nil {
+		return 0, err
+	}
+	stages := append([]builderStage(nil), b.stages...)
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stageIndex(stages[i].shaderType) < stageIndex(stages[j].shaderType)
+	})
+
+	var key string
+	if b.cache != nil {
+		cacheStages := make([]CacheStage, len(stages))
+		for i, s := range stages {
+			cacheStages[i] = CacheStage{s.shaderType, s.source.Code}
+		}
+		key = b.cache.Key(b.defines, cacheStages)
+		if program, ok := b.cache.Load(key); ok {
+			return program, nil
+		}
+	}
+
+	var shaders []stageShader
+	for _, s := range stages {
+		shader, err := s.source.Compile(s.shaderType)
+		if err != nil {
+			for _, c := range shaders {
+				gl.DeleteShader(c.shader)
+			}
+			return 0, err
+		}
+		shaders = append(shaders, stageShader{s.shaderType, shader})
+	}
+	program, err := link(shaders, b.cache != nil)
+	if err == nil && b.cache != nil {
+		if err := b.cache.Store(key, program); err != nil {
+			log.Println(err)
+		}
+	}
+	return program, err
+}
+
+func stageIndex(shaderType uint32) int {
+	for i, t := range stageOrder {
+		if t == shaderType {
+			return i
+		}
+	}
+	return len(stageOrder)
+}
+
+func contains32(list []uint32, v uint32) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkError is a failed program link. Drivers report link problems for
+// the program as a whole; Diagnostics assigns each line of the log to the
+// stage it mentions, where it mentions one.
+type LinkError struct {
+	Stages      []string // the linked stages in pipeline order
+	Log         string
+	Diagnostics []ShaderDiagnostic
+}
+
+func (e *LinkError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to link program (%s)", strings.Join(e.Stages, " + "))
+	if len(e.Diagnostics) == 0 {
+		fmt.Fprintf(&b, ": %s", strings.TrimRight(e.Log, "\x00\n"))
+		return b.String()
+	}
+	for _, d := range e.Diagnostics {
+		b.WriteString("\n  ")
+		if d.Stage != "" {
+			b.WriteString(d.Stage + ": ")
+		}
+		b.WriteString(d.Message)
+	}
+	return b.String()
+}
+
+// linkStageNames maps the words drivers use for a stage in link logs to
+// StageName's names. Longer phrases come first so "tessellation control"
+// wins over a bare "vertex" elsewhere in the line.
+var linkStageNames = []struct{ word, stage string }{
+	{"tessellation control", "tessellation control"},
+	{"tess control", "tessellation control"},
+	{"tessellation evaluation", "tessellation evaluation"},
+	{"tess eval", "tessellation evaluation"},
+	{"geometry", "geometry"},
+	{"vertex", "vertex"},
+	{"fragment", "fragment"},
+}
+
+// ParseLinkLog splits a program info log into one diagnostic per line,
+// attributing each to a stage when the line names one. NVIDIA-style
+// section headers such as "Fragment info" apply to the lines below them.
+func ParseLinkLog(log string) []ShaderDiagnostic {
+	var diags []ShaderDiagnostic
+	section := ""
+	for _, line := range strings.Split(strings.TrimRight(log, "\x00"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.Trim(line, "-") == "" {
+			continue
+		}
+		lower := strings.ToLower(line)
+		stage := ""
+		for _, n := range linkStageNames {
+			if strings.Contains(lower, n.word) {
+				stage = n.stage
+				break
+			}
+		}
+		if strings.HasSuffix(lower, " info") {
+			section = stage
+			continue
+		}
+		if stage == "" {
+			stage = section
+		}
+		severity := "error"
+		if strings.Contains(lower, "warning") {
+			severity = "warning"
+		}
+		diags = append(diags, ShaderDiagnostic{Stage: stage, Severity: severity, Message: line})
+	}
+	return diags
+}
@@ -0,0 +1,193 @@
+package glutil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// NewCubemap loads a cube map texture bound to texture unit 0 from six
+// square face images in the order +X, -X, +Y, -Y, +Z, -Z, or from one
+// equirectangular panorama, which is converted on the CPU. A single KTX,
+// KTX2 or DDS file holding a cube map is uploaded as it is. Face images
+// are used top row first, as cube maps expect, so FlipY does not apply.
+func NewCubemap(files []string, opts TextureOptions) (uint32, error) {
+	if len(files) == 1 && isTextureFile(files[0]) {
+		f, err := ReadTextureFile(files[0])
+		if err != nil {
+			return 0, err
+		}
+		if f.Faces != 6 {
+			return 0, fmt.Errorf("cubemap %q: not a cube map", files[0])
+		}
+		return f.Upload(opts)
+	}
+	if len(files) != 1 && len(files) != 6 {
+		return 0, fmt.Errorf("cubemap: want 6 faces or 1 panorama, got %d files", len(files))
+	}
+	var images []image.Image
+	for _, file := range files {
+		r, err := os.Open(file)
+		if err != nil {
+			return 0, fmt.Errorf("cubemap %q not found on disk: %v", file, err)
+		}
+		img, err := DecodeImage(file, r)
+		r.Close()
+		if err != nil {
+			return 0, err
+		}
+		images = append(images, img)
+	}
+	return NewCubemapImages(images, opts)
+}
+
+// NewCubemapImages is NewCubemap for decoded images. A panorama gives
+// faces a quarter of its width across.
+func NewCubemapImages(images []image.Image, opts TextureOptions) (uint32, error) {
+	if opts.FlipY {
+		return 0, fmt.Errorf("cubemap: FlipY does not apply to cube maps")
+	}
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	switch len(images) {
+	case 1:
+		faces := EquirectToCube(images[0], images[0].Bounds().Dx()/4)
+		images = faces[:]
+	case 6:
+	default:
+		return 0, fmt.Errorf("cubemap: want 6 faces or 1 panorama, got %d images", len(images))
+	}
+	size := images[0].Bounds().Size()
+	for i, img := range images {
+		if s := img.Bounds().Size(); s.X != s.Y || s != size {
+			return 0, fmt.Errorf("cubemap: face %d is %dx%d, want square faces of one size", i, s.X, s.Y)
+		}
+	}
+
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, texture)
+	opts.apply(gl.TEXTURE_CUBE_MAP)
+	for i, img := range images {
+		t := newTexels(img)
+		format, err := opts.internalFormat(t.xtype)
+		if err != nil {
+			gl.DeleteTextures(1, &texture)
+			return 0, fmt.Errorf("cubemap: %v", err)
+		}
+		t.upload(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), 0, format)
+	}
+	if opts.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+	}
+	return texture, nil
+}
+
+// EquirectToCube resamples an equirectangular panorama, longitude across
+// and latitude down with the top row looking straight up, into six size x
+// size cube map faces ordered +X, -X, +Y, -Y, +Z, -Z. The middle of the
+// panorama faces -Z, the way a default camera looks. Faces are RGBA32F
+// for an RGBA32F panorama, RGBA64 for a 16-bit one and RGBA otherwise.
+func EquirectToCube(panorama image.Image, size int) [6]image.Image {
+	if size < 1 {
+		size = 1
+	}
+	sample := panoramaSampler(panorama)
+	var faces [6]image.Image
+	for face := range faces {
+		hdr := NewRGBA32F(image.Rect(0, 0, size, size))
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				// s and t run across the face as GL samples it, with the
+				// first row at t = -1.
+				s := 2*(float64(x)+0.5)/float64(size) - 1
+				t := 2*(float64(y)+0.5)/float64(size) - 1
+				dx, dy, dz := cubeDirection(face, s, t)
+				length := math.Sqrt(dx*dx + dy*dy + dz*dz)
+				u := 0.5 + math.Atan2(dx, -dz)/(2*math.Pi)
+				v := 0.5 - math.Asin(dy/length)/math.Pi
+				hdr.SetRGBA32F(x, y, sample(u, v))
+			}
+		}
+		switch panorama.(type) {
+		case *RGBA32F:
+			faces[face] = hdr
+		case *image.RGBA64, *image.NRGBA64, *image.Gray16:
+			faces[face] = quantize(hdr, image.NewRGBA64(hdr.Rect))
+		default:
+			faces[face] = quantize(hdr, image.NewRGBA(hdr.Rect))
+		}
+	}
+	return faces
+}
+
+// cubeDirection returns the direction through s, t on a cube map face, as
+// in the cube map face selection table of the GL specification.
+func cubeDirection(face int, s, t float64) (x, y, z float64) {
+	switch face {
+	case 0:
+		return 1, -t, -s
+	case 1:
+		return -1, -t, s
+	case 2:
+		return s, 1, t
+	case 3:
+		return s, -1, -t
+	case 4:
+		return s, -t, 1
+	}
+	return -s, -t, -1
+}
+
+// panoramaSampler returns a bilinear sampler of img at u, v in [0, 1],
+// wrapping around in u. It reads non-float images premultiplied.
+func panoramaSampler(img image.Image) func(u, v float64) [4]float32 {
+	b := img.Bounds()
+	at := func(x, y int) [4]float32 {
+		x = ((x % b.Dx()) + b.Dx()) % b.Dx()
+		if y < 0 {
+			y = 0
+		} else if y >= b.Dy() {
+			y = b.Dy() - 1
+		}
+		if hdr, ok := img.(*RGBA32F); ok {
+			return hdr.RGBA32FAt(b.Min.X+x, b.Min.Y+y)
+		}
+		r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+		return [4]float32{float32(r) / 0xffff, float32(g) / 0xffff, float32(bl) / 0xffff, float32(a) / 0xffff}
+	}
+	return func(u, v float64) [4]float32 {
+		fx := u*float64(b.Dx()) - 0.5
+		fy := v*float64(b.Dy()) - 0.5
+		x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+		wx, wy := float32(fx-float64(x0)), float32(fy-float64(y0))
+		c00, c10 := at(x0, y0), at(x0+1, y0)
+		c01, c11 := at(x0, y0+1), at(x0+1, y0+1)
+		var c [4]float32
+		for i := range c {
+			top := c00[i]*(1-wx) + c10[i]*wx
+			bottom := c01[i]*(1-wx) + c11[i]*wx
+			c[i] = top*(1-wy) + bottom*wy
+		}
+		return c
+	}
+}
+
+// quantize sets dst from the premultiplied values in [0, 1] of hdr.
+func quantize(hdr *RGBA32F, dst draw.Image) image.Image {
+	c := func(f float32) uint16 { return uint16(math.Round(float64(clamp01(f)) * 0xffff)) }
+	for y := hdr.Rect.Min.Y; y < hdr.Rect.Max.Y; y++ {
+		for x := hdr.Rect.Min.X; x < hdr.Rect.Max.X; x++ {
+			v := hdr.RGBA32FAt(x, y)
+			dst.Set(x, y, color.RGBA64{c(v[0]), c(v[1]), c(v[2]), c(v[3])})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,161 @@
+package glutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// DDS header flags.
+const (
+	ddsHeaderLength  = 4 + 124
+	ddsDX10Length    = 20
+	ddsMipMapCount   = 0x20000
+	ddsFourCC        = 0x4
+	ddsRGB           = 0x40
+	ddsAlphaPixels   = 0x1
+	ddsCubeMap       = 0x200
+	ddsDX10CubeMap   = 0x4
+	ddsDX10Texture2D = 3
+)
+
+// ddsFourCCs maps the FourCC codes of legacy DDS headers to GL internal
+// formats.
+var ddsFourCCs = map[string]uint32{
+	"DXT1": gl.COMPRESSED_RGBA_S3TC_DXT1_EXT,
+	"DXT2": gl.COMPRESSED_RGBA_S3TC_DXT3_EXT,
+	"DXT3": gl.COMPRESSED_RGBA_S3TC_DXT3_EXT,
+	"DXT4": gl.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+	"DXT5": gl.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+	"ATI1": gl.COMPRESSED_RED_RGTC1,
+	"BC4U": gl.COMPRESSED_RED_RGTC1,
+	"BC4S": gl.COMPRESSED_SIGNED_RED_RGTC1,
+	"ATI2": gl.COMPRESSED_RG_RGTC2,
+	"BC5U": gl.COMPRESSED_RG_RGTC2,
+	"BC5S": gl.COMPRESSED_SIGNED_RG_RGTC2,
+}
+
+// ddsDXGIFormats maps the DXGI formats of DX10 headers to GL internal
+// formats.
+var ddsDXGIFormats = map[uint32]uint32{
+	2:  gl.RGBA32F,
+	10: gl.RGBA16F,
+	28: gl.RGBA8,
+	29: gl.SRGB8_ALPHA8,
+	71: gl.COMPRESSED_RGBA_S3TC_DXT1_EXT,
+	72: compressedSRGBAlphaS3TCDXT1,
+	74: gl.COMPRESSED_RGBA_S3TC_DXT3_EXT,
+	75: compressedSRGBAlphaS3TCDXT3,
+	77: gl.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+	78: compressedSRGBAlphaS3TCDXT5,
+	80: gl.COMPRESSED_RED_RGTC1,
+	81: gl.COMPRESSED_SIGNED_RED_RGTC1,
+	83: gl.COMPRESSED_RG_RGTC2,
+	84: gl.COMPRESSED_SIGNED_RG_RGTC2,
+	95: gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB,
+	96: gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB,
+	98: gl.COMPRESSED_RGBA_BPTC_UNORM_ARB,
+	99: gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM_ARB,
+}
+
+// ReadDDS reads a DirectDraw Surface file holding a 2D texture or cube map
+// in one of the formats of TexelFormat, with a legacy or DX10 header.
+// Uncompressed 32-bit files with channel masks become RGBA8.
+func ReadDDS(r io.Reader) (*TextureFile, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("dds: %v", err)
+	}
+	if len(b) < ddsHeaderLength || string(b[:4]) != "DDS " {
+		return nil, errors.New("dds: not a DDS file")
+	}
+	le := binary.LittleEndian
+	h := b[4:]
+	flags := le.Uint32(h[4:])
+	height, width := int(le.Uint32(h[8:])), int(le.Uint32(h[12:]))
+	levels := 1
+	if flags&ddsMipMapCount != 0 && le.Uint32(h[24:]) > 0 {
+		levels = int(le.Uint32(h[24:]))
+	}
+	pf := h[72:]
+	pfFlags, fourCC, bitCount := le.Uint32(pf[4:]), string(pf[8:12]), le.Uint32(pf[12:])
+	faces := 1
+	if le.Uint32(h[108:])&ddsCubeMap != 0 {
+		faces = 6
+	}
+	if !validImageSize(width, height) || levels > 32 {
+		return nil, fmt.Errorf("dds: bad size %dx%d with %d levels", width, height, levels)
+	}
+
+	at := ddsHeaderLength
+	var internalFormat uint32
+	var masks *[4]uint32
+	switch {
+	case pfFlags&ddsFourCC != 0 && fourCC == "DX10":
+		if len(b) < at+ddsDX10Length {
+			return nil, errors.New("dds: truncated DX10 header")
+		}
+		dx10 := b[at:]
+		at += ddsDX10Length
+		var ok bool
+		if internalFormat, ok = ddsDXGIFormats[le.Uint32(dx10)]; !ok {
+			return nil, fmt.Errorf("dds: unsupported DXGI format %d", le.Uint32(dx10))
+		}
+		if le.Uint32(dx10[4:]) != ddsDX10Texture2D || le.Uint32(dx10[12:]) > 1 {
+			return nil, errors.New("dds: only 2D textures and cube maps are supported")
+		}
+		if le.Uint32(dx10[8:])&ddsDX10CubeMap != 0 {
+			faces = 6
+		}
+	case pfFlags&ddsFourCC != 0:
+		var ok bool
+		if internalFormat, ok = ddsFourCCs[fourCC]; !ok {
+			return nil, fmt.Errorf("dds: unsupported FourCC %q", fourCC)
+		}
+	case pfFlags&ddsRGB != 0 && bitCount == 32:
+		internalFormat = gl.RGBA8
+		masks = &[4]uint32{le.Uint32(pf[16:]), le.Uint32(pf[20:]), le.Uint32(pf[24:]), 0}
+		if pfFlags&ddsAlphaPixels != 0 {
+			masks[3] = le.Uint32(pf[28:])
+		}
+	default:
+		return nil, errors.New("dds: unsupported pixel format")
+	}
+
+	// Unlike KTX, DDS stores each face with all its levels.
+	f := &TextureFile{Format: texelFormats[internalFormat], Width: width, Height: height, Faces: faces}
+	f.Levels = make([][][]byte, levels)
+	for face := 0; face < faces; face++ {
+		for level := 0; level < levels; level++ {
+			w, h := f.LevelSize(level)
+			size := f.Format.LevelSize(w, h)
+			if at+size > len(b) {
+				return nil, fmt.Errorf("dds: level %d of face %d truncated", level, face)
+			}
+			data := b[at : at+size]
+			at += size
+			if masks != nil {
+				data = ddsUnmask(data, *masks)
+			}
+			f.Levels[level] = append(f.Levels[level], data)
+		}
+	}
+	if err := f.check(); err != nil {
+		return nil, fmt.Errorf("dds: %v", err)
+	}
+	return f, nil
+}
+
+// ddsUnmask converts 32-bit pixels with channel masks, such as BGRA, to
+// RGBA.
+func ddsUnmask(data []byte, masks [4]uint32) []byte {
+	rgba := make([]byte, len(data))
+	for i := 0; i < len(data); i += 4 {
+		c := bmpMasked(binary.LittleEndian.Uint32(data[i:]), masks)
+		rgba[i], rgba[i+1], rgba[i+2], rgba[i+3] = c.R, c.G, c.B, c.A
+	}
+	return rgba
+}
@@ -0,0 +1,257 @@
+package glutil
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Dialect is a GLSL version that shader sources can be rewritten for.
+type Dialect struct {
+	Name    string
+	Version int // the #version number
+	ES      bool
+}
+
+// The dialects Rewrite targets.
+var (
+	GL33   = Dialect{Name: "GL 3.3", Version: 330}
+	GL41   = Dialect{Name: "GL 4.1", Version: 410}
+	GLES30 = Dialect{Name: "GLES 3.0", Version: 300, ES: true}
+	WebGL2 = Dialect{Name: "WebGL2", Version: 300, ES: true}
+)
+
+func (d Dialect) String() string {
+	return d.Name
+}
+
+// VersionLine returns the #version directive for d.
+func (d Dialect) VersionLine() string {
+	if d.ES {
+		return fmt.Sprintf("#version %d es", d.Version)
+	}
+	return fmt.Sprintf("#version %d core", d.Version)
+}
+
+// Supports reports whether d has the given shader stage.
+func (d Dialect) Supports(shaderType uint32) bool {
+	switch shaderType {
+	case gl.VERTEX_SHADER, gl.FRAGMENT_SHADER:
+		return true
+	case gl.GEOMETRY_SHADER:
+		return !d.ES
+	case gl.TESS_CONTROL_SHADER, gl.TESS_EVALUATION_SHADER:
+		return !d.ES && d.Version >= 400
+	}
+	return false
+}
+
+// interfaceLocations reports whether d allows layout(location) on the
+// variables passed between stages, not just on vertex inputs and
+// fragment outputs.
+func (d Dialect) interfaceLocations() bool {
+	return !d.ES && d.Version >= 410
+}
+
+// esSamplers have no default precision in GLSL ES 3.00.
+var esSamplers = []string{
+	"sampler3D", "samplerCubeShadow", "sampler2DShadow", "sampler2DArray", "sampler2DArrayShadow",
+	"isampler2D", "isampler3D", "isamplerCube", "isampler2DArray",
+	"usampler2D", "usampler3D", "usamplerCube", "usampler2DArray",
+}
+
+var (
+	versionLine = regexp.MustCompile(`^\s*#\s*version\s+(\d+)(\s+\w+)?\s*$`)
+	lineLine    = regexp.MustCompile(`^\s*#\s*line\b`)
+	esSampler   = regexp.MustCompile(`\b(` + strings.Join(esSamplers, "|") + `)\b`)
+	// declLine matches a global in/out/attribute/varying declaration:
+	// indent, layout arguments, qualifiers, storage, type, name, array, rest.
+	declLine    = regexp.MustCompile(`^(\s*)(?:layout\s*\(([^)]*)\)\s*)?((?:(?:flat|smooth|noperspective|centroid|invariant|highp|mediump|lowp)\s+)*)(attribute|varying|in|out)\s+(\w+(?:\s+\w+)*?)\s+(\w+)\s*(\[[^\]]*\])?\s*;(.*)$`)
+	legacyCalls = regexp.MustCompile(`\b(texture2D|texture3D|textureCube)\s*\(`)
+	legacyProj  = regexp.MustCompile(`\b(texture2DProj)\s*\(`)
+	fragColor   = regexp.MustCompile(`\bgl_FragColor\b`)
+)
+
+// Rewrite rewrites a shader of the given stage for dialect d:
+//
+//   - the #version line is replaced, or added if missing;
+//   - ES targets get default precision qualifiers;
+//   - attribute and varying become in and out, gl_FragColor becomes a
+//     declared output, and texture2D and friends become texture;
+//   - layout(location) is dropped from stage interface variables where d
+//     does not allow it;
+//   - fragment outputs named in fragData get layout(location = n), the
+//     declarative form of glBindFragDataLocation.
+//
+// Lines are only inserted directly after #version and are followed by a
+// #line directive, so compile errors keep pointing at the original lines.
+func (d Dialect) Rewrite(shaderType uint32, source string, fragData map[string]int) (string, error) {
+	if !d.Supports(shaderType) {
+		return "", fmt.Errorf("%s has no %s shaders", d, StageName(shaderType))
+	}
+	source = strings.TrimSuffix(source, "\x00")
+	lines := strings.Split(source, "\n")
+
+	versionAt := -1
+	for i, line := range lines {
+		if versionLine.MatchString(line) {
+			versionAt = i
+			break
+		}
+	}
+
+	var header, unlocated []string
+	usesFragColor, inComment := false, false
+	for i, line := range lines {
+		if i == versionAt {
+			continue
+		}
+		var lead, code, comment string
+		startsInComment := inComment
+		lead, code, comment, inComment = splitComment(line, inComment)
+		if !startsInComment && strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if m := declLine.FindStringSubmatch(code); m != nil {
+			var located bool
+			code, located = d.rewriteDecl(shaderType, m, fragData)
+			if shaderType == gl.FRAGMENT_SHADER && m[4] == "out" && !located {
+				unlocated = append(unlocated, m[6])
+			}
+		}
+		if shaderType == gl.FRAGMENT_SHADER && fragColor.MatchString(code) {
+			usesFragColor = true
+			code = fragColor.ReplaceAllString(code, "fragColor")
+		}
+		code = legacyCalls.ReplaceAllString(code, "texture(")
+		code = legacyProj.ReplaceAllString(code, "textureProj(")
+		lines[i] = lead + code + comment
+	}
+
+	if d.ES && len(unlocated) > 1 {
+		sort.Strings(unlocated)
+		return "", fmt.Errorf("%s needs a location for each of several fragment outputs: %s", d, strings.Join(unlocated, ", "))
+	}
+
+	if d.ES {
+		header = append(header, "precision highp float;", "precision highp int;")
+		used := map[string]bool{}
+		for _, s := range esSampler.FindAllString(source, -1) {
+			used[s] = true
+		}
+		for _, s := range esSamplers {
+			if used[s] {
+				header = append(header, "precision highp "+s+";")
+			}
+		}
+	}
+	if usesFragColor {
+		header = append(header, "layout(location = 0) out vec4 fragColor;")
+	}
+
+	out := make([]string, 0, len(lines)+len(header)+2)
+	if versionAt < 0 {
+		out = append(out, d.VersionLine())
+		out = append(out, header...)
+		out = append(out, "#line 1")
+		out = append(out, lines...)
+	} else {
+		out = append(out, lines[:versionAt]...)
+		out = append(out, d.VersionLine())
+		out = append(out, header...)
+		if len(header) > 0 && (versionAt+1 >= len(lines) || !lineLine.MatchString(lines[versionAt+1])) {
+			// #line n gives the number of the line after it.
+			out = append(out, "#line "+strconv.Itoa(versionAt+2))
+		}
+		out = append(out, lines[versionAt+1:]...)
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// rewriteDecl rebuilds an interface declaration matched by declLine. It
+// reports whether the result has a location.
+func (d Dialect) rewriteDecl(shaderType uint32, m []string, fragData map[string]int) (string, bool) {
+	indent, layout, qualifiers, storage, typ, name, array, rest := m[1], m[2], m[3], m[4], m[5], m[6], m[7], m[8]
+
+	switch {
+	case storage == "attribute":
+		storage = "in"
+	case storage == "varying" && shaderType == gl.VERTEX_SHADER:
+		storage = "out"
+	case storage == "varying":
+		storage = "in"
+	}
+
+	// Vertex inputs and fragment outputs can always have a location;
+	// anything between stages only where the dialect allows it.
+	endpoint := (shaderType == gl.VERTEX_SHADER && storage == "in") ||
+		(shaderType == gl.FRAGMENT_SHADER && storage == "out")
+
+	var args []string
+	located := false
+	for _, arg := range strings.Split(layout, ",") {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.ReplaceAll(arg, " ", ""), "location=") {
+			if !endpoint && !d.interfaceLocations() {
+				continue
+			}
+			located = true
+		}
+		args = append(args, arg)
+	}
+	if loc, ok := fragData[name]; ok && !located && shaderType == gl.FRAGMENT_SHADER && storage == "out" {
+		args = append([]string{fmt.Sprintf("location = %d", loc)}, args...)
+		located = true
+	}
+
+	var b strings.Builder
+	b.WriteString(indent)
+	if len(args) > 0 {
+		fmt.Fprintf(&b, "layout(%s) ", strings.Join(args, ", "))
+	}
+	fmt.Fprintf(&b, "%s%s %s %s%s;%s", qualifiers, storage, typ, name, array, rest)
+	return b.String(), located
+}
+
+// splitComment splits line into the end of a /* */ comment it starts
+// inside of, the code, and everything from the next comment on, which is
+// left as it is. inComment says whether the line starts inside a /* */
+// comment; the result says whether the next line does. As in glsl.Lex, a
+// /* comment ends at the first */ and // comments out the rest of a line.
+func splitComment(line string, inComment bool) (lead, code, comment string, stillInComment bool) {
+	if inComment {
+		end := strings.Index(line, "*/")
+		if end < 0 {
+			return line, "", "", true
+		}
+		lead, line, inComment = line[:end+2], line[end+2:], false
+	}
+	start := strings.Index(line, "/*")
+	if i := strings.Index(line, "//"); i >= 0 && (start < 0 || i < start) {
+		return lead, line[:i], line[i:], false
+	}
+	if start < 0 {
+		return lead, line, "", false
+	}
+	code, comment = line[:start], line[start:]
+	for i := 0; i < len(comment); i++ {
+		switch {
+		case inComment && strings.HasPrefix(comment[i:], "*/"):
+			inComment = false
+			i++
+		case !inComment && strings.HasPrefix(comment[i:], "//"):
+			return lead, code, comment, false
+		case !inComment && strings.HasPrefix(comment[i:], "/*"):
+			inComment = true
+			i++
+		}
+	}
+	return lead, code, comment, inComment
+}
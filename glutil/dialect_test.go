@@ -0,0 +1,183 @@
+package glutil
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenDialects are the dialects each testdata/dialect input is rewritten
+// for, keyed by the suffix of its golden file.
+var goldenDialects = map[string]Dialect{
+	"gl33":   GL33,
+	"gl41":   GL41,
+	"gles30": GLES30,
+	"webgl2": WebGL2,
+}
+
+// TestRewriteGolden rewrites each testdata/dialect/NAME.STAGE.glsl for
+// every dialect and compares it with NAME.STAGE.DIALECT.golden. Run with
+// -update to accept the current output.
+func TestRewriteGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "dialect", "*.glsl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no testdata/dialect inputs")
+	}
+	fragData := map[string]int{"albedo": 0, "packedNormal": 1}
+	for _, input := range inputs {
+		base := strings.TrimSuffix(input, ".glsl")
+		stage := map[string]uint32{".vert": gl.VERTEX_SHADER, ".frag": gl.FRAGMENT_SHADER}[filepath.Ext(base)]
+		src, err := os.ReadFile(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for suffix, d := range goldenDialects {
+			golden := base + "." + suffix + ".golden"
+			t.Run(filepath.Base(golden), func(t *testing.T) {
+				got, err := d.Rewrite(stage, string(src), fragData)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if *update {
+					if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != string(want) {
+					t.Errorf("Rewrite for %s:\n%s\nwant:\n%s", d, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		stage    uint32
+		src      string
+		fragData map[string]int
+		want     string // "" when an error is expected
+	}{
+		{
+			name:    "version added",
+			dialect: GL41, stage: gl.VERTEX_SHADER,
+			src:  "void main() {}",
+			want: "#version 410 core\n#line 1\nvoid main() {}",
+		},
+		{
+			name:    "existing line directive kept",
+			dialect: GLES30, stage: gl.VERTEX_SHADER,
+			src:  "#version 330 core\n#line 7 2\nvoid main() {}",
+			want: "#version 300 es\nprecision highp float;\nprecision highp int;\n#line 7 2\nvoid main() {}",
+		},
+		{
+			name:    "no header no line directive",
+			dialect: GL33, stage: gl.VERTEX_SHADER,
+			src:  "#version 410 core\nin vec3 p;",
+			want: "#version 330 core\nin vec3 p;",
+		},
+		{
+			name:    "interface location dropped before 4.1",
+			dialect: GL33, stage: gl.FRAGMENT_SHADER,
+			src:  "#version 410 core\nlayout(location = 2, component = 0) in vec3 n;\nlayout(location = 0) out vec4 c;",
+			want: "#version 330 core\nlayout(component = 0) in vec3 n;\nlayout(location = 0) out vec4 c;",
+		},
+		{
+			name:    "fragData location added",
+			dialect: GL41, stage: gl.FRAGMENT_SHADER,
+			src:      "#version 330 core\nout vec4 c; // colour",
+			fragData: map[string]int{"c": 3},
+			want:     "#version 410 core\nlayout(location = 3) out vec4 c; // colour",
+		},
+		{
+			name:    "declared location wins over fragData",
+			dialect: GL41, stage: gl.FRAGMENT_SHADER,
+			src:      "#version 330 core\nlayout(location = 1) out vec4 c;",
+			fragData: map[string]int{"c": 3},
+			want:     "#version 410 core\nlayout(location = 1) out vec4 c;",
+		},
+		{
+			name:    "block comment left alone",
+			dialect: GL41, stage: gl.FRAGMENT_SHADER,
+			src:  "#version 120\n/*\nvarying vec2 uv;\ngl_FragColor = texture2D(t, uv);\n*/ varying vec2 uv;",
+			want: "#version 410 core\n/*\nvarying vec2 uv;\ngl_FragColor = texture2D(t, uv);\n*/ in vec2 uv;",
+		},
+		{
+			name:    "directive inside block comment",
+			dialect: GL41, stage: gl.VERTEX_SHADER,
+			src:  "#version 120\n/*\n#define X\nattribute vec3 p;\n*/",
+			want: "#version 410 core\n/*\n#define X\nattribute vec3 p;\n*/",
+		},
+		{
+			name:    "several unlocated outputs on ES",
+			dialect: GLES30, stage: gl.FRAGMENT_SHADER,
+			src: "#version 330 core\nout vec4 a;\nout vec4 b;",
+		},
+		{
+			name:    "no geometry shaders on ES",
+			dialect: WebGL2, stage: gl.GEOMETRY_SHADER,
+			src: "#version 330 core\nvoid main() {}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dialect.Rewrite(tt.stage, tt.src, tt.fragData)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("Rewrite succeeded:\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Rewrite:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitComment(t *testing.T) {
+	tests := []struct {
+		line                string
+		inComment           bool
+		lead, code, comment string
+		stillInComment      bool
+	}{
+		{"in vec3 p;", false, "", "in vec3 p;", "", false},
+		{"in vec3 p; // x", false, "", "in vec3 p; ", "// x", false},
+		{"in vec3 p; /* x */", false, "", "in vec3 p; ", "/* x */", false},
+		{"in vec3 p; /* x", false, "", "in vec3 p; ", "/* x", true},
+		{"in vec3 p; // x /* y", false, "", "in vec3 p; ", "// x /* y", false},
+		{"in vec3 p; /* x // y", false, "", "in vec3 p; ", "/* x // y", true},
+		{"in vec3 p; /* x */ // y /* z", false, "", "in vec3 p; ", "/* x */ // y /* z", false},
+		{"in vec3 p; /* x */ out vec3 q; /* y", false, "", "in vec3 p; ", "/* x */ out vec3 q; /* y", true},
+		{"still a comment", true, "still a comment", "", "", true},
+		{"end */ in vec3 p; // x", true, "end */", " in vec3 p; ", "// x", false},
+		{"end */ /* again", true, "end */", " ", "/* again", true},
+	}
+	for _, tt := range tests {
+		lead, code, comment, in := splitComment(tt.line, tt.inComment)
+		if lead != tt.lead || code != tt.code || comment != tt.comment || in != tt.stillInComment {
+			t.Errorf("splitComment(%q, %v) = %q, %q, %q, %v, want %q, %q, %q, %v",
+				tt.line, tt.inComment, lead, code, comment, in, tt.lead, tt.code, tt.comment, tt.stillInComment)
+		}
+	}
+}
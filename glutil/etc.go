@@ -0,0 +1,262 @@
+package glutil
+
+import "encoding/binary"
+
+// Software decoders for the ETC2 and EAC block formats. Blocks are
+// big-endian and number their texels column by column; the decoders
+// return them row by row like the BCn ones.
+
+var etcModifiers = [8][2]int{
+	{2, 8}, {5, 17}, {9, 29}, {13, 42}, {18, 60}, {24, 80}, {33, 106}, {47, 183},
+}
+
+var etcDistances = [8]int{3, 6, 11, 16, 23, 32, 41, 64}
+
+// decodeETC2 decodes an ETC2 RGB block, which is ETC1 plus the T, H and
+// planar modes hidden in differential colours that overflow. With
+// punchthrough the differential bit is instead an opaque bit and, for
+// blocks that are not opaque, index 2 is transparent black.
+func decodeETC2(block []byte, out *texel4x4, punchthrough bool) {
+	b := block
+	diff := b[3]&2 != 0
+	opaque := true
+	if punchthrough {
+		opaque, diff = diff, true
+	}
+	bits := binary.BigEndian.Uint32(b[4:])
+	msb, lsb := bits>>16, bits&0xffff
+	index := func(i int) int {
+		// i is the row-major texel; the index bits are column-major.
+		j := (i%4)*4 + i/4
+		return int(msb>>j&1)<<1 | int(lsb>>j&1)
+	}
+
+	if !diff {
+		c1 := [3]int{int(b[0] >> 4), int(b[1] >> 4), int(b[2] >> 4)}
+		c2 := [3]int{int(b[0] & 0xf), int(b[1] & 0xf), int(b[2] & 0xf)}
+		for c := 0; c < 3; c++ {
+			c1[c], c2[c] = c1[c]<<4|c1[c], c2[c]<<4|c2[c]
+		}
+		etcSubblocks(b, c1, c2, index, out, true)
+		return
+	}
+
+	base := [3]int{int(b[0] >> 3), int(b[1] >> 3), int(b[2] >> 3)}
+	var second [3]int
+	for c := 0; c < 3; c++ {
+		d := int(b[c] & 7)
+		if d >= 4 {
+			d -= 8
+		}
+		second[c] = base[c] + d
+	}
+	switch {
+	case second[0] < 0 || second[0] > 31:
+		etcT(b, index, out, opaque)
+	case second[1] < 0 || second[1] > 31:
+		etcH(b, index, out, opaque)
+	case second[2] < 0 || second[2] > 31:
+		etcPlanar(b, out)
+	default:
+		for c := 0; c < 3; c++ {
+			base[c] = base[c]<<3 | base[c]>>2
+			second[c] = second[c]<<3 | second[c]>>2
+		}
+		etcSubblocks(b, base, second, index, out, opaque)
+	}
+}
+
+// etcSubblocks decodes the individual and differential modes: two 2x4 or
+// 4x2 subblocks, each a base colour plus a modifier from its table.
+func etcSubblocks(b []byte, c1, c2 [3]int, index func(int) int, out *texel4x4, opaque bool) {
+	flip := b[3]&1 != 0
+	tables := [2]int{int(b[3] >> 5), int(b[3] >> 2 & 7)}
+	for i := 0; i < 16; i++ {
+		x, y := i%4, i/4
+		sub := x / 2
+		if flip {
+			sub = y / 2
+		}
+		base := c1
+		if sub == 1 {
+			base = c2
+		}
+		idx := index(i)
+		if !opaque && idx == 2 {
+			out[i] = [4]uint8{}
+			continue
+		}
+		m := etcModifiers[tables[sub]][idx&1]
+		if idx&2 != 0 {
+			m = -m
+		}
+		if !opaque && idx&1 == 0 {
+			m = 0
+		}
+		out[i] = [4]uint8{clamp255(base[0] + m), clamp255(base[1] + m), clamp255(base[2] + m), 0xff}
+	}
+}
+
+// etcT decodes the T mode: one colour, and three more spread along the
+// grey axis around a second colour.
+func etcT(b []byte, index func(int) int, out *texel4x4, opaque bool) {
+	c1 := etc4([3]int{int(b[0]>>1&0xc | b[0]&3), int(b[1] >> 4), int(b[1] & 0xf)})
+	c2 := etc4([3]int{int(b[2] >> 4), int(b[2] & 0xf), int(b[3] >> 4)})
+	d := etcDistances[int(b[3]>>1&6|b[3]&1)]
+	paint := [4][3]int{c1, etcAdd(c2, d), c2, etcAdd(c2, -d)}
+	etcPaint(paint, index, out, opaque)
+}
+
+// etcH decodes the H mode: two colours, each moved both ways along the
+// grey axis.
+func etcH(b []byte, index func(int) int, out *texel4x4, opaque bool) {
+	r1 := int(b[0] >> 3 & 0xf)
+	g1 := int(b[0]&7)<<1 | int(b[1]>>4&1)
+	b1 := int(b[1]&8) | int(b[1]&3)<<1 | int(b[2]>>7)
+	r2 := int(b[2] >> 3 & 0xf)
+	g2 := int(b[2]&7)<<1 | int(b[3]>>7)
+	b2 := int(b[3] >> 3 & 0xf)
+	di := int(b[3]&4) | int(b[3]&1)<<1
+	if r1<<8|g1<<4|b1 >= r2<<8|g2<<4|b2 {
+		di |= 1
+	}
+	d := etcDistances[di]
+	c1, c2 := etc4([3]int{r1, g1, b1}), etc4([3]int{r2, g2, b2})
+	paint := [4][3]int{etcAdd(c1, d), etcAdd(c1, -d), etcAdd(c2, d), etcAdd(c2, -d)}
+	etcPaint(paint, index, out, opaque)
+}
+
+func etcPaint(paint [4][3]int, index func(int) int, out *texel4x4, opaque bool) {
+	for i := 0; i < 16; i++ {
+		idx := index(i)
+		if !opaque && idx == 2 {
+			out[i] = [4]uint8{}
+			continue
+		}
+		p := paint[idx]
+		out[i] = [4]uint8{clamp255(p[0]), clamp255(p[1]), clamp255(p[2]), 0xff}
+	}
+}
+
+// etcPlanar decodes the planar mode: a gradient through an origin colour
+// and colours at the right and bottom edges. It is always opaque.
+func etcPlanar(b []byte, out *texel4x4) {
+	ro := int(b[0] >> 1 & 0x3f)
+	gO := int(b[0]&1)<<6 | int(b[1]>>1&0x3f)
+	bo := int(b[1]&1)<<5 | int(b[2]>>3&3)<<3 | int(b[2]&3)<<1 | int(b[3]>>7)
+	rh := int(b[3]>>2&0x1f)<<1 | int(b[3]&1)
+	gh := int(b[4] >> 1)
+	bh := int(b[4]&1)<<5 | int(b[5]>>3)
+	rv := int(b[5]&7)<<3 | int(b[6]>>5)
+	gv := int(b[6]&0x1f)<<2 | int(b[7]>>6)
+	bv := int(b[7] & 0x3f)
+	e6 := func(v int) int { return v<<2 | v>>4 }
+	e7 := func(v int) int { return v<<1 | v>>6 }
+	o := [3]int{e6(ro), e7(gO), e6(bo)}
+	h := [3]int{e6(rh), e7(gh), e6(bh)}
+	v := [3]int{e6(rv), e7(gv), e6(bv)}
+	for i := 0; i < 16; i++ {
+		x, y := i%4, i/4
+		var t [4]uint8
+		for c := 0; c < 3; c++ {
+			t[c] = clamp255((x*(h[c]-o[c]) + y*(v[c]-o[c]) + 4*o[c] + 2) >> 2)
+		}
+		t[3] = 0xff
+		out[i] = t
+	}
+}
+
+func etc4(c [3]int) [3]int {
+	return [3]int{c[0]<<4 | c[0], c[1]<<4 | c[1], c[2]<<4 | c[2]}
+}
+
+func etcAdd(c [3]int, d int) [3]int {
+	return [3]int{c[0] + d, c[1] + d, c[2] + d}
+}
+
+func clamp255(v int) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+var eacModifiers = [16][8]int{
+	{-3, -6, -9, -15, 2, 5, 8, 14},
+	{-3, -7, -10, -13, 2, 6, 9, 12},
+	{-2, -5, -8, -13, 1, 4, 7, 12},
+	{-2, -4, -6, -13, 1, 3, 5, 12},
+	{-3, -6, -8, -12, 2, 5, 7, 11},
+	{-3, -7, -9, -11, 2, 6, 8, 10},
+	{-4, -7, -8, -11, 3, 6, 7, 10},
+	{-3, -5, -8, -11, 2, 4, 7, 10},
+	{-2, -6, -8, -10, 1, 5, 7, 9},
+	{-2, -5, -8, -10, 1, 4, 7, 9},
+	{-2, -4, -8, -10, 1, 3, 7, 9},
+	{-2, -5, -7, -10, 1, 4, 6, 9},
+	{-3, -4, -7, -10, 2, 3, 6, 9},
+	{-1, -2, -3, -10, 0, 1, 2, 9},
+	{-4, -6, -8, -9, 3, 5, 7, 8},
+	{-3, -5, -7, -9, 2, 4, 6, 8},
+}
+
+// eacIndex returns the 3-bit index of row-major texel i in an EAC block.
+func eacIndex(block []byte, i int) int {
+	bits := binary.BigEndian.Uint64(block)
+	j := (i%4)*4 + i/4
+	return int(bits >> (45 - 3*j) & 7)
+}
+
+// decodeEACAlpha decodes the 8-bit alpha block of ETC2 RGBA8.
+func decodeEACAlpha(block []byte, out *[16]uint8) {
+	base, mult, table := int(block[0]), int(block[1]>>4), block[1]&0xf
+	for i := 0; i < 16; i++ {
+		out[i] = clamp255(base + eacModifiers[table][eacIndex(block, i)]*mult)
+	}
+}
+
+// decodeEAC11 decodes an unsigned R11 channel to 16 bits.
+func decodeEAC11(block []byte, out *[16]uint16) {
+	base, mult, table := int(block[0]), int(block[1]>>4), block[1]&0xf
+	for i := 0; i < 16; i++ {
+		m := eacModifiers[table][eacIndex(block, i)]
+		v := base*8 + 4
+		if mult == 0 {
+			v += m
+		} else {
+			v += m * mult * 8
+		}
+		if v < 0 {
+			v = 0
+		} else if v > 2047 {
+			v = 2047
+		}
+		out[i] = uint16(v<<5 | v>>6)
+	}
+}
+
+// decodeEAC11Signed decodes a signed R11 channel into [-1, 1].
+func decodeEAC11Signed(block []byte, out *[16]float32) {
+	base, mult, table := int(int8(block[0])), int(block[1]>>4), block[1]&0xf
+	if base == -128 {
+		base = -127
+	}
+	for i := 0; i < 16; i++ {
+		m := eacModifiers[table][eacIndex(block, i)]
+		v := base * 8
+		if mult == 0 {
+			v += m
+		} else {
+			v += m * mult * 8
+		}
+		if v < -1023 {
+			v = -1023
+		} else if v > 1023 {
+			v = 1023
+		}
+		out[i] = float32(v) / 1023
+	}
+}
@@ -0,0 +1,201 @@
+package glsl
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// binaryPrec is the precedence of the binary operators allowed in #if,
+// higher binding tighter.
+var binaryPrec = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"|":  3,
+	"^":  4,
+	"&":  5,
+	"==": 6, "!=": 6,
+	"<": 7, ">": 7, "<=": 7, ">=": 7,
+	"<<": 8, ">>": 8,
+	"+": 9, "-": 9,
+	"*": 10, "/": 10, "%": 10,
+}
+
+// eval evaluates the integer expression of an #if or #elif. Macros are
+// replaced by their values and unknown identifiers are 0, as in C.
+func (l *lexer) eval(expr string) (int64, error) {
+	sub := &lexer{macros: l.macros}
+	if err := sub.tokens(expr); err != nil {
+		return 0, err
+	}
+	e := &evaluator{macros: l.macros, toks: sub.out}
+	v, err := e.binary(1)
+	if err != nil {
+		return 0, fmt.Errorf("#if %s: %v", expr, err)
+	}
+	if e.pos < len(e.toks) {
+		return 0, fmt.Errorf("#if %s: unexpected %q", expr, e.toks[e.pos].Text)
+	}
+	return v, nil
+}
+
+type evaluator struct {
+	macros map[string]string
+	toks   []Token
+	pos    int
+	depth  int // macro expansion depth
+}
+
+func (e *evaluator) peek() string {
+	if e.pos < len(e.toks) {
+		return e.toks[e.pos].Text
+	}
+	return ""
+}
+
+func (e *evaluator) next() (Token, error) {
+	if e.pos >= len(e.toks) {
+		return Token{}, fmt.Errorf("unexpected end of expression")
+	}
+	e.pos++
+	return e.toks[e.pos-1], nil
+}
+
+func (e *evaluator) binary(minPrec int) (int64, error) {
+	x, err := e.unary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := e.peek()
+		prec, ok := binaryPrec[op]
+		if !ok || prec < minPrec {
+			return x, nil
+		}
+		e.pos++
+		y, err := e.binary(prec + 1)
+		if err != nil {
+			return 0, err
+		}
+		if x, err = apply(op, x, y); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (e *evaluator) unary() (int64, error) {
+	t, err := e.next()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case t.Text == "!" || t.Text == "-" || t.Text == "~" || t.Text == "+":
+		x, err := e.unary()
+		if err != nil {
+			return 0, err
+		}
+		switch t.Text {
+		case "!":
+			return boolInt(x == 0), nil
+		case "-":
+			return -x, nil
+		case "~":
+			return ^x, nil
+		}
+		return x, nil
+	case t.Text == "(":
+		x, err := e.binary(1)
+		if err != nil {
+			return 0, err
+		}
+		if t, err := e.next(); err != nil || t.Text != ")" {
+			return 0, fmt.Errorf("missing )")
+		}
+		return x, nil
+	case t.Text == "defined":
+		paren := e.peek() == "("
+		if paren {
+			e.pos++
+		}
+		name, err := e.next()
+		if err != nil || name.Kind != Ident {
+			return 0, fmt.Errorf("defined needs a macro name")
+		}
+		if paren {
+			if t, err := e.next(); err != nil || t.Text != ")" {
+				return 0, fmt.Errorf("missing ) after defined(%s", name.Text)
+			}
+		}
+		_, ok := e.macros[name.Text]
+		return boolInt(ok), nil
+	case t.Kind == Number:
+		return strconv.ParseInt(t.Text, 0, 64)
+	case t.Kind == Ident:
+		value, ok := e.macros[t.Text]
+		if !ok || value == "" {
+			return 0, nil
+		}
+		if e.depth > 16 {
+			return 0, fmt.Errorf("macro %s expands too deeply", t.Text)
+		}
+		sub := &lexer{}
+		if err := sub.tokens(value); err != nil {
+			return 0, err
+		}
+		inner := &evaluator{macros: e.macros, toks: sub.out, depth: e.depth + 1}
+		return inner.binary(1)
+	}
+	return 0, fmt.Errorf("unexpected %q", t.Text)
+}
+
+func apply(op string, x, y int64) (int64, error) {
+	switch op {
+	case "||":
+		return boolInt(x != 0 || y != 0), nil
+	case "&&":
+		return boolInt(x != 0 && y != 0), nil
+	case "|":
+		return x | y, nil
+	case "^":
+		return x ^ y, nil
+	case "&":
+		return x & y, nil
+	case "==":
+		return boolInt(x == y), nil
+	case "!=":
+		return boolInt(x != y), nil
+	case "<":
+		return boolInt(x < y), nil
+	case ">":
+		return boolInt(x > y), nil
+	case "<=":
+		return boolInt(x <= y), nil
+	case ">=":
+		return boolInt(x >= y), nil
+	case "<<":
+		return x << uint64(y), nil
+	case ">>":
+		return x >> uint64(y), nil
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/", "%":
+		if y == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		if op == "/" {
+			return x / y, nil
+		}
+		return x % y, nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", op)
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,293 @@
+// Package glsl is a small GLSL front end written in Go: a lexer with a
+// preprocessor for conditionals and #line, a parser for global
+// declarations, and cross-stage checks. It needs no GL context.
+package glsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TokenKind is the kind of a Token.
+type TokenKind int
+
+const (
+	Ident TokenKind = iota
+	Number
+	Punct
+)
+
+// Token is a lexical token. File and Line follow #line directives, so they
+// point into the original files of preprocessed source.
+type Token struct {
+	Kind TokenKind
+	Text string
+	File int
+	Line int
+}
+
+func (t Token) String() string {
+	return fmt.Sprintf("%d:%d %q", t.File, t.Line, t.Text)
+}
+
+// puncts are the multi-character operators, longest first.
+var puncts = []string{
+	"<<=", ">>=",
+	"++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||", "^^",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=",
+}
+
+// Lex splits src into tokens. Comments are dropped. Preprocessor
+// directives are handled rather than returned: #define and #undef record
+// object-like macros, #if, #ifdef, #ifndef, #elif, #else and #endif skip
+// inactive code, and #line renumbers what follows. Macros are not
+// expanded in code; GLSL shaders rarely depend on that for declarations.
+func Lex(src string) ([]Token, error) {
+	return lex(src, false)
+}
+
+// LexAll is like Lex but keeps the code of every conditional branch and
+// ignores #error, giving the union of what any set of defines could
+// declare. The result need not be a valid shader.
+func LexAll(src string) ([]Token, error) {
+	return lex(src, true)
+}
+
+func lex(src string, all bool) ([]Token, error) {
+	l := &lexer{macros: map[string]string{}, all: all}
+	src = strings.TrimSuffix(src, "\x00")
+
+	inComment := false
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		l.line++
+		physical := i + 1
+		// Join continued lines. Their tokens take the number of the first
+		// one and l.joined moves the count past the others afterwards.
+		line := lines[i]
+		l.joined = 0
+		for strings.HasSuffix(strings.TrimSuffix(line, "\r"), "\\") && i+1 < len(lines) {
+			i++
+			l.joined++
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\r"), "\\") + " " + lines[i]
+		}
+		var err error
+		line, inComment = stripComments(line, inComment)
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			err = l.directive(strings.TrimSpace(line)[1:])
+		} else if l.active() {
+			err = l.tokens(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", physical, err)
+		}
+		l.line += l.joined
+	}
+	if len(l.conds) > 0 {
+		return nil, fmt.Errorf("missing #endif")
+	}
+	return l.out, nil
+}
+
+type cond struct {
+	active, taken, parentActive bool
+	sawElse                     bool
+}
+
+type lexer struct {
+	out    []Token
+	all    bool
+	file   int
+	line   int
+	joined int // continuation lines joined onto the current one
+	macros map[string]string
+	conds  []cond
+}
+
+func (l *lexer) active() bool {
+	return l.all || len(l.conds) == 0 || l.conds[len(l.conds)-1].active
+}
+
+// stripComments removes comments from line; inComment says whether the
+// line starts inside a /* */ comment.
+func stripComments(line string, inComment bool) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inComment:
+			if strings.HasPrefix(line[i:], "*/") {
+				inComment = false
+				i++
+				b.WriteByte(' ')
+			}
+		case strings.HasPrefix(line[i:], "//"):
+			return b.String(), false
+		case strings.HasPrefix(line[i:], "/*"):
+			inComment = true
+			i++
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+	return b.String(), inComment
+}
+
+func (l *lexer) directive(d string) error {
+	d = strings.TrimSpace(d)
+	name, rest := d, ""
+	if i := strings.IndexFunc(d, unicode.IsSpace); i >= 0 {
+		name, rest = d[:i], strings.TrimSpace(d[i:])
+	}
+
+	switch name {
+	case "ifdef", "ifndef", "if":
+		parent := l.active()
+		var v bool
+		switch name {
+		case "ifdef":
+			_, v = l.macros[rest]
+		case "ifndef":
+			_, v = l.macros[rest]
+			v = !v
+		default:
+			n, err := l.eval(rest)
+			if err != nil && parent && !l.all {
+				return err
+			}
+			v = n != 0
+		}
+		l.conds = append(l.conds, cond{active: parent && v, taken: v, parentActive: parent})
+		return nil
+	case "elif":
+		if len(l.conds) == 0 {
+			return fmt.Errorf("#elif without #if")
+		}
+		c := &l.conds[len(l.conds)-1]
+		if c.taken || !c.parentActive {
+			c.active = false
+			return nil
+		}
+		n, err := l.eval(rest)
+		if err != nil && !l.all {
+			return err
+		}
+		c.active = n != 0
+		c.taken = c.active
+		return nil
+	case "else":
+		if len(l.conds) == 0 {
+			return fmt.Errorf("#else without #if")
+		}
+		c := &l.conds[len(l.conds)-1]
+		if c.sawElse {
+			return fmt.Errorf("#else after #else")
+		}
+		c.sawElse = true
+		c.active = c.parentActive && !c.taken
+		c.taken = true
+		return nil
+	case "endif":
+		if len(l.conds) == 0 {
+			return fmt.Errorf("#endif without #if")
+		}
+		l.conds = l.conds[:len(l.conds)-1]
+		return nil
+	}
+
+	if !l.active() {
+		return nil
+	}
+	switch name {
+	case "define":
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return fmt.Errorf("#define without a name")
+		}
+		if strings.Contains(fields[0], "(") {
+			// Function-like macros are not expanded; record the name only.
+			fields[0] = fields[0][:strings.Index(fields[0], "(")]
+		}
+		l.macros[fields[0]] = strings.TrimSpace(strings.TrimPrefix(rest, fields[0]))
+	case "undef":
+		delete(l.macros, rest)
+	case "line":
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return fmt.Errorf("#line without a number")
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("#line %s: %v", fields[0], err)
+		}
+		// #line n numbers the line after the directive n, counting from
+		// the directive's last continued line.
+		l.line = n - 1 - l.joined
+		if len(fields) > 1 {
+			if l.file, err = strconv.Atoi(fields[1]); err != nil {
+				return fmt.Errorf("#line source %s: %v", fields[1], err)
+			}
+		}
+	case "error":
+		if !l.all {
+			return fmt.Errorf("#error %s", rest)
+		}
+	}
+	return nil
+}
+
+func (l *lexer) tokens(line string) error {
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(line) && isIdentPart(line[j]) {
+				j++
+			}
+			l.emit(Ident, line[i:j])
+			i = j
+		case isDigit(c) || (c == '.' && i+1 < len(line) && isDigit(line[i+1])):
+			j := i + 1
+			for j < len(line) && (isIdentPart(line[j]) || line[j] == '.' ||
+				((line[j] == '+' || line[j] == '-') && (line[j-1] == 'e' || line[j-1] == 'E'))) {
+				j++
+			}
+			l.emit(Number, line[i:j])
+			i = j
+		default:
+			text := line[i : i+1]
+			for _, p := range puncts {
+				if strings.HasPrefix(line[i:], p) {
+					text = p
+					break
+				}
+			}
+			if !strings.ContainsAny(text[:1], "+-*/%<>=!&|^~?:;,.()[]{}") {
+				return fmt.Errorf("unexpected character %q", text)
+			}
+			l.emit(Punct, text)
+			i += len(text)
+		}
+	}
+	return nil
+}
+
+func (l *lexer) emit(kind TokenKind, text string) {
+	l.out = append(l.out, Token{Kind: kind, Text: text, File: l.file, Line: l.line})
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
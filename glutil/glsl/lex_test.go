@@ -0,0 +1,143 @@
+package glsl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// positions renders tokens as "file:line text", one per token.
+func positions(toks []Token) string {
+	var b strings.Builder
+	for _, t := range toks {
+		fmt.Fprintf(&b, "%d:%d %s\n", t.File, t.Line, t.Text)
+	}
+	return b.String()
+}
+
+func TestLexLines(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "plain",
+			src:  "in vec3 p;\n\nout float f;",
+			want: "0:1 in\n0:1 vec3\n0:1 p\n0:1 ;\n0:3 out\n0:3 float\n0:3 f\n0:3 ;\n",
+		},
+		{
+			name: "continued define",
+			src:  "#define SCALE \\\n    2\n#if SCALE == 2\nfloat a;\n#endif\nint b;",
+			want: "0:4 float\n0:4 a\n0:4 ;\n0:6 int\n0:6 b\n0:6 ;\n",
+		},
+		{
+			name: "continued code",
+			src:  "uniform \\\nfloat \\\nc;\nint d;",
+			want: "0:1 uniform\n0:1 float\n0:1 c\n0:1 ;\n0:4 int\n0:4 d\n0:4 ;\n",
+		},
+		{
+			name: "continued with CRLF",
+			src:  "uniform \\\r\nfloat c;\r\nint d;\r\n",
+			want: "0:1 uniform\n0:1 float\n0:1 c\n0:1 ;\n0:3 int\n0:3 d\n0:3 ;\n",
+		},
+		{
+			name: "continued line directive",
+			src:  "#line 10 \\\n  2\nfloat e;",
+			want: "2:10 float\n2:10 e\n2:10 ;\n",
+		},
+		{
+			name: "line directive",
+			src:  "float a;\n#line 1 1\nfloat b;\n#line 20\nfloat c;",
+			want: "0:1 float\n0:1 a\n0:1 ;\n1:1 float\n1:1 b\n1:1 ;\n1:20 float\n1:20 c\n1:20 ;\n",
+		},
+		{
+			name: "comments",
+			src:  "/* a\n b */ float x; // y\n/* z */ int w; /* v\n*/\nuint u;",
+			want: "0:2 float\n0:2 x\n0:2 ;\n0:3 int\n0:3 w\n0:3 ;\n0:5 uint\n0:5 u\n0:5 ;\n",
+		},
+		{
+			name: "operators and numbers",
+			src:  "a <<= 1.5e-3; b++",
+			want: "0:1 a\n0:1 <<=\n0:1 1.5e-3\n0:1 ;\n0:1 b\n0:1 ++\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks, err := Lex(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := positions(toks); got != tt.want {
+				t.Errorf("Lex:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLexConditionals(t *testing.T) {
+	src := `#define LIT 1
+#ifdef TEXTURED
+uniform sampler2D tex;
+#elif LIT && defined(LIT)
+uniform vec3 light;
+#else
+uniform vec4 color;
+#endif
+#ifndef TEXTURED
+in vec2 uv;
+#endif
+#undef LIT
+#if defined(LIT)
+in vec3 normal;
+#endif
+`
+	names := func(toks []Token) string {
+		var ns []string
+		for i, t := range toks {
+			if i+1 < len(toks) && toks[i+1].Text == ";" {
+				ns = append(ns, t.Text)
+			}
+		}
+		return strings.Join(ns, " ")
+	}
+
+	toks, err := Lex(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(toks), "light uv"; got != want {
+		t.Errorf("Lex declares %q, want %q", got, want)
+	}
+	toks, err = LexAll(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(toks), "tex light color uv normal"; got != want {
+		t.Errorf("LexAll declares %q, want %q", got, want)
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"float a;\n#endif", "line 2: #endif without #if"},
+		{"#if 1\nfloat a;", "missing #endif"},
+		{"#if 1\n#else\n#else\n#endif", "line 3: #else after #else"},
+		{"#define A \\\n 1\n#error no \\\n good", "line 3: #error no   good"},
+		{"float a @ b;", "line 1: unexpected character \"@\""},
+		{"#line x", "line 1: #line x:"},
+		{"#if 1 +\n#endif", "line 1:"},
+	}
+	for _, tt := range tests {
+		_, err := Lex(tt.src)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Lex(%q) error = %v, want prefix %q", tt.src, err, tt.want)
+		}
+	}
+	if _, err := LexAll("#error skipped\nfloat a;"); err != nil {
+		t.Errorf("LexAll reported #error: %v", err)
+	}
+}
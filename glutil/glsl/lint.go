@@ -0,0 +1,249 @@
+package glsl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity says whether a Problem stops a program from linking.
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Problem is something Lint found.
+type Problem struct {
+	Pos      string // "file:line"
+	Severity Severity
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Pos, p.Severity, p.Message)
+}
+
+// Lint checks the stages of one program against each other:
+//
+//   - outputs of a stage that the next stage does not read (warning);
+//   - inputs of a stage that the previous stage does not write (error);
+//   - variables passed between stages, and uniforms declared in more
+//     than one stage, whose types differ (error);
+//   - uniforms outside blocks that no stage uses (warning).
+//
+// Built-in gl_ variables are ignored.
+func Lint(stages ...*Shader) []Problem {
+	sorted := append([]*Shader(nil), stages...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Stage < sorted[j].Stage })
+
+	var problems []Problem
+	for i := 0; i+1 < len(sorted); i++ {
+		problems = append(problems, lintInterface(sorted[i], sorted[i+1])...)
+	}
+	return append(problems, lintUniforms(sorted)...)
+}
+
+// arrayedInputs reports whether the inputs of stage are per-vertex
+// arrays, as in geometry and tessellation shaders.
+func arrayedInputs(stage Stage) bool {
+	return stage == TessControl || stage == TessEvaluation || stage == Geometry
+}
+
+// interfaceType is the type of an interface variable as seen from one
+// vertex, dropping the per-vertex array of arrayed stages.
+func interfaceType(d Decl, arrayed bool) string {
+	array := d.Array
+	if arrayed && strings.HasPrefix(array, "[") {
+		array = array[strings.Index(array, "]")+1:]
+	}
+	return d.Type + array
+}
+
+func lintInterface(from, to *Shader) []Problem {
+	var problems []Problem
+	outputs := map[string]Decl{}
+	for _, d := range from.Decls {
+		if d.Storage == "out" && d.Block == "" && !builtin(d.Name) {
+			outputs[d.Name] = d
+		}
+	}
+	read := map[string]bool{}
+	for _, in := range to.Decls {
+		if in.Storage != "in" || in.Block != "" || builtin(in.Name) {
+			continue
+		}
+		out, ok := outputs[in.Name]
+		if !ok {
+			problems = append(problems, Problem{
+				Pos:      to.Pos(in),
+				Severity: Error,
+				Message:  fmt.Sprintf("%s input %s is not written by the %s shader", to.Stage, in.Name, from.Stage),
+			})
+			continue
+		}
+		read[in.Name] = true
+		outType := interfaceType(out, from.Stage == TessControl)
+		inType := interfaceType(in, arrayedInputs(to.Stage))
+		if outType != inType {
+			problems = append(problems, Problem{
+				Pos:      to.Pos(in),
+				Severity: Error,
+				Message: fmt.Sprintf("%s input %s is %s but the %s shader writes %s (%s)",
+					to.Stage, in.Name, inType, from.Stage, outType, from.Pos(out)),
+			})
+		}
+	}
+	for _, out := range from.Decls {
+		if _, ok := outputs[out.Name]; ok && !read[out.Name] {
+			problems = append(problems, Problem{
+				Pos:      from.Pos(out),
+				Severity: Warning,
+				Message:  fmt.Sprintf("%s output %s is not read by the %s shader", from.Stage, out.Name, to.Stage),
+			})
+		}
+	}
+	return problems
+}
+
+func lintUniforms(stages []*Shader) []Problem {
+	var problems []Problem
+	type declared struct {
+		shader *Shader
+		decl   Decl
+	}
+	first := map[string]declared{}
+	used := map[string]bool{}
+	var order []string
+	for _, s := range stages {
+		for _, d := range s.Decls {
+			if d.Storage != "uniform" || builtin(d.Name) {
+				continue
+			}
+			key := d.Block + "." + d.Name
+			if s.Uses[d.Name] > 0 || d.Block != "" {
+				used[key] = true
+			}
+			prev, ok := first[key]
+			if !ok {
+				first[key] = declared{s, d}
+				order = append(order, key)
+				continue
+			}
+			if prev.decl.Type+prev.decl.Array != d.Type+d.Array {
+				problems = append(problems, Problem{
+					Pos:      s.Pos(d),
+					Severity: Error,
+					Message: fmt.Sprintf("uniform %s is %s%s in the %s shader but %s%s in the %s shader (%s)",
+						d.Name, d.Type, d.Array, s.Stage, prev.decl.Type, prev.decl.Array, prev.shader.Stage, prev.shader.Pos(prev.decl)),
+				})
+			}
+		}
+	}
+	for _, key := range order {
+		if used[key] {
+			continue
+		}
+		f := first[key]
+		problems = append(problems, Problem{
+			Pos:      f.shader.Pos(f.decl),
+			Severity: Warning,
+			Message:  fmt.Sprintf("uniform %s is declared but never used", f.decl.Name),
+		})
+	}
+	return problems
+}
+
+// LookupKind is what a name is looked up as from Go code.
+type LookupKind int
+
+const (
+	UniformLookup LookupKind = iota
+	AttribLookup
+	FragDataLookup
+	BlockLookup
+)
+
+var lookupNames = [...]string{"uniform", "attribute", "fragment output", "uniform block"}
+
+func (k LookupKind) String() string {
+	return lookupNames[k]
+}
+
+// Lookup is a name that Go code looks up in a program, such as the
+// argument of gl.GetUniformLocation or Program.SetMat4.
+type Lookup struct {
+	Kind LookupKind
+	Name string
+	Pos  string // where the Go code looks it up
+}
+
+// CheckLookups reports lookups of names that none of stages declares. A
+// uniform lookup that finds a block member is also reported, since block
+// members are set through a uniform buffer rather than a location.
+// Uniform names may carry array indices and struct fields, as in
+// "lights[0].color"; only the base name is checked.
+func CheckLookups(lookups []Lookup, stages ...*Shader) []Problem {
+	var problems []Problem
+	for _, l := range lookups {
+		name := l.Name
+		if i := strings.IndexAny(name, "[."); i >= 0 {
+			name = name[:i]
+		}
+		var found bool
+		var block string
+		for _, s := range stages {
+			switch l.Kind {
+			case UniformLookup:
+				if d, ok := s.Lookup("uniform", name); ok {
+					found = true
+					block = d.Block
+				}
+			case AttribLookup:
+				if s.Stage == Vertex {
+					_, found = s.Lookup("in", name)
+				}
+			case FragDataLookup:
+				if s.Stage == Fragment {
+					_, found = s.Lookup("out", name)
+				}
+			case BlockLookup:
+				for _, d := range s.Decls {
+					if d.Storage == "uniform" && d.Block == name {
+						found = true
+					}
+				}
+			}
+			if found {
+				break
+			}
+		}
+		switch {
+		case !found:
+			problems = append(problems, Problem{
+				Pos:      l.Pos,
+				Severity: Error,
+				Message:  fmt.Sprintf("%s %s is not declared by the shaders", l.Kind, l.Name),
+			})
+		case block != "":
+			problems = append(problems, Problem{
+				Pos:      l.Pos,
+				Severity: Warning,
+				Message:  fmt.Sprintf("uniform %s is a member of block %s and has no location", l.Name, block),
+			})
+		}
+	}
+	return problems
+}
+
+func builtin(name string) bool {
+	return strings.HasPrefix(name, "gl_")
+}
@@ -0,0 +1,159 @@
+package glsl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustParse(t *testing.T, stage Stage, src string) *Shader {
+	t.Helper()
+	s, err := Parse(stage, src)
+	if err != nil {
+		t.Fatalf("Parse %s: %v", stage, err)
+	}
+	return s
+}
+
+func problemStrings(problems []Problem) []string {
+	var ss []string
+	for _, p := range problems {
+		ss = append(ss, p.String())
+	}
+	return ss
+}
+
+func TestParseDecls(t *testing.T) {
+	s := mustParse(t, Vertex, `#version 410 core
+layout(location = 2) in vec3 position;
+attribute vec2 uv;
+varying vec2 fragUV;
+out vec3 normals[2];
+uniform mat4 bones[4], model;
+layout(std140) uniform Frame {
+	mat4 projection;
+	vec3 lightPos;
+} frame;
+float helper(float x) { return x * 2.0; }
+void main() { fragUV = uv; }
+`)
+	want := []string{
+		"in vec3 position",
+		"in vec2 uv",
+		"out vec2 fragUV",
+		"out vec3 normals[2]",
+		"uniform mat4 bones[4]",
+		"uniform mat4 model",
+		"uniform mat4 projection",
+		"uniform vec3 lightPos",
+	}
+	var got []string
+	for _, d := range s.Decls {
+		got = append(got, d.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decls:\n%q\nwant:\n%q", got, want)
+	}
+	if d, _ := s.Lookup("in", "position"); d.Location != 2 || d.Line != 2 {
+		t.Errorf("position at location %d line %d, want 2 and 2", d.Location, d.Line)
+	}
+	if d, _ := s.Lookup("uniform", "lightPos"); d.Block != "Frame" || d.Line != 9 {
+		t.Errorf("lightPos in block %q at line %d, want Frame and 9", d.Block, d.Line)
+	}
+	if s.Uses["uv"] != 1 || s.Uses["model"] != 0 {
+		t.Errorf("Uses[uv] = %d, Uses[model] = %d, want 1 and 0", s.Uses["uv"], s.Uses["model"])
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages map[Stage]string
+		want   []string
+	}{
+		{
+			name: "matching",
+			stages: map[Stage]string{
+				Vertex:   "out vec2 uv;\nuniform mat4 mvp;\nvoid main() { uv = vec2(0); gl_Position = mvp[0]; }",
+				Fragment: "in vec2 uv;\nout vec4 color;\nvoid main() { color = vec4(uv, 0, 1); }",
+			},
+		},
+		{
+			name: "unread output and unwritten input",
+			stages: map[Stage]string{
+				Vertex:   "out vec2 uv;\nout vec3 extra;\nvoid main() {}",
+				Fragment: "in vec2 uv;\nin vec3 normal;\nvoid main() {}",
+			},
+			want: []string{
+				"0:2: error: fragment input normal is not written by the vertex shader",
+				"0:2: warning: vertex output extra is not read by the fragment shader",
+			},
+		},
+		{
+			name: "type mismatch",
+			stages: map[Stage]string{
+				Vertex:   "out vec3 uv;\nvoid main() {}",
+				Fragment: "in vec2 uv;\nvoid main() {}",
+			},
+			want: []string{"0:1: error: fragment input uv is vec2 but the vertex shader writes vec3 (0:1)"},
+		},
+		{
+			name: "geometry inputs are arrays",
+			stages: map[Stage]string{
+				Vertex:   "out vec3 normal;\nvoid main() {}",
+				Geometry: "in vec3 normal[];\nout vec4 color;\nvoid main() {}",
+				Fragment: "in vec4 color;\nvoid main() {}",
+			},
+		},
+		{
+			name: "uniforms",
+			stages: map[Stage]string{
+				Vertex: "uniform mat4 model;\nuniform float unused;\nvoid main() { model; }",
+				// Continued lines must not shift the reported line.
+				Fragment: "#define TINT \\\n  vec4(1)\nuniform mat3 model;\nvoid main() {}",
+			},
+			want: []string{
+				"0:3: error: uniform model is mat3 in the fragment shader but mat4 in the vertex shader (0:1)",
+				"0:2: warning: uniform unused is declared but never used",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stages []*Shader
+			// Pass the stages out of order; Lint sorts them.
+			for _, stage := range []Stage{Fragment, Geometry, Vertex} {
+				if src, ok := tt.stages[stage]; ok {
+					stages = append(stages, mustParse(t, stage, src))
+				}
+			}
+			if got := problemStrings(Lint(stages...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLookups(t *testing.T) {
+	vertex := mustParse(t, Vertex, "in vec3 position;\nuniform Frame { mat4 camera; };\nstruct Light { vec3 color; };\nuniform Light lights[4];\nvoid main() {}")
+	fragment := mustParse(t, Fragment, "out vec4 color;\nuniform sampler2D tex;\nvoid main() {}")
+	lookups := []Lookup{
+		{UniformLookup, "tex", "a.go:1"},
+		{UniformLookup, "lights[2].color", "a.go:2"},
+		{UniformLookup, "camera", "a.go:3"},
+		{UniformLookup, "texture", "a.go:4"},
+		{AttribLookup, "position", "a.go:5"},
+		{AttribLookup, "color", "a.go:6"},
+		{FragDataLookup, "color", "a.go:7"},
+		{BlockLookup, "Frame", "a.go:8"},
+		{BlockLookup, "Light", "a.go:9"},
+	}
+	want := []string{
+		"a.go:3: warning: uniform camera is a member of block Frame and has no location",
+		"a.go:4: error: uniform texture is not declared by the shaders",
+		"a.go:6: error: attribute color is not declared by the shaders",
+		"a.go:9: error: uniform block Light is not declared by the shaders",
+	}
+	if got := problemStrings(CheckLookups(lookups, vertex, fragment)); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckLookups:\n%q\nwant:\n%q", got, want)
+	}
+}
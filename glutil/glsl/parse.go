@@ -0,0 +1,452 @@
+package glsl
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Stage is a shader stage, in pipeline order.
+type Stage int
+
+const (
+	Vertex Stage = iota
+	TessControl
+	TessEvaluation
+	Geometry
+	Fragment
+	Compute
+)
+
+var stageNames = [...]string{"vertex", "tess control", "tess evaluation", "geometry", "fragment", "compute"}
+
+func (s Stage) String() string {
+	if s < 0 || int(s) >= len(stageNames) {
+		return fmt.Sprintf("Stage(%d)", int(s))
+	}
+	return stageNames[s]
+}
+
+// StageOf guesses the stage from a file extension: .vert, .tesc, .tese,
+// .geom, .frag or .comp.
+func StageOf(name string) (Stage, bool) {
+	switch path.Ext(name) {
+	case ".vert":
+		return Vertex, true
+	case ".tesc":
+		return TessControl, true
+	case ".tese":
+		return TessEvaluation, true
+	case ".geom":
+		return Geometry, true
+	case ".frag":
+		return Fragment, true
+	case ".comp":
+		return Compute, true
+	}
+	return 0, false
+}
+
+// Decl is a global in, out, uniform or buffer variable, or a member of an
+// interface block.
+type Decl struct {
+	Storage  string // "in", "out", "uniform" or "buffer"
+	Type     string
+	Name     string
+	Array    string // the array suffix, such as "[4]" or "[]", if any
+	Location int    // -1 without layout(location)
+	Block    string // the interface block the variable is a member of
+	File     int    // source string number from #line
+	Line     int
+}
+
+func (d Decl) String() string {
+	return fmt.Sprintf("%s %s %s%s", d.Storage, d.Type, d.Name, d.Array)
+}
+
+// Shader is what Parse finds in one stage.
+type Shader struct {
+	Stage Stage
+	// Files names the source string numbers of #line directives, as in
+	// glutil.ShaderSource.Files. It is only used to report positions.
+	Files []string
+	Decls []Decl
+	// Uses counts the identifiers outside the names being declared, so a
+	// variable is unused when its count is zero.
+	Uses map[string]int
+}
+
+// Pos returns "file:line" for d, using s.Files when it names d.File.
+func (s *Shader) Pos(d Decl) string {
+	return s.pos(d.File, d.Line)
+}
+
+func (s *Shader) pos(file, line int) string {
+	if file >= 0 && file < len(s.Files) {
+		return fmt.Sprintf("%s:%d", s.Files[file], line)
+	}
+	return fmt.Sprintf("%d:%d", file, line)
+}
+
+// Lookup returns the declaration of name with the given storage, or false.
+func (s *Shader) Lookup(storage, name string) (Decl, bool) {
+	for _, d := range s.Decls {
+		if d.Storage == storage && d.Name == name {
+			return d, true
+		}
+	}
+	return Decl{}, false
+}
+
+// Parse lexes src and collects the global declarations of a shader of the
+// given stage. Function bodies are only scanned for identifier uses.
+// attribute and varying are reported as in and out.
+func Parse(stage Stage, src string) (*Shader, error) {
+	toks, err := Lex(src)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTokens(stage, toks)
+}
+
+// ParseTokens is Parse for already lexed source.
+func ParseTokens(stage Stage, toks []Token) (*Shader, error) {
+	p := &parser{stage: stage, toks: toks, declared: map[int]bool{}}
+	s := &Shader{Stage: stage, Uses: map[string]int{}}
+	for p.pos < len(toks) {
+		decls, err := p.external()
+		if err != nil {
+			return nil, err
+		}
+		s.Decls = append(s.Decls, decls...)
+	}
+	for i, t := range toks {
+		if t.Kind == Ident && !p.declared[i] {
+			s.Uses[t.Text]++
+		}
+	}
+	return s, nil
+}
+
+// qualifiers that may come before the type of a global variable.
+var qualifiers = map[string]bool{
+	"const": true, "in": true, "out": true, "inout": true, "attribute": true, "varying": true,
+	"uniform": true, "buffer": true, "shared": true,
+	"centroid": true, "sample": true, "patch": true, "flat": true, "smooth": true, "noperspective": true,
+	"invariant": true, "precise": true, "highp": true, "mediump": true, "lowp": true,
+	"coherent": true, "volatile": true, "restrict": true, "readonly": true, "writeonly": true,
+}
+
+type parser struct {
+	stage    Stage
+	toks     []Token
+	pos      int
+	declared map[int]bool // token indices of declared names
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos].Text
+	}
+	return ""
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.toks[len(p.toks)-1]
+	if p.pos < len(p.toks) {
+		t = p.toks[p.pos]
+	}
+	return fmt.Errorf("%d:%d: %s", t.File, t.Line, fmt.Sprintf(format, args...))
+}
+
+// skipBalanced skips from an opening bracket to just after its match.
+func (p *parser) skipBalanced() error {
+	open := p.peek()
+	close := map[string]string{"{": "}", "(": ")", "[": "]"}[open]
+	depth := 0
+	for ; p.pos < len(p.toks); p.pos++ {
+		switch p.toks[p.pos].Text {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		}
+	}
+	return p.errorf("missing %s", close)
+}
+
+// skipStatement skips to just after the next ; outside brackets.
+func (p *parser) skipStatement() error {
+	for p.pos < len(p.toks) {
+		switch p.peek() {
+		case ";":
+			p.pos++
+			return nil
+		case "{", "(", "[":
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+		default:
+			p.pos++
+		}
+	}
+	return p.errorf("missing ;")
+}
+
+// external parses one top-level declaration or function definition.
+func (p *parser) external() ([]Decl, error) {
+	switch p.peek() {
+	case ";":
+		p.pos++
+		return nil, nil
+	case "precision":
+		return nil, p.skipStatement()
+	case "struct":
+		return nil, p.skipStatement()
+	}
+
+	location := -1
+	var storage string
+	for p.pos < len(p.toks) {
+		t := p.peek()
+		if t == "layout" {
+			p.pos++
+			loc, err := p.layout()
+			if err != nil {
+				return nil, err
+			}
+			if loc >= 0 {
+				location = loc
+			}
+			continue
+		}
+		if !qualifiers[t] {
+			break
+		}
+		switch t {
+		case "in", "out", "uniform", "buffer", "const", "shared":
+			storage = t
+		case "attribute":
+			storage = "in"
+		case "varying":
+			storage = "out"
+			if p.stage == Fragment {
+				storage = "in"
+			}
+		}
+		p.pos++
+	}
+
+	// layout(...) in; and invariant gl_Position; declare no variables.
+	if p.peek() == ";" || p.pos+1 < len(p.toks) && p.toks[p.pos+1].Text == ";" {
+		return nil, p.skipStatement()
+	}
+	if p.pos >= len(p.toks) {
+		return nil, p.errorf("unexpected end of source")
+	}
+
+	if p.peek() == "struct" {
+		return nil, p.skipStatement()
+	}
+	typ := p.toks[p.pos]
+	if typ.Kind != Ident {
+		return nil, p.errorf("unexpected %q", typ.Text)
+	}
+	p.pos++
+
+	// An interface block: storage Name { members } [instance [array]];
+	if p.peek() == "{" && (storage == "in" || storage == "out" || storage == "uniform" || storage == "buffer") {
+		return p.block(storage, typ.Text)
+	}
+
+	typeArray := ""
+	if p.peek() == "[" {
+		typeArray = p.array()
+	}
+
+	var decls []Decl
+	for {
+		if p.pos >= len(p.toks) || p.toks[p.pos].Kind != Ident {
+			return nil, p.errorf("expected a name after %s", typ.Text)
+		}
+		name := p.toks[p.pos]
+		p.pos++
+		if p.peek() == "(" {
+			// A function prototype or definition.
+			if err := p.skipBalanced(); err != nil {
+				return nil, err
+			}
+			if p.peek() == "{" {
+				return nil, p.skipBalanced()
+			}
+			return nil, p.skipStatement()
+		}
+		p.declared[p.pos-1] = true
+		array := typeArray
+		if p.peek() == "[" {
+			array += p.array()
+		}
+		if storage == "in" || storage == "out" || storage == "uniform" || storage == "buffer" {
+			decls = append(decls, Decl{
+				Storage:  storage,
+				Type:     typ.Text,
+				Name:     name.Text,
+				Array:    array,
+				Location: location,
+				File:     name.File,
+				Line:     name.Line,
+			})
+		}
+		if p.peek() == "=" {
+			if err := p.initializer(); err != nil {
+				return nil, err
+			}
+		}
+		switch p.peek() {
+		case ",":
+			p.pos++
+			continue
+		case ";":
+			p.pos++
+			return decls, nil
+		}
+		return nil, p.errorf("unexpected %q in declaration of %s", p.peek(), name.Text)
+	}
+}
+
+// initializer skips = and the expression after it, up to , or ;.
+func (p *parser) initializer() error {
+	p.pos++
+	for p.pos < len(p.toks) {
+		switch p.peek() {
+		case ",", ";":
+			return nil
+		case "{", "(", "[":
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+		default:
+			p.pos++
+		}
+	}
+	return p.errorf("missing ;")
+}
+
+// array reads an array suffix such as [4] or [] and returns its text.
+func (p *parser) array() string {
+	start := p.pos
+	if err := p.skipBalanced(); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, t := range p.toks[start:p.pos] {
+		b.WriteString(t.Text)
+	}
+	for p.peek() == "[" {
+		b.WriteString(p.array())
+	}
+	return b.String()
+}
+
+// layout reads the arguments of layout(...) and returns the location, or
+// -1 if there is none.
+func (p *parser) layout() (int, error) {
+	if p.peek() != "(" {
+		return -1, p.errorf("expected ( after layout")
+	}
+	start := p.pos
+	if err := p.skipBalanced(); err != nil {
+		return -1, err
+	}
+	args := p.toks[start+1 : p.pos-1]
+	for i, t := range args {
+		if t.Text == "location" && i+2 < len(args) && args[i+1].Text == "=" {
+			n, err := strconv.ParseInt(args[i+2].Text, 0, 32)
+			if err != nil {
+				return -1, fmt.Errorf("%d:%d: location %s: %v", t.File, t.Line, args[i+2].Text, err)
+			}
+			return int(n), nil
+		}
+	}
+	return -1, nil
+}
+
+// block parses the members of an interface block whose name was just
+// read.
+func (p *parser) block(storage, name string) ([]Decl, error) {
+	p.pos++ // {
+	var decls []Decl
+	for p.peek() != "}" {
+		if p.pos >= len(p.toks) {
+			return nil, p.errorf("missing } after block %s", name)
+		}
+		location := -1
+		for qualifiers[p.peek()] || p.peek() == "layout" {
+			if p.peek() == "layout" {
+				p.pos++
+				loc, err := p.layout()
+				if err != nil {
+					return nil, err
+				}
+				location = loc
+				continue
+			}
+			p.pos++
+		}
+		typ := p.toks[p.pos]
+		p.pos++
+		typeArray := ""
+		if p.peek() == "[" {
+			typeArray = p.array()
+		}
+		for {
+			if p.pos >= len(p.toks) || p.toks[p.pos].Kind != Ident {
+				return nil, p.errorf("expected a member name in block %s", name)
+			}
+			member := p.toks[p.pos]
+			p.declared[p.pos] = true
+			p.pos++
+			array := typeArray
+			if p.peek() == "[" {
+				array += p.array()
+			}
+			decls = append(decls, Decl{
+				Storage:  storage,
+				Type:     typ.Text,
+				Name:     member.Text,
+				Array:    array,
+				Location: location,
+				Block:    name,
+				File:     member.File,
+				Line:     member.Line,
+			})
+			if p.peek() == "," {
+				p.pos++
+				continue
+			}
+			break
+		}
+		if p.peek() != ";" {
+			return nil, p.errorf("expected ; in block %s", name)
+		}
+		p.pos++
+	}
+	p.pos++ // }
+	if p.pos < len(p.toks) && p.toks[p.pos].Kind == Ident {
+		p.declared[p.pos] = true
+		p.pos++
+		if p.peek() == "[" {
+			p.array()
+		}
+	}
+	if p.peek() != ";" {
+		return nil, p.errorf("expected ; after block %s", name)
+	}
+	p.pos++
+	return decls, nil
+}
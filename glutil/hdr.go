@@ -0,0 +1,173 @@
+package glutil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strings"
+)
+
+const hdrMaxImageLength = 1 << 28
+
+// DecodeHDR decodes a Radiance RGBE (.hdr) image into an RGBA32F with
+// alpha 1. Both flat and run-length encoded scanlines are read; the XYZE
+// colour format is not.
+func DecodeHDR(r io.Reader) (image.Image, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	magic, err := br.ReadString('\n')
+	if err != nil || !(strings.HasPrefix(magic, "#?RADIANCE") || strings.HasPrefix(magic, "#?RGBE")) {
+		return nil, errors.New("hdr: not a Radiance file")
+	}
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("hdr: reading header: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if format := strings.TrimPrefix(line, "FORMAT="); format != line && format != "32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("hdr: unsupported format %s", format)
+		}
+	}
+
+	resolution, err := br.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("hdr: reading resolution: %v", err)
+	}
+	var yAxis, xAxis string
+	var width, height int
+	if _, err := fmt.Sscanf(resolution, "%s %d %s %d", &yAxis, &height, &xAxis, &width); err != nil {
+		return nil, fmt.Errorf("hdr: bad resolution %q", strings.TrimSpace(resolution))
+	}
+	if (yAxis != "-Y" && yAxis != "+Y") || xAxis != "+X" {
+		return nil, fmt.Errorf("hdr: unsupported orientation %q", strings.TrimSpace(resolution))
+	}
+	if width <= 0 || height <= 0 || width*height > hdrMaxImageLength {
+		return nil, fmt.Errorf("hdr: bad size %dx%d", width, height)
+	}
+
+	img := NewRGBA32F(image.Rect(0, 0, width, height))
+	scanline := make([]byte, 4*width)
+	for row := 0; row < height; row++ {
+		if err := hdrReadScanline(br, scanline); err != nil {
+			return nil, fmt.Errorf("hdr: scanline %d: %v", row, err)
+		}
+		// -Y means the first scanline is the top one.
+		y := row
+		if yAxis == "+Y" {
+			y = height - 1 - row
+		}
+		pix := img.Pix[img.PixOffset(0, y):]
+		for x := 0; x < width; x++ {
+			rgbe := scanline[4*x : 4*x+4]
+			pix[4*x+3] = 1
+			if rgbe[3] == 0 {
+				continue
+			}
+			f := float32(math.Ldexp(1, int(rgbe[3])-(128+8)))
+			pix[4*x] = float32(rgbe[0]) * f
+			pix[4*x+1] = float32(rgbe[1]) * f
+			pix[4*x+2] = float32(rgbe[2]) * f
+		}
+	}
+	return img, nil
+}
+
+// hdrReadScanline reads one scanline of RGBE pixels into dst. New-style
+// run-length scanlines start with 2, 2 and the width, then hold each
+// channel separately as runs and literals.
+func hdrReadScanline(br *bufio.Reader, dst []byte) error {
+	width := len(dst) / 4
+	var head [4]byte
+	if _, err := io.ReadFull(br, head[:]); err != nil {
+		return err
+	}
+	if width < 8 || width > 0x7fff || head[0] != 2 || head[1] != 2 || head[2]&0x80 != 0 {
+		return hdrReadFlat(br, dst, head)
+	}
+	if int(head[2])<<8|int(head[3]) != width {
+		return errors.New("scanline width mismatch")
+	}
+
+	for c := 0; c < 4; c++ {
+		for x := 0; x < width; {
+			count, err := br.ReadByte()
+			if err != nil {
+				return err
+			}
+			if count > 128 {
+				n := int(count) - 128
+				if x+n > width {
+					return errors.New("run overflows the scanline")
+				}
+				v, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				for ; n > 0; n-- {
+					dst[4*x+c] = v
+					x++
+				}
+			} else {
+				n := int(count)
+				if n == 0 || x+n > width {
+					return errors.New("bad literal length")
+				}
+				for ; n > 0; n-- {
+					v, err := br.ReadByte()
+					if err != nil {
+						return err
+					}
+					dst[4*x+c] = v
+					x++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hdrReadFlat reads a scanline of plain RGBE pixels, the first of which is
+// already in first. An old-style run is a pixel of 1, 1, 1 and a count
+// that repeats the previous pixel, shifted 8 bits more for each run in a
+// row.
+func hdrReadFlat(br *bufio.Reader, dst []byte, first [4]byte) error {
+	width := len(dst) / 4
+	px := first
+	shift := uint(0)
+	for x := 0; x < width; {
+		if px[0] == 1 && px[1] == 1 && px[2] == 1 {
+			if x == 0 {
+				return errors.New("run without a previous pixel")
+			}
+			n := int(px[3]) << shift
+			if x+n > width {
+				return errors.New("run overflows the scanline")
+			}
+			for ; n > 0; n-- {
+				copy(dst[4*x:], dst[4*x-4:4*x])
+				x++
+			}
+			shift += 8
+		} else {
+			copy(dst[4*x:], px[:])
+			x++
+			shift = 0
+		}
+		if x < width {
+			if _, err := io.ReadFull(br, px[:]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package glutil
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// ReloadInterval is how often a ReloadableProgram checks its files.
+var ReloadInterval = 500 * time.Millisecond
+
+// ReloadableProgram is a program loaded through a ShaderLoader that is
+// rebuilt when one of its files, includes included, changes. Poll it once
+// per frame from the render thread.
+//
+// After a rebuild the values of uniforms with the same name and type are
+// copied from the old program. If the new sources fail to compile or link
+// the error is logged and the last good program stays in use.
+type ReloadableProgram struct {
+	loader                   *ShaderLoader
+	vertexFile, fragmentFile string
+
+	program   *Program
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// NewReloadableProgram builds the program once. Unlike later reloads, a
+// failure here is returned.
+func (l *ShaderLoader) NewReloadableProgram(vertexFile, fragmentFile string) (*ReloadableProgram, error) {
+	p := &ReloadableProgram{
+		loader:       l,
+		vertexFile:   vertexFile,
+		fragmentFile: fragmentFile,
+	}
+	id, err := p.build()
+	if err != nil {
+		return nil, err
+	}
+	p.program = Reflect(id)
+	p.lastCheck = time.Now()
+	return p, nil
+}
+
+// ID returns the current program object.
+func (p *ReloadableProgram) ID() uint32 {
+	return p.program.ID
+}
+
+// Program returns the current program. The same *Program is updated in
+// place on every reload, so it can be kept and its setters keep working.
+func (p *ReloadableProgram) Program() *Program {
+	return p.program
+}
+
+// Delete deletes the current program.
+func (p *ReloadableProgram) Delete() {
+	p.program.Delete()
+	p.program.ID = 0
+}
+
+// Poll rebuilds the program if any of its files changed since the last
+// check, at most once every ReloadInterval. It reports whether the program
+// object changed, in which case locations looked up by hand rather than
+// through Program must be looked up again.
+func (p *ReloadableProgram) Poll() bool {
+	if time.Since(p.lastCheck) < ReloadInterval {
+		return false
+	}
+	p.lastCheck = time.Now()
+
+	if !p.changed() {
+		return false
+	}
+	old := p.program.ID
+	id, err := p.build()
+	if err != nil {
+		log.Printf("shader reload %s + %s: %v", p.vertexFile, p.fragmentFile, err)
+		return false
+	}
+
+	copyUniforms(old, id)
+	gl.DeleteProgram(old)
+	p.program.reflect(id)
+	log.Printf("reloaded %s + %s", p.vertexFile, p.fragmentFile)
+	return true
+}
+
+// build loads and links the program and records the modification times
+// of the files it read. The times are recorded even when the build fails
+// so a broken file is not retried until it changes again.
+func (p *ReloadableProgram) build() (uint32, error) {
+	vertex, verr := p.loader.LoadStage(gl.VERTEX_SHADER, p.vertexFile)
+	fragment, ferr := p.loader.LoadStage(gl.FRAGMENT_SHADER, p.fragmentFile)
+
+	p.modTimes = map[string]time.Time{}
+	for _, src := range []*ShaderSource{vertex, fragment} {
+		if src == nil {
+			continue
+		}
+		for _, f := range src.Files {
+			p.modTimes[f.Name] = modTime(p.loader.FS, f.Name)
+		}
+	}
+	for _, name := range []string{p.vertexFile, p.fragmentFile} {
+		if _, ok := p.modTimes[name]; !ok {
+			p.modTimes[name] = modTime(p.loader.FS, name)
+		}
+	}
+	if verr != nil {
+		return 0, verr
+	}
+	if ferr != nil {
+		return 0, ferr
+	}
+
+	return new(ProgramBuilder).
+		Stage(gl.VERTEX_SHADER, p.vertexFile, vertex).
+		Stage(gl.FRAGMENT_SHADER, p.fragmentFile, fragment).
+		Link()
+}
+
+func (p *ReloadableProgram) changed() bool {
+	for name, t := range p.modTimes {
+		if !modTime(p.loader.FS, name).Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// modTime returns the modification time of name, or the zero time if it
+// cannot be read. Embedded files always report the zero time, so
+// programs loaded from an embed.FS never reload.
+func modTime(fsys fs.FS, name string) time.Time {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// copyUniforms copies the value of every default-block uniform of from to
+// the uniform of the same name and type in to, and the binding point of
+// every uniform block.
+func copyUniforms(from, to uint32) {
+	for _, old := range activeBlocks(from) {
+		for _, b := range activeBlocks(to) {
+			if b.Name == old.Name {
+				gl.UniformBlockBinding(to, b.Index, old.Binding)
+			}
+		}
+	}
+
+	dst := map[string]uint32{}
+	for _, u := range activeUniforms(to) {
+		dst[u.Name] = u.Type
+	}
+
+	for _, u := range activeUniforms(from) {
+		t, ok := glslTypes[u.Type]
+		if !ok || dst[u.Name] != u.Type {
+			continue
+		}
+		base := strings.TrimSuffix(u.Name, "[0]")
+		for i := 0; i < int(u.Size); i++ {
+			name := base
+			if u.Size > 1 {
+				name = fmt.Sprintf("%s[%d]", base, i)
+			}
+			src := gl.GetUniformLocation(from, gl.Str(name+"\x00"))
+			loc := gl.GetUniformLocation(to, gl.Str(name+"\x00"))
+			if src < 0 || loc < 0 {
+				continue
+			}
+			copyUniform(from, src, to, loc, t)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package glutil
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// ImageDecoder decodes one image file format.
+type ImageDecoder func(r io.Reader) (image.Image, error)
+
+var imageDecoders = map[string]ImageDecoder{}
+
+// RegisterImageDecoder makes DecodeImage use decode for files with the
+// given extension, such as ".tga". It replaces an earlier decoder for the
+// same extension.
+func RegisterImageDecoder(ext string, decode ImageDecoder) {
+	imageDecoders[strings.ToLower(ext)] = decode
+}
+
+func init() {
+	RegisterImageDecoder(".tga", DecodeTGA)
+	RegisterImageDecoder(".bmp", DecodeBMP)
+	RegisterImageDecoder(".hdr", DecodeHDR)
+	RegisterImageDecoder(".pic", DecodeHDR)
+	// BMP and Radiance files start with a signature, so image.Decode can
+	// find them too. TGA has none.
+	image.RegisterFormat("bmp", "BM", DecodeBMP, decodeConfig(DecodeBMP))
+	image.RegisterFormat("hdr", "#?RADIANCE", DecodeHDR, decodeConfig(DecodeHDR))
+	image.RegisterFormat("hdr", "#?RGBE", DecodeHDR, decodeConfig(DecodeHDR))
+}
+
+func decodeConfig(decode ImageDecoder) func(io.Reader) (image.Config, error) {
+	return func(r io.Reader) (image.Config, error) {
+		img, err := decode(r)
+		if err != nil {
+			return image.Config{}, err
+		}
+		b := img.Bounds()
+		return image.Config{ColorModel: img.ColorModel(), Width: b.Dx(), Height: b.Dy()}, nil
+	}
+}
+
+// DecodeImage decodes r with the decoder registered for the extension of
+// name, or with image.Decode, which knows PNG, JPEG, BMP and Radiance HDR
+// by their signatures.
+func DecodeImage(name string, r io.Reader) (image.Image, error) {
+	if decode, ok := imageDecoders[strings.ToLower(filepath.Ext(name))]; ok {
+		return decode(bufio.NewReader(r))
+	}
+	img, _, err := image.Decode(r)
+	if err == image.ErrFormat {
+		var exts []string
+		for ext := range imageDecoders {
+			exts = append(exts, ext)
+		}
+		exts = append(exts, ".jpg", ".png")
+		sort.Strings(exts)
+		return nil, fmt.Errorf("%s: unknown image format (known: %s)", name, strings.Join(exts, " "))
+	}
+	return img, err
+}
+
+// RGBA32F is an image of float32 red, green, blue and alpha values, such
+// as a decoded Radiance HDR file. Values may exceed 1.
+type RGBA32F struct {
+	Pix    []float32
+	Stride int // in float32s
+	Rect   image.Rectangle
+}
+
+// NewRGBA32F returns a transparent black image with the given bounds.
+func NewRGBA32F(r image.Rectangle) *RGBA32F {
+	return &RGBA32F{Pix: make([]float32, 4*r.Dx()*r.Dy()), Stride: 4 * r.Dx(), Rect: r}
+}
+
+func (p *RGBA32F) ColorModel() color.Model { return color.RGBA64Model }
+
+func (p *RGBA32F) Bounds() image.Rectangle { return p.Rect }
+
+// At clamps the pixel at x, y to [0, 1].
+func (p *RGBA32F) At(x, y int) color.Color {
+	v := p.RGBA32FAt(x, y)
+	c := func(f float32) uint16 {
+		return uint16(math.Round(float64(clamp01(f)) * 0xffff))
+	}
+	a := clamp01(v[3])
+	return color.RGBA64{c(v[0] * a), c(v[1] * a), c(v[2] * a), c(a)}
+}
+
+// RGBA32FAt returns the unclamped, non-premultiplied value at x, y.
+func (p *RGBA32F) RGBA32FAt(x, y int) [4]float32 {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return [4]float32{}
+	}
+	i := p.PixOffset(x, y)
+	return [4]float32{p.Pix[i], p.Pix[i+1], p.Pix[i+2], p.Pix[i+3]}
+}
+
+// SetRGBA32F sets the pixel at x, y.
+func (p *RGBA32F) SetRGBA32F(x, y int, v [4]float32) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	copy(p.Pix[p.PixOffset(x, y):], v[:])
+}
+
+// PixOffset returns the index of the first element of Pix for x, y.
+func (p *RGBA32F) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*4
+}
+
+func clamp01(f float32) float32 {
+	if f < 0 {
+		return 0
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// texels is image data laid out for glTexImage2D: RGBA rows, first row
+// first, in the component type xtype.
+type texels struct {
+	pix           []byte
+	stride        int
+	width, height int
+	xtype         uint32 // gl.UNSIGNED_BYTE, gl.UNSIGNED_SHORT or gl.FLOAT
+}
+
+// newTexels converts img to RGBA texels. 8-bit images become
+// UNSIGNED_BYTE, 16-bit images UNSIGNED_SHORT so they keep their
+// precision, and RGBA32F images FLOAT.
+func newTexels(img image.Image) texels {
+	b := img.Bounds()
+	t := texels{width: b.Dx(), height: b.Dy()}
+	switch img := img.(type) {
+	case *RGBA32F:
+		t.xtype = gl.FLOAT
+		t.stride = 16 * t.width
+		t.pix = make([]byte, t.stride*t.height)
+		for y := 0; y < t.height; y++ {
+			row := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+			for i := 0; i < 4*t.width; i++ {
+				binary.LittleEndian.PutUint32(t.pix[y*t.stride+4*i:], math.Float32bits(row[i]))
+			}
+		}
+	case *image.RGBA64, *image.NRGBA64, *image.Gray16:
+		rgba := image.NewRGBA64(b)
+		draw.Draw(rgba, b, img, b.Min, draw.Src)
+		// image.RGBA64 is big-endian; GL reads shorts in host order.
+		t.xtype = gl.UNSIGNED_SHORT
+		t.stride = 8 * t.width
+		t.pix = make([]byte, t.stride*t.height)
+		for y := 0; y < t.height; y++ {
+			row := rgba.Pix[y*rgba.Stride:]
+			for i := 0; i < 4*t.width; i++ {
+				binary.LittleEndian.PutUint16(t.pix[y*t.stride+2*i:], binary.BigEndian.Uint16(row[2*i:]))
+			}
+		}
+	default:
+		rgba := image.NewRGBA(image.Rect(0, 0, t.width, t.height))
+		draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+		t.xtype = gl.UNSIGNED_BYTE
+		t.stride = rgba.Stride
+		t.pix = rgba.Pix
+	}
+	return t
+}
+
+// upload specifies a level of the texture bound to target.
+func (t texels) upload(target uint32, level, internalFormat int32) {
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage2D(
+		target,
+		level,
+		internalFormat,
+		int32(t.width),
+		int32(t.height),
+		0,
+		gl.RGBA,
+		t.xtype,
+		gl.Ptr(t.pix))
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+}
@@ -0,0 +1,202 @@
+package glutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+var (
+	ktx1Identifier = []byte{0xAB, 'K', 'T', 'X', ' ', '1', '1', 0xBB, '\r', '\n', 0x1A, '\n'}
+	ktx2Identifier = []byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+)
+
+// ktx2Formats maps the Vulkan formats of KTX2 files to GL internal
+// formats.
+var ktx2Formats = map[uint32]uint32{
+	37:  gl.RGBA8,
+	43:  gl.SRGB8_ALPHA8,
+	97:  gl.RGBA16F,
+	109: gl.RGBA32F,
+	131: gl.COMPRESSED_RGB_S3TC_DXT1_EXT,
+	132: compressedSRGBS3TCDXT1,
+	133: gl.COMPRESSED_RGBA_S3TC_DXT1_EXT,
+	134: compressedSRGBAlphaS3TCDXT1,
+	135: gl.COMPRESSED_RGBA_S3TC_DXT3_EXT,
+	136: compressedSRGBAlphaS3TCDXT3,
+	137: gl.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+	138: compressedSRGBAlphaS3TCDXT5,
+	139: gl.COMPRESSED_RED_RGTC1,
+	140: gl.COMPRESSED_SIGNED_RED_RGTC1,
+	141: gl.COMPRESSED_RG_RGTC2,
+	142: gl.COMPRESSED_SIGNED_RG_RGTC2,
+	143: gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB,
+	144: gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB,
+	145: gl.COMPRESSED_RGBA_BPTC_UNORM_ARB,
+	146: gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM_ARB,
+	147: gl.COMPRESSED_RGB8_ETC2,
+	148: gl.COMPRESSED_SRGB8_ETC2,
+	149: gl.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2,
+	150: gl.COMPRESSED_SRGB8_PUNCHTHROUGH_ALPHA1_ETC2,
+	151: gl.COMPRESSED_RGBA8_ETC2_EAC,
+	152: gl.COMPRESSED_SRGB8_ALPHA8_ETC2_EAC,
+	153: gl.COMPRESSED_R11_EAC,
+	154: gl.COMPRESSED_SIGNED_R11_EAC,
+	155: gl.COMPRESSED_RG11_EAC,
+	156: gl.COMPRESSED_SIGNED_RG11_EAC,
+}
+
+// ReadKTX reads a KTX or KTX2 file holding a 2D texture or cube map in one
+// of the formats of TexelFormat. Arrays, 3D textures and supercompressed
+// KTX2 files are not supported.
+func ReadKTX(r io.Reader) (*TextureFile, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("ktx: %v", err)
+	}
+	var f *TextureFile
+	switch {
+	case bytes.HasPrefix(b, ktx1Identifier):
+		f, err = readKTX1(b)
+	case bytes.HasPrefix(b, ktx2Identifier):
+		f, err = readKTX2(b)
+	default:
+		return nil, errors.New("ktx: not a KTX file")
+	}
+	if err == nil {
+		err = f.check()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ktx: %v", err)
+	}
+	return f, nil
+}
+
+func readKTX1(b []byte) (*TextureFile, error) {
+	const headerLength = 12 + 13*4
+	if len(b) < headerLength {
+		return nil, errors.New("truncated header")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(b[12:]) != 0x04030201 {
+		order = binary.BigEndian
+	}
+	var h [13]uint32
+	for i := range h {
+		h[i] = order.Uint32(b[12+4*i:])
+	}
+	glType, glTypeSize, glInternalFormat := h[1], h[2], h[4]
+	width, height, depth := int(h[6]), int(h[7]), int(h[8])
+	arrayElements, faces, levels, kvBytes := h[9], int(h[10]), int(h[11]), int(h[12])
+	if order == binary.BigEndian && glTypeSize > 1 {
+		return nil, errors.New("big-endian texel data is not supported")
+	}
+	if depth > 1 || arrayElements > 0 || height == 0 {
+		return nil, errors.New("only 2D textures and cube maps are supported")
+	}
+	if !validImageSize(width, height) {
+		return nil, fmt.Errorf("bad size %dx%d", width, height)
+	}
+	if faces != 1 && faces != 6 {
+		return nil, fmt.Errorf("bad face count %d", faces)
+	}
+	if glInternalFormat == gl.RGBA && glType == gl.UNSIGNED_BYTE {
+		glInternalFormat = gl.RGBA8
+	}
+	format, ok := texelFormats[glInternalFormat]
+	if !ok || format.Compressed != (glType == 0) {
+		return nil, fmt.Errorf("unsupported internal format 0x%X", glInternalFormat)
+	}
+	if levels == 0 {
+		// Zero asks for the mipmaps to be generated; there is one level.
+		levels = 1
+	}
+	if levels > 32 {
+		return nil, fmt.Errorf("bad level count %d", levels)
+	}
+
+	f := &TextureFile{Format: format, Width: width, Height: height, Faces: faces}
+	at := headerLength + kvBytes
+	for level := 0; level < levels; level++ {
+		if at+4 > len(b) {
+			return nil, fmt.Errorf("level %d truncated", level)
+		}
+		// imageSize is per face for cube maps, and faces are padded to 4
+		// bytes, which block and RGBA data always are.
+		size := int(order.Uint32(b[at:]))
+		at += 4
+		var data [][]byte
+		for face := 0; face < faces; face++ {
+			if size < 0 || at+size > len(b) {
+				return nil, fmt.Errorf("level %d truncated", level)
+			}
+			data = append(data, b[at:at+size])
+			at += (size + 3) &^ 3
+		}
+		f.Levels = append(f.Levels, data)
+	}
+	return f, nil
+}
+
+func readKTX2(b []byte) (*TextureFile, error) {
+	const headerLength = 12 + 9*4 + 4*4 + 2*8
+	if len(b) < headerLength {
+		return nil, errors.New("truncated header")
+	}
+	le := binary.LittleEndian
+	vkFormat := le.Uint32(b[12:])
+	width, height, depth := int(le.Uint32(b[20:])), int(le.Uint32(b[24:])), int(le.Uint32(b[28:]))
+	layers, faces, levels := le.Uint32(b[32:]), int(le.Uint32(b[36:])), int(le.Uint32(b[40:]))
+	supercompression := le.Uint32(b[44:])
+	if supercompression != 0 {
+		return nil, fmt.Errorf("supercompression scheme %d is not supported", supercompression)
+	}
+	if depth > 1 || layers > 0 || height == 0 {
+		return nil, errors.New("only 2D textures and cube maps are supported")
+	}
+	if !validImageSize(width, height) {
+		return nil, fmt.Errorf("bad size %dx%d", width, height)
+	}
+	if faces != 1 && faces != 6 {
+		return nil, fmt.Errorf("bad face count %d", faces)
+	}
+	internalFormat, ok := ktx2Formats[vkFormat]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Vulkan format %d", vkFormat)
+	}
+	if levels == 0 {
+		levels = 1
+	}
+	if levels > 32 {
+		return nil, fmt.Errorf("bad level count %d", levels)
+	}
+
+	f := &TextureFile{Format: texelFormats[internalFormat], Width: width, Height: height, Faces: faces}
+	index := b[headerLength:]
+	if len(index) < 24*levels {
+		return nil, errors.New("truncated level index")
+	}
+	for level := 0; level < levels; level++ {
+		offset := le.Uint64(index[24*level:])
+		length := le.Uint64(index[24*level+8:])
+		if offset > uint64(len(b)) || length > uint64(len(b))-offset {
+			return nil, fmt.Errorf("level %d truncated", level)
+		}
+		w, h := f.LevelSize(level)
+		size := f.Format.LevelSize(w, h)
+		if size < 0 || int(length) < size*faces {
+			return nil, fmt.Errorf("level %d holds %d bytes, want %d", level, length, size*faces)
+		}
+		var data [][]byte
+		for face := 0; face < faces; face++ {
+			at := int(offset) + face*size
+			data = append(data, b[at:at+size])
+		}
+		f.Levels = append(f.Levels, data)
+	}
+	return f, nil
+}
@@ -0,0 +1,218 @@
+package glutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Layout is a GLSL block memory layout.
+type Layout int
+
+const (
+	// Std140 is the layout of uniform blocks declared layout(std140).
+	Std140 Layout = iota
+	// Std430 is the tighter layout available to shader storage blocks.
+	Std430
+)
+
+func (l Layout) String() string {
+	if l == Std430 {
+		return "std430"
+	}
+	return "std140"
+}
+
+// Go types with a fixed GLSL meaning. Other arrays are GLSL arrays, so use
+// mgl32 types for vectors and matrices.
+var (
+	vec2Type = reflect.TypeOf(mgl32.Vec2{})
+	vec3Type = reflect.TypeOf(mgl32.Vec3{})
+	vec4Type = reflect.TypeOf(mgl32.Vec4{})
+	mat2Type = reflect.TypeOf(mgl32.Mat2{})
+	mat3Type = reflect.TypeOf(mgl32.Mat3{})
+	mat4Type = reflect.TypeOf(mgl32.Mat4{})
+)
+
+// Size returns the number of bytes v occupies in the layout, including
+// trailing padding.
+func (l Layout) Size(v interface{}) (int, error) {
+	_, size, err := l.layout(reflect.Indirect(reflect.ValueOf(v)).Type())
+	return size, err
+}
+
+// Encode converts v, a struct or pointer to a struct, to the bytes a
+// shader sees for a block declared with the same members in layout l.
+//
+// Supported field types are float32, int32, uint32, bool, mgl32.Vec2-4,
+// mgl32.Mat2-4, nested structs and fixed-size arrays of any of these.
+func (l Layout) Encode(v interface{}) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: cannot encode %T, want a struct", l, v)
+	}
+	_, size, err := l.layout(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	l.encode(buf, rv)
+	return buf, nil
+}
+
+// Offsets returns the byte offset of each field of the struct v, keyed by
+// field name, e.g. for comparing with GL_UNIFORM_OFFSET.
+func (l Layout) Offsets(v interface{}) (map[string]int, error) {
+	t := reflect.Indirect(reflect.ValueOf(v)).Type()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: cannot lay out %T, want a struct", l, v)
+	}
+	offsets := map[string]int{}
+	offset := 0
+	for i := 0; i < t.NumField(); i++ {
+		align, size, err := l.layout(t.Field(i).Type)
+		if err != nil {
+			return nil, err
+		}
+		offset = roundUp(offset, align)
+		offsets[t.Field(i).Name] = offset
+		offset += size
+	}
+	return offsets, nil
+}
+
+// layout returns the base alignment and size of t.
+func (l Layout) layout(t reflect.Type) (align, size int, err error) {
+	switch t {
+	case vec2Type:
+		return 8, 8, nil
+	case vec3Type:
+		return 16, 12, nil
+	case vec4Type:
+		return 16, 16, nil
+	case mat2Type:
+		return l.matrix(2, 8)
+	case mat3Type:
+		return l.matrix(3, 12)
+	case mat4Type:
+		return l.matrix(4, 16)
+	}
+
+	switch t.Kind() {
+	case reflect.Float32, reflect.Int32, reflect.Uint32, reflect.Bool:
+		return 4, 4, nil
+
+	case reflect.Array:
+		align, _, err := l.layout(t.Elem())
+		if err != nil {
+			return 0, 0, err
+		}
+		stride, _ := l.stride(t.Elem())
+		if l == Std140 {
+			align = roundUp(align, 16)
+		}
+		return align, stride * t.Len(), nil
+
+	case reflect.Struct:
+		align, offset := 4, 0
+		for i := 0; i < t.NumField(); i++ {
+			a, s, err := l.layout(t.Field(i).Type)
+			if err != nil {
+				return 0, 0, fmt.Errorf("%s.%s: %v", t.Name(), t.Field(i).Name, err)
+			}
+			offset = roundUp(offset, a) + s
+			if a > align {
+				align = a
+			}
+		}
+		if l == Std140 {
+			align = roundUp(align, 16)
+		}
+		return align, roundUp(offset, align), nil
+	}
+	return 0, 0, fmt.Errorf("%s: unsupported type %s", l, t)
+}
+
+// matrix lays out a column-major matrix as an array of columns.
+func (l Layout) matrix(columns, columnSize int) (align, size int, err error) {
+	stride := columnSize
+	if stride == 12 {
+		stride = 16
+	}
+	if l == Std140 {
+		stride = roundUp(stride, 16)
+	}
+	return stride, stride * columns, nil
+}
+
+// stride returns the distance between elements of an array of t. In
+// std140 it is rounded up to a vec4.
+func (l Layout) stride(t reflect.Type) (int, error) {
+	align, size, err := l.layout(t)
+	if err != nil {
+		return 0, err
+	}
+	stride := roundUp(size, align)
+	if l == Std140 {
+		stride = roundUp(stride, 16)
+	}
+	return stride, nil
+}
+
+// encode writes v at the start of buf, which is already laid out for it.
+func (l Layout) encode(buf []byte, v reflect.Value) {
+	t := v.Type()
+	switch t {
+	case vec2Type, vec3Type, vec4Type:
+		for i := 0; i < v.Len(); i++ {
+			putFloat(buf[i*4:], float32(v.Index(i).Float()))
+		}
+		return
+	case mat2Type, mat3Type, mat4Type:
+		rows := map[reflect.Type]int{mat2Type: 2, mat3Type: 3, mat4Type: 4}[t]
+		stride, _, _ := l.matrix(rows, rows*4)
+		for c := 0; c < rows; c++ {
+			for r := 0; r < rows; r++ {
+				putFloat(buf[c*stride+r*4:], float32(v.Index(c*rows+r).Float()))
+			}
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Float32:
+		putFloat(buf, float32(v.Float()))
+	case reflect.Int32:
+		binary.LittleEndian.PutUint32(buf, uint32(int32(v.Int())))
+	case reflect.Uint32:
+		binary.LittleEndian.PutUint32(buf, uint32(v.Uint()))
+	case reflect.Bool:
+		if v.Bool() {
+			binary.LittleEndian.PutUint32(buf, 1)
+		}
+	case reflect.Array:
+		stride, _ := l.stride(t.Elem())
+		for i := 0; i < v.Len(); i++ {
+			l.encode(buf[i*stride:], v.Index(i))
+		}
+	case reflect.Struct:
+		offset := 0
+		for i := 0; i < v.NumField(); i++ {
+			align, size, _ := l.layout(t.Field(i).Type)
+			offset = roundUp(offset, align)
+			l.encode(buf[offset:], v.Field(i))
+			offset += size
+		}
+	}
+}
+
+func putFloat(buf []byte, f float32) {
+	binary.LittleEndian.PutUint32(buf, math.Float32bits(f))
+}
+
+func roundUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
@@ -0,0 +1,152 @@
+package glutil
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type vec3Float struct {
+	V mgl32.Vec3
+	F float32
+}
+
+type floatArray struct {
+	A [3]float32
+	F float32
+}
+
+type mat3Float struct {
+	M mgl32.Mat3
+	F float32
+}
+
+type inner struct {
+	B mgl32.Vec2
+	C float32
+}
+
+type nested struct {
+	A float32
+	S inner
+	D float32
+}
+
+func TestLayoutOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  Layout
+		v       interface{}
+		offsets map[string]int
+		size    int
+	}{
+		// A float packs into the fourth component of a vec3.
+		{"vec3 float std140", Std140, vec3Float{}, map[string]int{"V": 0, "F": 12}, 16},
+		{"vec3 float std430", Std430, vec3Float{}, map[string]int{"V": 0, "F": 12}, 16},
+		// Array elements are padded to a vec4 only in std140.
+		{"float array std140", Std140, floatArray{}, map[string]int{"A": 0, "F": 48}, 64},
+		{"float array std430", Std430, floatArray{}, map[string]int{"A": 0, "F": 12}, 16},
+		// mat3 columns are vec3s, so each takes 16 bytes in both layouts.
+		{"mat3 std140", Std140, mat3Float{}, map[string]int{"M": 0, "F": 48}, 64},
+		{"mat3 std430", Std430, mat3Float{}, map[string]int{"M": 0, "F": 48}, 64},
+		// Structs are aligned and padded to a vec4 only in std140.
+		{"nested std140", Std140, nested{}, map[string]int{"A": 0, "S": 16, "D": 32}, 48},
+		{"nested std430", Std430, nested{}, map[string]int{"A": 0, "S": 8, "D": 24}, 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offsets, err := tt.layout.Offsets(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(offsets, tt.offsets) {
+				t.Errorf("Offsets = %v, want %v", offsets, tt.offsets)
+			}
+			size, err := tt.layout.Size(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != tt.size {
+				t.Errorf("Size = %d, want %d", size, tt.size)
+			}
+		})
+	}
+}
+
+// floatsAt reads the float32 at each byte offset of buf.
+func floatsAt(buf []byte, offsets ...int) []float32 {
+	var fs []float32
+	for _, o := range offsets {
+		fs = append(fs, math.Float32frombits(binary.LittleEndian.Uint32(buf[o:])))
+	}
+	return fs
+}
+
+func TestLayoutEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  Layout
+		v       interface{}
+		offsets []int
+		want    []float32
+	}{
+		{"vec3 float", Std140, vec3Float{mgl32.Vec3{1, 2, 3}, 4}, []int{0, 4, 8, 12}, []float32{1, 2, 3, 4}},
+		{"float array std140", Std140, floatArray{[3]float32{1, 2, 3}, 4}, []int{0, 16, 32, 48}, []float32{1, 2, 3, 4}},
+		{"float array std430", Std430, floatArray{[3]float32{1, 2, 3}, 4}, []int{0, 4, 8, 12}, []float32{1, 2, 3, 4}},
+		{
+			"mat3 columns", Std140, mat3Float{mgl32.Mat3{1, 2, 3, 4, 5, 6, 7, 8, 9}, 10},
+			[]int{0, 4, 8, 16, 20, 24, 32, 36, 40, 48},
+			[]float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			"nested std140", Std140, nested{1, inner{mgl32.Vec2{2, 3}, 4}, 5},
+			[]int{0, 16, 20, 24, 32}, []float32{1, 2, 3, 4, 5},
+		},
+		{
+			"nested std430", Std430, nested{1, inner{mgl32.Vec2{2, 3}, 4}, 5},
+			[]int{0, 8, 12, 16, 24}, []float32{1, 2, 3, 4, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := tt.layout.Encode(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := floatsAt(buf, tt.offsets...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("floats at %v = %v, want %v", tt.offsets, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutEncodeIntegers(t *testing.T) {
+	v := struct {
+		I int32
+		U uint32
+		B bool
+		N bool
+	}{-2, 7, true, false}
+	buf, err := Std140.Encode(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{0xfffffffe, 7, 1, 0}
+	for i, w := range want {
+		if got := binary.LittleEndian.Uint32(buf[4*i:]); got != w {
+			t.Errorf("word %d = %#x, want %#x", i, got, w)
+		}
+	}
+}
+
+func TestLayoutUnsupported(t *testing.T) {
+	if _, err := Std140.Encode(struct{ F float64 }{}); err == nil {
+		t.Error("Encode of a float64 field succeeded")
+	}
+	if _, err := Std430.Encode(1); err == nil {
+		t.Error("Encode of a non-struct succeeded")
+	}
+}
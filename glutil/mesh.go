@@ -0,0 +1,434 @@
+package glutil
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// NewVertexArray creates a vertex array object and a vertex buffer holding
+// vertices, and leaves both bound so attributes can be configured next.
+func NewVertexArray(vertices []float32) (vao, vbo uint32) {
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+
+	return vao, vbo
+}
+
+// VertexAttrib points the named attribute of program at the bound vertex
+// buffer. size, stride and offset are counted in float32s, so an
+// interleaved X, Y, Z, U, V layout uses VertexAttrib(p, "vertTexCoord", 2, 5, 3).
+func VertexAttrib(program uint32, name string, size, stride, offset int) {
+	attrib := uint32(gl.GetAttribLocation(program, gl.Str(name+"\x00")))
+	gl.EnableVertexAttribArray(attrib)
+	gl.VertexAttribPointer(attrib, int32(size), gl.FLOAT, false, int32(stride*4), gl.PtrOffset(offset*4))
+}
+
+// VertexAttribute is one attribute of an interleaved vertex.
+type VertexAttribute struct {
+	// Name is the vertex shader input the attribute feeds.
+	Name string
+	// Size is the number of components, 1 to 4.
+	Size int
+	// Type is the component type: gl.FLOAT, gl.HALF_FLOAT, gl.BYTE,
+	// gl.UNSIGNED_BYTE, gl.SHORT, gl.UNSIGNED_SHORT, gl.INT or
+	// gl.UNSIGNED_INT. Zero means gl.FLOAT.
+	Type uint32
+	// Normalized maps integer components to [0, 1], or [-1, 1] for signed
+	// types, when they feed a float input.
+	Normalized bool
+}
+
+// PositionAttrib is a vec3 position.
+func PositionAttrib(name string) VertexAttribute {
+	return VertexAttribute{Name: name, Size: 3}
+}
+
+// UVAttrib is a vec2 texture coordinate.
+func UVAttrib(name string) VertexAttribute {
+	return VertexAttribute{Name: name, Size: 2}
+}
+
+// NormalAttrib is a vec3 normal.
+func NormalAttrib(name string) VertexAttribute {
+	return VertexAttribute{Name: name, Size: 3}
+}
+
+// TangentAttrib is a vec3 tangent, for normal mapping.
+func TangentAttrib(name string) VertexAttribute {
+	return VertexAttribute{Name: name, Size: 3}
+}
+
+// ColorAttrib is an RGBA colour of four normalized bytes.
+func ColorAttrib(name string) VertexAttribute {
+	return VertexAttribute{Name: name, Size: 4, Type: gl.UNSIGNED_BYTE, Normalized: true}
+}
+
+// componentBytes returns the size of one component of a, or 0 for an
+// unknown type.
+func (a VertexAttribute) componentBytes() int {
+	switch a.Type {
+	case 0, gl.FLOAT, gl.INT, gl.UNSIGNED_INT:
+		return 4
+	case gl.HALF_FLOAT, gl.SHORT, gl.UNSIGNED_SHORT:
+		return 2
+	case gl.BYTE, gl.UNSIGNED_BYTE:
+		return 1
+	}
+	return 0
+}
+
+// bytes returns the space a takes in a vertex, padded to 4 bytes as GL
+// prefers.
+func (a VertexAttribute) bytes() int {
+	return roundUp(a.Size*a.componentBytes(), 4)
+}
+
+func (a VertexAttribute) xtype() uint32 {
+	if a.Type == 0 {
+		return gl.FLOAT
+	}
+	return a.Type
+}
+
+// VertexLayout lists the attributes of an interleaved vertex in order,
+// each starting on a 4-byte boundary. The examples' cubes are
+//
+//	glutil.VertexLayout{glutil.PositionAttrib("vert"), glutil.UVAttrib("vertTexCoord")}
+type VertexLayout []VertexAttribute
+
+// Stride returns the size of a vertex in bytes.
+func (l VertexLayout) Stride() int {
+	stride := 0
+	for _, a := range l {
+		stride += a.bytes()
+	}
+	return stride
+}
+
+// Offset returns the byte offset of the attribute called name.
+func (l VertexLayout) Offset(name string) (int, bool) {
+	offset := 0
+	for _, a := range l {
+		if a.Name == name {
+			return offset, true
+		}
+		offset += a.bytes()
+	}
+	return 0, false
+}
+
+func (l VertexLayout) validate() error {
+	if len(l) == 0 {
+		return fmt.Errorf("vertex layout: no attributes")
+	}
+	if len(l) > maxVertexAttribs {
+		return fmt.Errorf("vertex layout: %d attributes, at most %d are supported", len(l), maxVertexAttribs)
+	}
+	seen := map[string]bool{}
+	for _, a := range l {
+		switch {
+		case seen[a.Name]:
+			return fmt.Errorf("vertex layout: attribute %q listed twice", a.Name)
+		case a.Size < 1 || a.Size > 4:
+			return fmt.Errorf("vertex layout: attribute %q has %d components, want 1 to 4", a.Name, a.Size)
+		case a.componentBytes() == 0:
+			return fmt.Errorf("vertex layout: attribute %q has unknown type 0x%X", a.Name, a.Type)
+		case a.Normalized && (a.xtype() == gl.FLOAT || a.xtype() == gl.HALF_FLOAT):
+			return fmt.Errorf("vertex layout: attribute %q is normalized but not an integer type", a.Name)
+		}
+		seen[a.Name] = true
+	}
+	return nil
+}
+
+// Mismatches compares the layout with the active attributes of p and
+// describes each problem: inputs the layout does not provide, matrix
+// inputs, integer inputs fed floats, and component counts that differ,
+// except a vec4 fed three components, whose w is 1. Layout attributes p
+// does not read are not problems, as shaders often ignore some.
+func (l VertexLayout) Mismatches(p *Program) []string {
+	var problems []string
+	for _, v := range p.Attributes {
+		if strings.HasPrefix(v.Name, "gl_") {
+			continue
+		}
+		t := glslTypes[v.Type]
+		var a *VertexAttribute
+		for i := range l {
+			if l[i].Name == v.Name {
+				a = &l[i]
+			}
+		}
+		switch {
+		case a == nil:
+			problems = append(problems, fmt.Sprintf("program %d reads %s %s, which the vertex layout does not provide", p.ID, v.TypeName(), v.Name))
+		case t.columns > 0:
+			problems = append(problems, fmt.Sprintf("program %d reads %s %s; matrix attributes are not supported", p.ID, v.TypeName(), v.Name))
+		case (t.kind == kindInt || t.kind == kindUint) && (a.xtype() == gl.FLOAT || a.xtype() == gl.HALF_FLOAT):
+			problems = append(problems, fmt.Sprintf("program %d reads %s %s, but the vertex layout gives floats", p.ID, v.TypeName(), v.Name))
+		case a.Size != t.components && !(a.Size == 3 && t.components == 4):
+			problems = append(problems, fmt.Sprintf("program %d reads %s %s, but the vertex layout gives %d components", p.ID, v.TypeName(), v.Name, a.Size))
+		}
+	}
+	return problems
+}
+
+// maxVertexAttribs is the number of attributes every GL 4.1 driver
+// supports, and the most a VertexLayout may have.
+const maxVertexAttribs = 16
+
+// Mesh is a vertex buffer with its layout, and optionally an index
+// buffer. Drawing it with a program binds the layout's attributes to the
+// program's inputs by name, in a vertex array object kept per set of
+// attribute locations, so one mesh can be drawn by programs whose
+// locations differ.
+type Mesh struct {
+	Layout VertexLayout
+	VBO    uint32
+	// EBO is the index buffer of an indexed mesh, and IndexType the type
+	// of its indices, gl.UNSIGNED_SHORT or gl.UNSIGNED_INT.
+	EBO       uint32
+	IndexType uint32
+	// Vertices is the number of vertices in VBO.
+	Vertices int32
+	// Count is the number of vertices Draw draws: the number of indices
+	// for an indexed mesh.
+	Count int32
+
+	vaos    map[[maxVertexAttribs]int32]uint32
+	checked map[*Program]bool
+}
+
+// NewMesh uploads vertices, interleaved as layout says, into a new
+// vertex buffer.
+func NewMesh(layout VertexLayout, vertices []float32) (*Mesh, error) {
+	if err := layout.validate(); err != nil {
+		return nil, err
+	}
+	stride := layout.Stride() / 4
+	if len(vertices)%stride != 0 {
+		return nil, fmt.Errorf("mesh: %d floats is not a whole number of %d-float vertices", len(vertices), stride)
+	}
+	m := &Mesh{Layout: layout, Vertices: int32(len(vertices) / stride), Count: int32(len(vertices) / stride), vaos: map[[maxVertexAttribs]int32]uint32{}, checked: map[*Program]bool{}}
+	gl.GenBuffers(1, &m.VBO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.VBO)
+	if len(vertices) > 0 {
+		gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+	}
+	return m, nil
+}
+
+// NewIndexedMesh is NewMesh with an index buffer: each three indices are
+// a triangle of vertices. Indices are stored as gl.UNSIGNED_SHORT when
+// every one fits, else as gl.UNSIGNED_INT.
+func NewIndexedMesh(layout VertexLayout, vertices []float32, indices []uint32) (*Mesh, error) {
+	m, err := NewMesh(layout, vertices)
+	if err != nil {
+		return nil, err
+	}
+	var max uint32
+	for _, i := range indices {
+		if i >= uint32(m.Vertices) {
+			m.Delete()
+			return nil, fmt.Errorf("mesh: index %d out of range for %d vertices", i, m.Vertices)
+		}
+		if i > max {
+			max = i
+		}
+	}
+	m.Count = int32(len(indices))
+	// The element array binding belongs to the bound VAO, which may be
+	// another mesh's, so upload through COPY_WRITE_BUFFER instead; Bind
+	// attaches the EBO to this mesh's own VAOs.
+	gl.GenBuffers(1, &m.EBO)
+	gl.BindBuffer(gl.COPY_WRITE_BUFFER, m.EBO)
+	var short []uint16
+	m.IndexType, short = packIndices(indices, max)
+	switch {
+	case m.IndexType == gl.UNSIGNED_INT:
+		gl.BufferData(gl.COPY_WRITE_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+	case len(short) > 0:
+		gl.BufferData(gl.COPY_WRITE_BUFFER, len(short)*2, gl.Ptr(short), gl.STATIC_DRAW)
+	}
+	gl.BindBuffer(gl.COPY_WRITE_BUFFER, 0)
+	return m, nil
+}
+
+// packIndices picks the index type for indices whose largest is max:
+// gl.UNSIGNED_SHORT, with the indices converted, when max fits in 16 bits,
+// else gl.UNSIGNED_INT.
+func packIndices(indices []uint32, max uint32) (indexType uint32, short []uint16) {
+	if max > math.MaxUint16 {
+		return gl.UNSIGNED_INT, nil
+	}
+	short = make([]uint16, len(indices))
+	for i, index := range indices {
+		short[i] = uint16(index)
+	}
+	return gl.UNSIGNED_SHORT, short
+}
+
+// Bind binds the mesh's vertex array object for p, creating it the first
+// time p's attribute locations are seen. Each of the layout's Mismatches
+// with p is logged once.
+func (m *Mesh) Bind(p *Program) {
+	if !m.checked[p] {
+		m.checked[p] = true
+		for _, problem := range m.Layout.Mismatches(p) {
+			p.warn("layout "+problem, "%s", problem)
+		}
+	}
+	key := m.locations(p)
+	if vao, ok := m.vaos[key]; ok {
+		gl.BindVertexArray(vao)
+		return
+	}
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.VBO)
+	if m.EBO != 0 {
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, m.EBO)
+	}
+	stride, offset := int32(m.Layout.Stride()), 0
+	for i, a := range m.Layout {
+		if key[i] >= 0 {
+			loc := uint32(key[i] / 2)
+			gl.EnableVertexAttribArray(loc)
+			if key[i]%2 == 1 {
+				gl.VertexAttribIPointer(loc, int32(a.Size), a.xtype(), stride, gl.PtrOffset(offset))
+			} else {
+				gl.VertexAttribPointer(loc, int32(a.Size), a.xtype(), a.Normalized, stride, gl.PtrOffset(offset))
+			}
+		}
+		offset += a.bytes()
+	}
+	m.vaos[key] = vao
+}
+
+// locations returns, for each layout attribute, twice its location in p,
+// plus one if it feeds an integer input, or -1 if p does not read it.
+func (m *Mesh) locations(p *Program) [maxVertexAttribs]int32 {
+	var key [maxVertexAttribs]int32
+	for i := range key {
+		key[i] = -1
+	}
+	for i, a := range m.Layout {
+		v, ok := p.attributes[a.Name]
+		if !ok || v.Location < 0 {
+			continue
+		}
+		key[i] = 2 * v.Location
+		kind := glslTypes[v.Type].kind
+		if (kind == kindInt || kind == kindUint) && a.xtype() != gl.FLOAT && a.xtype() != gl.HALF_FLOAT {
+			key[i]++
+		}
+	}
+	return key
+}
+
+// Draw draws the mesh as triangles with p, which must be in use.
+func (m *Mesh) Draw(p *Program) {
+	m.DrawRange(p, 0, m.Count)
+}
+
+// DrawRange draws count vertices from first as triangles with p, which
+// must be in use. For an indexed mesh first and count count indices.
+func (m *Mesh) DrawRange(p *Program, first, count int32) {
+	m.Bind(p)
+	if m.EBO == 0 {
+		gl.DrawArrays(gl.TRIANGLES, first, count)
+		return
+	}
+	size := 2
+	if m.IndexType == gl.UNSIGNED_INT {
+		size = 4
+	}
+	gl.DrawElements(gl.TRIANGLES, count, m.IndexType, gl.PtrOffset(int(first)*size))
+}
+
+// Delete deletes the vertex and index buffers and the vertex array
+// objects.
+func (m *Mesh) Delete() {
+	for _, vao := range m.vaos {
+		gl.DeleteVertexArrays(1, &vao)
+	}
+	m.vaos = map[[maxVertexAttribs]int32]uint32{}
+	gl.DeleteBuffers(1, &m.VBO)
+	if m.EBO != 0 {
+		gl.DeleteBuffers(1, &m.EBO)
+	}
+}
+
+// Weld merges vertices of stride floats whose every component is within
+// tolerance of an earlier vertex's, and returns the distinct vertices in
+// the order first seen with an index per input vertex, ready for
+// NewIndexedMesh. A tolerance of 0 merges exact copies only. A vertex
+// merges with the earliest distinct vertex it matches, so a chain of near
+// neighbours does not drift further than tolerance. It is an error for
+// vertices not to be a whole number of vertices.
+func Weld(vertices []float32, stride int, tolerance float32) (welded []float32, indices []uint32, err error) {
+	if stride < 1 {
+		return nil, nil, fmt.Errorf("mesh: weld stride %d, want at least 1", stride)
+	}
+	if len(vertices)%stride != 0 {
+		return nil, nil, fmt.Errorf("mesh: %d floats is not a whole number of %d-float vertices", len(vertices), stride)
+	}
+	// Buckets on the first component: a match lies in the same or a
+	// neighbouring bucket.
+	bucket := func(v float32) int64 {
+		if tolerance <= 0 {
+			if v == 0 {
+				v = 0 // -0 and +0 match
+			}
+			return int64(math.Float32bits(v))
+		}
+		return int64(math.Floor(float64(v) / float64(tolerance)))
+	}
+	near := func(a, b []float32) bool {
+		for i := range a {
+			if d := a[i] - b[i]; d > tolerance || d < -tolerance {
+				return false
+			}
+		}
+		return true
+	}
+	buckets := map[int64][]uint32{}
+	n := len(vertices) / stride
+	indices = make([]uint32, n)
+	for i := 0; i < n; i++ {
+		v := vertices[i*stride : (i+1)*stride]
+		b := bucket(v[0])
+		match := -1
+		for _, nb := range []int64{b - 1, b, b + 1} {
+			if tolerance <= 0 && nb != b {
+				continue
+			}
+			for _, j := range buckets[nb] {
+				if match >= 0 && int(j) >= match {
+					break
+				}
+				if near(v, welded[int(j)*stride:(int(j)+1)*stride]) {
+					match = int(j)
+					break
+				}
+			}
+		}
+		if match >= 0 {
+			indices[i] = uint32(match)
+			continue
+		}
+		j := uint32(len(welded) / stride)
+		welded = append(welded, v...)
+		buckets[b] = append(buckets[b], j)
+		indices[i] = j
+	}
+	return welded, indices, nil
+}
@@ -0,0 +1,328 @@
+package glutil
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+func TestVertexLayoutStride(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout VertexLayout
+		want   int
+	}{
+		{"empty", VertexLayout{}, 0},
+		{"position and UV", VertexLayout{PositionAttrib("vert"), UVAttrib("vertTexCoord")}, 20},
+		{"colour bytes", VertexLayout{PositionAttrib("vert"), ColorAttrib("color")}, 16},
+		// Attributes are padded to 4 bytes.
+		{"three bytes", VertexLayout{{Name: "a", Size: 3, Type: gl.UNSIGNED_BYTE}}, 4},
+		{"three halves", VertexLayout{{Name: "a", Size: 3, Type: gl.HALF_FLOAT}}, 8},
+		{"one short", VertexLayout{{Name: "a", Size: 1, Type: gl.SHORT}, {Name: "b", Size: 1, Type: gl.INT}}, 8},
+	}
+	for _, tt := range tests {
+		if got := tt.layout.Stride(); got != tt.want {
+			t.Errorf("%s: Stride() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVertexLayoutOffset(t *testing.T) {
+	layout := VertexLayout{
+		PositionAttrib("position"),
+		ColorAttrib("color"),
+		{Name: "uv", Size: 2, Type: gl.HALF_FLOAT},
+		{Name: "flag", Size: 1, Type: gl.UNSIGNED_BYTE},
+		NormalAttrib("normal"),
+	}
+	tests := []struct {
+		name   string
+		offset int
+		ok     bool
+	}{
+		{"position", 0, true},
+		{"color", 12, true},
+		{"uv", 16, true},
+		{"flag", 20, true},
+		{"normal", 24, true},
+		{"tangent", 0, false},
+	}
+	for _, tt := range tests {
+		if offset, ok := layout.Offset(tt.name); offset != tt.offset || ok != tt.ok {
+			t.Errorf("Offset(%q) = %d, %v, want %d, %v", tt.name, offset, ok, tt.offset, tt.ok)
+		}
+	}
+	if got := layout.Stride(); got != 36 {
+		t.Errorf("Stride() = %d, want 36", got)
+	}
+}
+
+func TestVertexLayoutValidate(t *testing.T) {
+	tooMany := make(VertexLayout, maxVertexAttribs+1)
+	for i := range tooMany {
+		tooMany[i] = VertexAttribute{Name: string(rune('a' + i)), Size: 1}
+	}
+	tests := []struct {
+		name   string
+		layout VertexLayout
+		err    string // "" when valid
+	}{
+		{"cube", VertexLayout{PositionAttrib("vert"), UVAttrib("vertTexCoord")}, ""},
+		{"normalized bytes", VertexLayout{ColorAttrib("color")}, ""},
+		{"integer", VertexLayout{{Name: "bones", Size: 4, Type: gl.UNSIGNED_SHORT}}, ""},
+		{"empty", VertexLayout{}, "no attributes"},
+		{"too many", tooMany, "17 attributes"},
+		{"twice", VertexLayout{PositionAttrib("p"), NormalAttrib("p")}, `"p" listed twice`},
+		{"no components", VertexLayout{{Name: "a"}}, "0 components"},
+		{"five components", VertexLayout{{Name: "a", Size: 5}}, "5 components"},
+		{"unknown type", VertexLayout{{Name: "a", Size: 1, Type: gl.DOUBLE}}, "unknown type 0x140A"},
+		{"normalized float", VertexLayout{{Name: "a", Size: 1, Normalized: true}}, "not an integer type"},
+		{"normalized half", VertexLayout{{Name: "a", Size: 2, Type: gl.HALF_FLOAT, Normalized: true}}, "not an integer type"},
+	}
+	for _, tt := range tests {
+		err := tt.layout.validate()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: validate() = %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: validate() = %v, want an error containing %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestVertexLayoutMismatches(t *testing.T) {
+	// A stub program: Mismatches reads only ID and Attributes.
+	p := &Program{ID: 7, Attributes: []Variable{
+		{Name: "gl_VertexID", Type: gl.INT, Size: 1, Location: -1},
+		{Name: "position", Type: gl.FLOAT_VEC3, Size: 1, Location: 0},
+	}}
+	position := PositionAttrib("position")
+	tests := []struct {
+		name   string
+		inputs []Variable
+		layout VertexLayout
+		want   []string
+	}{
+		{
+			name:   "matching",
+			inputs: []Variable{{Name: "uv", Type: gl.FLOAT_VEC2}},
+			layout: VertexLayout{position, UVAttrib("uv"), NormalAttrib("unread")},
+		},
+		{
+			name:   "missing input",
+			inputs: []Variable{{Name: "uv", Type: gl.FLOAT_VEC2}},
+			layout: VertexLayout{position},
+			want:   []string{"program 7 reads vec2 uv, which the vertex layout does not provide"},
+		},
+		{
+			name:   "integer input fed floats",
+			inputs: []Variable{{Name: "bones", Type: gl.INT_VEC2}},
+			layout: VertexLayout{position, {Name: "bones", Size: 2}},
+			want:   []string{"program 7 reads ivec2 bones, but the vertex layout gives floats"},
+		},
+		{
+			name:   "integer input fed half floats",
+			inputs: []Variable{{Name: "bones", Type: gl.UNSIGNED_INT_VEC2}},
+			layout: VertexLayout{position, {Name: "bones", Size: 2, Type: gl.HALF_FLOAT}},
+			want:   []string{"program 7 reads uvec2 bones, but the vertex layout gives floats"},
+		},
+		{
+			name:   "integer input fed integers",
+			inputs: []Variable{{Name: "bones", Type: gl.UNSIGNED_INT_VEC4}},
+			layout: VertexLayout{position, {Name: "bones", Size: 4, Type: gl.UNSIGNED_BYTE}},
+		},
+		{
+			name:   "vec4 fed three components",
+			inputs: []Variable{{Name: "color", Type: gl.FLOAT_VEC4}},
+			layout: VertexLayout{position, {Name: "color", Size: 3}},
+		},
+		{
+			name:   "vec3 fed four components",
+			inputs: []Variable{{Name: "normal", Type: gl.FLOAT_VEC3}},
+			layout: VertexLayout{position, {Name: "normal", Size: 4}},
+			want:   []string{"program 7 reads vec3 normal, but the vertex layout gives 4 components"},
+		},
+		{
+			name:   "vec4 fed two components",
+			inputs: []Variable{{Name: "color", Type: gl.FLOAT_VEC4}},
+			layout: VertexLayout{position, {Name: "color", Size: 2}},
+			want:   []string{"program 7 reads vec4 color, but the vertex layout gives 2 components"},
+		},
+		{
+			name:   "matrix",
+			inputs: []Variable{{Name: "instance", Type: gl.FLOAT_MAT4}},
+			layout: VertexLayout{position, {Name: "instance", Size: 4}},
+			want:   []string{"program 7 reads mat4 instance; matrix attributes are not supported"},
+		},
+		{
+			name:   "several",
+			inputs: []Variable{{Name: "uv", Type: gl.FLOAT_VEC2}, {Name: "normal", Type: gl.FLOAT_VEC3}},
+			layout: VertexLayout{{Name: "position", Size: 2}},
+			want: []string{
+				"program 7 reads vec3 position, but the vertex layout gives 2 components",
+				"program 7 reads vec2 uv, which the vertex layout does not provide",
+				"program 7 reads vec3 normal, which the vertex layout does not provide",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := *p
+			stub.Attributes = append(append([]Variable(nil), p.Attributes...), tt.inputs...)
+			if got := tt.layout.Mismatches(&stub); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mismatches:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// texturedCube returns the 36 position and UV vertices of a unit cube's
+// 12 triangles. Each face maps to its own sixth of the texture, so no two
+// faces share a vertex.
+func texturedCube() []float32 {
+	corners := [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	var vertices []float32
+	for face := 0; face < 6; face++ {
+		axis, side := face/2, float32(face%2)
+		for _, c := range []int{0, 1, 2, 0, 2, 3} {
+			s, t := corners[c][0], corners[c][1]
+			var p [3]float32
+			p[axis], p[(axis+1)%3], p[(axis+2)%3] = side, s, t
+			vertices = append(vertices, p[0], p[1], p[2], (float32(face)+s)/6, t)
+		}
+	}
+	return vertices
+}
+
+func TestWeld(t *testing.T) {
+	tests := []struct {
+		name      string
+		vertices  []float32
+		stride    int
+		tolerance float32
+		welded    []float32
+		indices   []uint32
+	}{
+		{
+			name:     "signed zeros",
+			vertices: []float32{0, 1, float32(math.Copysign(0, -1)), 1, 2, 0, 2, float32(math.Copysign(0, -1))},
+			stride:   2,
+			welded:   []float32{0, 1, 2, 0},
+			indices:  []uint32{0, 0, 1, 1},
+		},
+		{
+			name:     "exact copies only",
+			vertices: []float32{1, 1.0000001, 1},
+			stride:   1,
+			welded:   []float32{1, 1.0000001},
+			indices:  []uint32{0, 1, 0},
+		},
+		{
+			// 0.099 and 0.101 fall in buckets 0 and 1, -0.001 in -1.
+			name:     "across bucket boundaries",
+			vertices: []float32{0.099, 0.101, -0.001},
+			stride:   1, tolerance: 0.1,
+			welded:  []float32{0.099},
+			indices: []uint32{0, 0, 0},
+		},
+		{
+			// 1.8 is within tolerance of 0.9, but 0.9 merged into 0, so
+			// 1.8 starts a new vertex instead of drifting to 0.
+			name:     "no chain drift",
+			vertices: []float32{0, 0.9, 1.8, 2.7},
+			stride:   1, tolerance: 1,
+			welded:  []float32{0, 1.8},
+			indices: []uint32{0, 0, 1, 1},
+		},
+		{
+			// 1 is within tolerance of both; 2 came first though its
+			// bucket is searched last.
+			name:     "earliest match",
+			vertices: []float32{2, 5, 0, 1, 5},
+			stride:   1, tolerance: 1,
+			welded:  []float32{2, 5, 0},
+			indices: []uint32{0, 1, 2, 0, 1},
+		},
+		{
+			name:     "every component within tolerance",
+			vertices: []float32{0, 0, 0.5, 0.5, 0.5, 2},
+			stride:   2, tolerance: 1,
+			welded:  []float32{0, 0, 0.5, 2},
+			indices: []uint32{0, 0, 1},
+		},
+		{
+			name:    "empty",
+			stride:  3,
+			indices: []uint32{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			welded, indices, err := Weld(tt.vertices, tt.stride, tt.tolerance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(welded, tt.welded) || !reflect.DeepEqual(indices, tt.indices) {
+				t.Errorf("Weld = %v, %v, want %v, %v", welded, indices, tt.welded, tt.indices)
+			}
+		})
+	}
+}
+
+func TestWeldCube(t *testing.T) {
+	cube := texturedCube()
+	for _, tolerance := range []float32{0, 1e-4} {
+		welded, indices, err := Weld(cube, 5, tolerance)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(welded) != 24*5 || len(indices) != 36 {
+			t.Fatalf("tolerance %v: welded to %d vertices and %d indices, want 24 and 36", tolerance, len(welded)/5, len(indices))
+		}
+		for i, index := range indices {
+			got, want := welded[index*5:index*5+5], cube[i*5:i*5+5]
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("tolerance %v: vertex %d is %v, want %v", tolerance, i, got, want)
+			}
+		}
+	}
+}
+
+func TestWeldErrors(t *testing.T) {
+	if _, _, err := Weld([]float32{1, 2, 3}, 0, 0); err == nil {
+		t.Error("Weld accepted stride 0")
+	}
+	// A trailing partial vertex is an error, not silently dropped.
+	if _, _, err := Weld([]float32{1, 2, 3, 4, 5, 6, 7}, 3, 0); err == nil {
+		t.Error("Weld accepted 7 floats of 3-float vertices")
+	}
+}
+
+func TestPackIndices(t *testing.T) {
+	tests := []struct {
+		name      string
+		indices   []uint32
+		indexType uint32
+		short     []uint16
+	}{
+		{"empty", []uint32{}, gl.UNSIGNED_SHORT, []uint16{}},
+		{"small", []uint32{0, 2, 1}, gl.UNSIGNED_SHORT, []uint16{0, 2, 1}},
+		{"largest short", []uint32{65535, 0}, gl.UNSIGNED_SHORT, []uint16{65535, 0}},
+		{"past a short", []uint32{65536, 0}, gl.UNSIGNED_INT, nil},
+	}
+	for _, tt := range tests {
+		var max uint32
+		for _, i := range tt.indices {
+			if i > max {
+				max = i
+			}
+		}
+		indexType, short := packIndices(tt.indices, max)
+		if indexType != tt.indexType || !reflect.DeepEqual(short, tt.short) {
+			t.Errorf("%s: packIndices = 0x%X %v, want 0x%X %v", tt.name, indexType, short, tt.indexType, tt.short)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package glutil
+
+import (
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// NewProgram compiles the vertex and fragment shader sources and links them
+// into a program.
+func NewProgram(vertexShaderSource, fragmentShaderSource string) (uint32, error) {
+	return new(ProgramBuilder).
+		Source(gl.VERTEX_SHADER, vertexShaderSource).
+		Source(gl.FRAGMENT_SHADER, fragmentShaderSource).
+		Link()
+}
+
+type stageShader struct {
+	shaderType, shader uint32
+}
+
+// link links compiled shaders, given in pipeline order, into a program and
+// deletes them. Failures are returned as a *LinkError. retrievable asks
+// the driver to keep the binary for glGetProgramBinary.
+func link(shaders []stageShader, retrievable bool) (uint32, error) {
+	program := gl.CreateProgram()
+	if retrievable {
+		gl.ProgramParameteri(program, gl.PROGRAM_BINARY_RETRIEVABLE_HINT, gl.TRUE)
+	}
+
+	for _, s := range shaders {
+		gl.AttachShader(program, s.shader)
+	}
+	gl.LinkProgram(program)
+
+	for _, s := range shaders {
+		gl.DeleteShader(s.shader)
+	}
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+
+		gl.DeleteProgram(program)
+
+		var stages []string
+		for _, s := range shaders {
+			stages = append(stages, StageName(s.shaderType))
+		}
+		return 0, &LinkError{Stages: stages, Log: log, Diagnostics: ParseLinkLog(log)}
+	}
+
+	return program, nil
+}
+
+// CompileShader compiles a single shader stage of the given type, e.g.
+// gl.VERTEX_SHADER. Compile failures are returned as a *ShaderError.
+func CompileShader(source string, shaderType uint32) (uint32, error) {
+	if !strings.HasSuffix(source, "\x00") {
+		source += "\x00"
+	}
+	shader := gl.CreateShader(shaderType)
+
+	csources, free := gl.Strs(source)
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+
+		gl.DeleteShader(shader)
+
+		stage := StageName(shaderType)
+		return 0, &ShaderError{
+			Stage:       stage,
+			Source:      source,
+			Log:         log,
+			Diagnostics: ParseShaderLog(stage, log),
+		}
+	}
+
+	return shader, nil
+}
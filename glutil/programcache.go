@@ -0,0 +1,253 @@
+package glutil
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// DefaultProgramCache, if set, is used by loaders made with
+// NewShaderLoader.
+var DefaultProgramCache *ProgramCache
+
+// DriverInfo identifies the GL implementation a program binary was made by.
+// Binaries are only valid for the driver that produced them.
+type DriverInfo struct {
+	Vendor, Renderer, Version string
+}
+
+// CurrentDriver reads the driver strings of the current context.
+func CurrentDriver() DriverInfo {
+	return DriverInfo{
+		Vendor:   gl.GoStr(gl.GetString(gl.VENDOR)),
+		Renderer: gl.GoStr(gl.GetString(gl.RENDERER)),
+		Version:  gl.GoStr(gl.GetString(gl.VERSION)),
+	}
+}
+
+// CacheStage is one shader stage as far as the cache key is concerned.
+type CacheStage struct {
+	Type uint32
+	Code string
+}
+
+// CacheKey returns the cache key of a program built from stages with
+// defines on driver. Stage order and define order do not matter.
+func CacheKey(driver DriverInfo, defines map[string]string, stages []CacheStage) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "vendor %q\nrenderer %q\nversion %q\n", driver.Vendor, driver.Renderer, driver.Version)
+
+	names := make([]string, 0, len(defines))
+	for name := range defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "define %q %q\n", name, defines[name])
+	}
+
+	sorted := append([]CacheStage(nil), stages...)
+	sort.SliceStable(sorted, func(i, j int) bool { return stageIndex(sorted[i].Type) < stageIndex(sorted[j].Type) })
+	for _, s := range sorted {
+		fmt.Fprintf(h, "stage %d %d\n%s\n", s.Type, len(s.Code), s.Code)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ProgramCache keeps linked program binaries in a directory, one file per
+// cache key, so later runs can skip compiling. Binaries the driver rejects,
+// for example after a driver update, are deleted and the program is built
+// from source again.
+type ProgramCache struct {
+	Dir    string
+	Driver DriverInfo
+	// MaxBytes bounds the total size of the cache. The least recently
+	// used entries are removed once it is exceeded; zero means no limit.
+	MaxBytes int64
+}
+
+// DefaultProgramCacheSize is the MaxBytes of caches from OpenProgramCache.
+const DefaultProgramCacheSize = 64 << 20
+
+// OpenProgramCache creates dir if needed and returns a cache for the
+// current context's driver. It fails if the driver supports no program
+// binary formats.
+func OpenProgramCache(dir string) (*ProgramCache, error) {
+	var formats int32
+	gl.GetIntegerv(gl.NUM_PROGRAM_BINARY_FORMATS, &formats)
+	if formats == 0 {
+		return nil, errors.New("program cache: driver supports no program binary formats")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("program cache: %v", err)
+	}
+	return &ProgramCache{Dir: dir, Driver: CurrentDriver(), MaxBytes: DefaultProgramCacheSize}, nil
+}
+
+// Key returns the cache key for stages and defines on c's driver.
+func (c *ProgramCache) Key(defines map[string]string, stages []CacheStage) string {
+	return CacheKey(c.Driver, defines, stages)
+}
+
+// Load returns a program created from the binary stored under key. It
+// reports false if there is no entry or the driver rejects it, in which
+// case the entry is removed.
+func (c *ProgramCache) Load(key string) (uint32, bool) {
+	format, data, err := c.read(key)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("program cache: %v", err)
+			c.remove(key)
+		}
+		return 0, false
+	}
+
+	program := gl.CreateProgram()
+	gl.ProgramBinary(program, format, gl.Ptr(data), int32(len(data)))
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		gl.DeleteProgram(program)
+		c.remove(key)
+		return 0, false
+	}
+
+	now := time.Now()
+	os.Chtimes(c.path(key), now, now)
+	return program, true
+}
+
+// Store saves the binary of a program linked with
+// PROGRAM_BINARY_RETRIEVABLE_HINT under key and evicts old entries.
+func (c *ProgramCache) Store(key string, program uint32) error {
+	var length int32
+	gl.GetProgramiv(program, gl.PROGRAM_BINARY_LENGTH, &length)
+	if length == 0 {
+		return errors.New("program cache: driver returned an empty program binary")
+	}
+	data := make([]byte, length)
+	var format uint32
+	gl.GetProgramBinary(program, length, &length, &format, gl.Ptr(data))
+
+	if err := c.write(key, format, data[:length]); err != nil {
+		return err
+	}
+	return c.Evict()
+}
+
+// cacheMagic starts every cache file, followed by the binary format and
+// the binary length as little-endian uint32s.
+const cacheMagic = "GLPB"
+
+func (c *ProgramCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".bin")
+}
+
+func (c *ProgramCache) read(key string) (format uint32, data []byte, err error) {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(b) < 12 || string(b[:4]) != cacheMagic {
+		return 0, nil, fmt.Errorf("%s: not a program binary", c.path(key))
+	}
+	format = binary.LittleEndian.Uint32(b[4:])
+	n := binary.LittleEndian.Uint32(b[8:])
+	if int(n) != len(b)-12 {
+		return 0, nil, fmt.Errorf("%s: truncated: want %d bytes, have %d", c.path(key), n, len(b)-12)
+	}
+	return format, b[12:], nil
+}
+
+// write stores the entry through a temporary file so a crash never leaves
+// a half-written binary under the real name.
+func (c *ProgramCache) write(key string, format uint32, data []byte) error {
+	b := make([]byte, 12+len(data))
+	copy(b, cacheMagic)
+	binary.LittleEndian.PutUint32(b[4:], format)
+	binary.LittleEndian.PutUint32(b[8:], uint32(len(data)))
+	copy(b[12:], data)
+
+	tmp, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("program cache: %v", err)
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("program cache: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("program cache: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("program cache: %v", err)
+	}
+	return nil
+}
+
+func (c *ProgramCache) remove(key string) {
+	os.Remove(c.path(key))
+}
+
+// Evict removes the least recently used entries, by modification time,
+// until the cache fits in MaxBytes.
+func (c *ProgramCache) Evict() error {
+	if c.MaxBytes <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return fmt.Errorf("program cache: %v", err)
+	}
+
+	type file struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".bin") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{e.Name(), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].modTime.Equal(files[j].modTime) {
+			return files[i].modTime.Before(files[j].modTime)
+		}
+		return files[i].name < files[j].name
+	})
+
+	for _, f := range files {
+		if total <= c.MaxBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.Dir, f.name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("program cache: %v", err)
+		}
+		total -= f.size
+	}
+	return nil
+}
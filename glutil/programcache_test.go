@@ -0,0 +1,155 @@
+package glutil
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+func TestCacheKey(t *testing.T) {
+	driver := DriverInfo{Vendor: "Mesa", Renderer: "llvmpipe", Version: "4.1 Mesa 23.0"}
+	defines := map[string]string{"LIT": "1", "TEXTURED": ""}
+	stages := []CacheStage{
+		{gl.VERTEX_SHADER, "void main() { gl_Position = vec4(0); }"},
+		{gl.FRAGMENT_SHADER, "out vec4 c; void main() { c = vec4(1); }"},
+	}
+	base := CacheKey(driver, defines, stages)
+
+	same := []struct {
+		name    string
+		driver  DriverInfo
+		defines map[string]string
+		stages  []CacheStage
+	}{
+		{"stage order", driver, defines, []CacheStage{stages[1], stages[0]}},
+		{"define map copy", driver, map[string]string{"TEXTURED": "", "LIT": "1"}, stages},
+	}
+	for _, tt := range same {
+		if got := CacheKey(tt.driver, tt.defines, tt.stages); got != base {
+			t.Errorf("%s: key changed", tt.name)
+		}
+	}
+
+	changed := []struct {
+		name    string
+		driver  DriverInfo
+		defines map[string]string
+		stages  []CacheStage
+	}{
+		{"vertex source", driver, defines, []CacheStage{{gl.VERTEX_SHADER, stages[0].Code + " "}, stages[1]}},
+		{"stage type", driver, defines, []CacheStage{{gl.GEOMETRY_SHADER, stages[0].Code}, stages[1]}},
+		{"extra stage", driver, defines, append([]CacheStage{{gl.GEOMETRY_SHADER, "void main() {}"}}, stages...)},
+		{"define value", driver, map[string]string{"LIT": "2", "TEXTURED": ""}, stages},
+		{"define added", driver, map[string]string{"LIT": "1", "TEXTURED": "", "FOG": ""}, stages},
+		{"define removed", driver, map[string]string{"LIT": "1"}, stages},
+		{"vendor", DriverInfo{"NVIDIA Corporation", driver.Renderer, driver.Version}, defines, stages},
+		{"renderer", DriverInfo{driver.Vendor, "AMD Radeon", driver.Version}, defines, stages},
+		{"version", DriverInfo{driver.Vendor, driver.Renderer, "4.1 Mesa 23.1"}, defines, stages},
+		// Quoting keeps the boundaries between fields.
+		{"shifted define", driver, map[string]string{"LIT": "1 TEXTURED", "": ""}, stages},
+	}
+	for _, tt := range changed {
+		if got := CacheKey(tt.driver, tt.defines, tt.stages); got == base {
+			t.Errorf("%s: key did not change", tt.name)
+		}
+	}
+}
+
+// cacheFiles returns the sorted entry names in the cache directory.
+func cacheFiles(t *testing.T, c *ProgramCache) []string {
+	t.Helper()
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".bin"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// storeAt writes an entry of size data bytes under key, last used at
+// minute m.
+func storeAt(t *testing.T, c *ProgramCache, key string, size, m int) {
+	t.Helper()
+	if err := c.write(key, 1, make([]byte, size)); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2020, 1, 1, 0, m, 0, 0, time.UTC)
+	if err := os.Chtimes(c.path(key), at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProgramCacheEvict(t *testing.T) {
+	// Each entry is a 12-byte header and 88 bytes of binary.
+	tests := []struct {
+		name     string
+		maxBytes int64
+		used     map[string]int // key: minute last used
+		want     []string
+	}{
+		{"under limit", 400, map[string]int{"a": 1, "b": 2, "c": 3}, []string{"a", "b", "c"}},
+		{"exactly at limit", 300, map[string]int{"a": 1, "b": 2, "c": 3}, []string{"a", "b", "c"}},
+		{"oldest first", 250, map[string]int{"a": 3, "b": 1, "c": 2}, []string{"a", "c"}},
+		{"several", 100, map[string]int{"a": 2, "b": 4, "c": 1, "d": 3}, []string{"b"}},
+		{"no limit", 0, map[string]int{"a": 1, "b": 2, "c": 3}, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ProgramCache{Dir: t.TempDir(), MaxBytes: tt.maxBytes}
+			for key, m := range tt.used {
+				storeAt(t, c, key, 88, m)
+			}
+			if err := c.Evict(); err != nil {
+				t.Fatal(err)
+			}
+			if got := cacheFiles(t, c); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("left %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgramCacheEvictIgnoresOtherFiles(t *testing.T) {
+	c := &ProgramCache{Dir: t.TempDir(), MaxBytes: 100}
+	storeAt(t, c, "a", 88, 1)
+	if err := os.WriteFile(filepath.Join(c.Dir, "notes.txt"), make([]byte, 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Evict(); err != nil {
+		t.Fatal(err)
+	}
+	if got := cacheFiles(t, c); strings.Join(got, ",") != "a,notes.txt" {
+		t.Errorf("left %v, want [a notes.txt]", got)
+	}
+}
+
+func TestProgramCacheReadWrite(t *testing.T) {
+	c := &ProgramCache{Dir: t.TempDir()}
+	if err := c.write("k", 0x8e21, []byte("binary")); err != nil {
+		t.Fatal(err)
+	}
+	format, data, err := c.read("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != 0x8e21 || string(data) != "binary" {
+		t.Errorf("read = %#x %q, want 0x8e21 \"binary\"", format, data)
+	}
+
+	b, _ := os.ReadFile(c.path("k"))
+	if err := os.WriteFile(c.path("k"), b[:len(b)-1], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.read("k"); err == nil {
+		t.Error("read of a truncated entry succeeded")
+	}
+}
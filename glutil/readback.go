@@ -0,0 +1,242 @@
+package glutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// ReadFormat is the kind of image a readback returns.
+type ReadFormat int
+
+const (
+	// ReadRGBA reads 8 bits per channel into an *image.RGBA, premultiplied
+	// as the image type expects.
+	ReadRGBA ReadFormat = iota
+	// ReadNRGBA64 reads 16 bits per channel into an *image.NRGBA64.
+	ReadNRGBA64
+	// ReadRGBA32F reads float32 channels into an *RGBA32F, unclamped.
+	ReadRGBA32F
+)
+
+// xtype returns the GL pixel type ReadFormat reads as.
+func (f ReadFormat) xtype() (uint32, error) {
+	switch f {
+	case ReadRGBA:
+		return gl.UNSIGNED_BYTE, nil
+	case ReadNRGBA64:
+		return gl.UNSIGNED_SHORT, nil
+	case ReadRGBA32F:
+		return gl.FLOAT, nil
+	}
+	return 0, fmt.Errorf("readback: unknown format %d", f)
+}
+
+// ReadFramebuffer reads rect of one buffer of framebuffer fbo: gl.BACK or
+// gl.FRONT of the default framebuffer 0, or a gl.COLOR_ATTACHMENTi or
+// gl.DEPTH_ATTACHMENT of a framebuffer object. rect is in framebuffer
+// pixels counted from the bottom-left, as for glReadPixels; the image
+// starts at its top row, as image files do. Depth is read into red, green
+// and blue with an opaque alpha. The framebuffer bindings and pack state
+// are restored afterwards.
+func ReadFramebuffer(fbo, buffer uint32, rect image.Rectangle, format ReadFormat) (image.Image, error) {
+	xtype, err := format.xtype()
+	if err != nil {
+		return nil, err
+	}
+	if rect.Empty() {
+		return nil, fmt.Errorf("readback: empty rectangle %v", rect)
+	}
+
+	var prevFBO, prevBuffer int32
+	gl.GetIntegerv(gl.READ_FRAMEBUFFER_BINDING, &prevFBO)
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, fbo)
+	defer gl.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(prevFBO))
+	if status := gl.CheckFramebufferStatus(gl.READ_FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		return nil, fmt.Errorf("readback: framebuffer %d is incomplete (0x%X)", fbo, status)
+	}
+
+	pixelFormat, channels := uint32(gl.RGBA), 4
+	if buffer == gl.DEPTH_ATTACHMENT {
+		pixelFormat, channels = gl.DEPTH_COMPONENT, 1
+	} else {
+		gl.GetIntegerv(gl.READ_BUFFER, &prevBuffer)
+		gl.ReadBuffer(buffer)
+		defer gl.ReadBuffer(uint32(prevBuffer))
+	}
+
+	w, h := rect.Dx(), rect.Dy()
+	data := make([]byte, w*h*channels*pixelBytes(xtype))
+	restore := packTightly()
+	defer restore()
+	clearErrors()
+	gl.ReadPixels(int32(rect.Min.X), int32(rect.Min.Y), int32(w), int32(h), pixelFormat, xtype, gl.Ptr(data))
+	if e := gl.GetError(); e != gl.NO_ERROR {
+		return nil, fmt.Errorf("readback: reading buffer 0x%X of framebuffer %d failed (0x%X)", buffer, fbo, e)
+	}
+	return readbackImage(data, w, h, channels, format), nil
+}
+
+// ReadTexture reads a level of texture into an image starting at its top
+// row. target is gl.TEXTURE_2D or a gl.TEXTURE_CUBE_MAP_* face. Compressed
+// textures are decompressed by the driver and depth textures are read as
+// for ReadFramebuffer. The texture binding and pack state are restored
+// afterwards.
+func ReadTexture(texture, target uint32, level int32, format ReadFormat) (image.Image, error) {
+	xtype, err := format.xtype()
+	if err != nil {
+		return nil, err
+	}
+	bindTarget, binding := target, uint32(gl.TEXTURE_BINDING_2D)
+	if target >= gl.TEXTURE_CUBE_MAP_POSITIVE_X && target <= gl.TEXTURE_CUBE_MAP_NEGATIVE_Z {
+		bindTarget, binding = gl.TEXTURE_CUBE_MAP, gl.TEXTURE_BINDING_CUBE_MAP
+	} else if target != gl.TEXTURE_2D {
+		return nil, fmt.Errorf("readback: unsupported texture target 0x%X", target)
+	}
+
+	var prev int32
+	gl.GetIntegerv(binding, &prev)
+	gl.BindTexture(bindTarget, texture)
+	defer gl.BindTexture(bindTarget, uint32(prev))
+
+	var w, h, depth int32
+	gl.GetTexLevelParameteriv(target, level, gl.TEXTURE_WIDTH, &w)
+	gl.GetTexLevelParameteriv(target, level, gl.TEXTURE_HEIGHT, &h)
+	gl.GetTexLevelParameteriv(target, level, gl.TEXTURE_DEPTH_SIZE, &depth)
+	if w == 0 || h == 0 {
+		return nil, fmt.Errorf("readback: texture %d has no level %d", texture, level)
+	}
+	pixelFormat, channels := uint32(gl.RGBA), 4
+	if depth > 0 {
+		pixelFormat, channels = gl.DEPTH_COMPONENT, 1
+	}
+
+	data := make([]byte, int(w)*int(h)*channels*pixelBytes(xtype))
+	restore := packTightly()
+	defer restore()
+	clearErrors()
+	gl.GetTexImage(target, level, pixelFormat, xtype, gl.Ptr(data))
+	if e := gl.GetError(); e != gl.NO_ERROR {
+		return nil, fmt.Errorf("readback: reading level %d of texture %d failed (0x%X)", level, texture, e)
+	}
+	return readbackImage(data, int(w), int(h), channels, format), nil
+}
+
+// packTightly sets the pack state so rows are read without padding and
+// into client memory, and returns a func restoring it.
+func packTightly() func() {
+	var alignment, rowLength, pbo int32
+	gl.GetIntegerv(gl.PACK_ALIGNMENT, &alignment)
+	gl.GetIntegerv(gl.PACK_ROW_LENGTH, &rowLength)
+	gl.GetIntegerv(gl.PIXEL_PACK_BUFFER_BINDING, &pbo)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.PixelStorei(gl.PACK_ROW_LENGTH, 0)
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+	return func() {
+		gl.PixelStorei(gl.PACK_ALIGNMENT, alignment)
+		gl.PixelStorei(gl.PACK_ROW_LENGTH, rowLength)
+		gl.BindBuffer(gl.PIXEL_PACK_BUFFER, uint32(pbo))
+	}
+}
+
+// clearErrors discards errors left by earlier calls, so GetError after
+// the next call reports only its own.
+func clearErrors() {
+	for i := 0; i < 16 && gl.GetError() != gl.NO_ERROR; i++ {
+	}
+}
+
+// hostByteOrder is the byte order of this machine, in which GL reads and
+// writes multi-byte pixel values in client memory.
+var hostByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 0 {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}()
+
+func pixelBytes(xtype uint32) int {
+	switch xtype {
+	case gl.UNSIGNED_SHORT:
+		return 2
+	case gl.FLOAT:
+		return 4
+	}
+	return 1
+}
+
+// readbackImage converts tightly packed pixels read from GL, bottom row
+// first, with 1 or 4 channels in host byte order, into an image of format
+// starting at the top row. One channel is copied to red, green and blue
+// with an opaque alpha.
+func readbackImage(data []byte, w, h, channels int, format ReadFormat) image.Image {
+	rect := image.Rect(0, 0, w, h)
+	switch format {
+	case ReadNRGBA64:
+		img := image.NewNRGBA64(rect)
+		for y := 0; y < h; y++ {
+			src := data[(h-1-y)*w*channels*2:]
+			row := img.Pix[y*img.Stride:]
+			for x := 0; x < w; x++ {
+				for c := 0; c < 4; c++ {
+					v := uint16(0xffff)
+					if c < 3 || channels == 4 {
+						v = hostByteOrder.Uint16(src[2*channelIndex(x, c, channels):])
+					}
+					binary.BigEndian.PutUint16(row[8*x+2*c:], v)
+				}
+			}
+		}
+		return img
+	case ReadRGBA32F:
+		img := NewRGBA32F(rect)
+		for y := 0; y < h; y++ {
+			src := data[(h-1-y)*w*channels*4:]
+			row := img.Pix[y*img.Stride:]
+			for x := 0; x < w; x++ {
+				for c := 0; c < 4; c++ {
+					v := float32(1)
+					if c < 3 || channels == 4 {
+						v = math.Float32frombits(hostByteOrder.Uint32(src[4*channelIndex(x, c, channels):]))
+					}
+					row[4*x+c] = v
+				}
+			}
+		}
+		return img
+	}
+	img := image.NewRGBA(rect)
+	for y := 0; y < h; y++ {
+		src := data[(h-1-y)*w*channels:]
+		row := img.Pix[y*img.Stride:]
+		for x := 0; x < w; x++ {
+			p := row[4*x : 4*x+4]
+			if channels == 1 {
+				v := src[x]
+				p[0], p[1], p[2], p[3] = v, v, v, 0xff
+				continue
+			}
+			copy(p, src[4*x:4*x+4])
+			if a := uint32(p[3]); a != 0xff {
+				for c := 0; c < 3; c++ {
+					p[c] = uint8((uint32(p[c])*a + 127) / 0xff)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// channelIndex returns the index of channel c of pixel x in a row of
+// 1 or 4 channel pixels, using the only channel for red, green and blue.
+func channelIndex(x, c, channels int) int {
+	if channels == 1 {
+		return x
+	}
+	return 4*x + c
+}
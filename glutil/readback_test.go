@@ -0,0 +1,140 @@
+package glutil
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// hostUint16s packs values as GL writes them to client memory.
+func hostUint16s(v ...uint16) []byte {
+	b := make([]byte, 2*len(v))
+	for i, x := range v {
+		hostByteOrder.PutUint16(b[2*i:], x)
+	}
+	return b
+}
+
+func hostFloat32s(v ...float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, x := range v {
+		hostByteOrder.PutUint32(b[4*i:], math.Float32bits(x))
+	}
+	return b
+}
+
+func TestReadbackImage(t *testing.T) {
+	rect := image.Rect(0, 0, 2, 2)
+	// rgba, nrgba64 and rgba32f build 2x2 images from pixels listed top
+	// row first.
+	rgba := func(p ...color.RGBA) *image.RGBA {
+		img := image.NewRGBA(rect)
+		for i, c := range p {
+			img.SetRGBA(i%2, i/2, c)
+		}
+		return img
+	}
+	nrgba64 := func(p ...color.NRGBA64) *image.NRGBA64 {
+		img := image.NewNRGBA64(rect)
+		for i, c := range p {
+			img.SetNRGBA64(i%2, i/2, c)
+		}
+		return img
+	}
+	rgba32f := func(p ...[4]float32) *RGBA32F {
+		img := NewRGBA32F(rect)
+		for i, c := range p {
+			img.SetRGBA32F(i%2, i/2, c)
+		}
+		return img
+	}
+
+	// The data is bottom row first, as GL returns it.
+	tests := []struct {
+		name     string
+		data     []byte
+		channels int
+		format   ReadFormat
+		want     image.Image
+	}{
+		{
+			name: "RGBA",
+			data: []byte{
+				1, 2, 3, 255, 200, 100, 0, 128,
+				4, 5, 6, 255, 9, 9, 9, 0,
+			},
+			channels: 4,
+			format:   ReadRGBA,
+			want: rgba(
+				color.RGBA{4, 5, 6, 255}, color.RGBA{0, 0, 0, 0},
+				color.RGBA{1, 2, 3, 255}, color.RGBA{100, 50, 0, 128},
+			),
+		},
+		{
+			name:     "RGBA depth",
+			data:     []byte{10, 20, 30, 40},
+			channels: 1,
+			format:   ReadRGBA,
+			want: rgba(
+				color.RGBA{30, 30, 30, 255}, color.RGBA{40, 40, 40, 255},
+				color.RGBA{10, 10, 10, 255}, color.RGBA{20, 20, 20, 255},
+			),
+		},
+		{
+			name: "NRGBA64",
+			data: hostUint16s(
+				0x0102, 0x0304, 0x0506, 0xffff, 0xa000, 0x000b, 0x0c00, 0x8000,
+				0x1111, 0x2222, 0x3333, 0x4444, 0xfedc, 0xba98, 0x7654, 0x3210,
+			),
+			channels: 4,
+			format:   ReadNRGBA64,
+			want: nrgba64(
+				color.NRGBA64{0x1111, 0x2222, 0x3333, 0x4444}, color.NRGBA64{0xfedc, 0xba98, 0x7654, 0x3210},
+				color.NRGBA64{0x0102, 0x0304, 0x0506, 0xffff}, color.NRGBA64{0xa000, 0x000b, 0x0c00, 0x8000},
+			),
+		},
+		{
+			name:     "NRGBA64 depth",
+			data:     hostUint16s(0x0001, 0x0100, 0x8000, 0xffff),
+			channels: 1,
+			format:   ReadNRGBA64,
+			want: nrgba64(
+				color.NRGBA64{0x8000, 0x8000, 0x8000, 0xffff}, color.NRGBA64{0xffff, 0xffff, 0xffff, 0xffff},
+				color.NRGBA64{0x0001, 0x0001, 0x0001, 0xffff}, color.NRGBA64{0x0100, 0x0100, 0x0100, 0xffff},
+			),
+		},
+		{
+			name: "RGBA32F",
+			data: hostFloat32s(
+				0, 0.5, 1, 1, -2, 3.25, 100, 0.125,
+				1e-3, 2e3, -0.75, 0, 0.25, 0.25, 0.25, 0.5,
+			),
+			channels: 4,
+			format:   ReadRGBA32F,
+			want: rgba32f(
+				[4]float32{1e-3, 2e3, -0.75, 0}, [4]float32{0.25, 0.25, 0.25, 0.5},
+				[4]float32{0, 0.5, 1, 1}, [4]float32{-2, 3.25, 100, 0.125},
+			),
+		},
+		{
+			name:     "RGBA32F depth",
+			data:     hostFloat32s(0, 0.25, 0.75, 1),
+			channels: 1,
+			format:   ReadRGBA32F,
+			want: rgba32f(
+				[4]float32{0.75, 0.75, 0.75, 1}, [4]float32{1, 1, 1, 1},
+				[4]float32{0, 0, 0, 1}, [4]float32{0.25, 0.25, 0.25, 1},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readbackImage(tt.data, 2, 2, tt.channels, tt.format)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readbackImage = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package glutil
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Variable is an active uniform or vertex attribute of a linked program.
+type Variable struct {
+	Name     string
+	Type     uint32 // e.g. gl.FLOAT_MAT4
+	Size     int32  // array length, 1 for non-arrays
+	Location int32
+}
+
+// TypeName returns the GLSL name of the variable's type, e.g. "mat4".
+func (v Variable) TypeName() string {
+	if t, ok := glslTypes[v.Type]; ok {
+		return t.name
+	}
+	return fmt.Sprintf("0x%x", v.Type)
+}
+
+func (v Variable) String() string {
+	if v.Size > 1 {
+		return fmt.Sprintf("%s %s[%d] (location %d)", v.TypeName(), strings.TrimSuffix(v.Name, "[0]"), v.Size, v.Location)
+	}
+	return fmt.Sprintf("%s %s (location %d)", v.TypeName(), v.Name, v.Location)
+}
+
+// Block is an active uniform block of a linked program.
+type Block struct {
+	Name    string
+	Index   uint32
+	Size    int32 // GL_UNIFORM_BLOCK_DATA_SIZE in bytes
+	Binding uint32
+}
+
+// Program wraps a linked program object with its active uniforms and
+// attributes. Its setters look locations up once, check the Go value
+// against the GLSL type, and log a warning the first time a name is
+// unknown or has the wrong type instead of silently writing to -1.
+//
+// The setters use glProgramUniform, so the program does not need to be
+// bound.
+type Program struct {
+	ID         uint32
+	Uniforms   []Variable
+	Attributes []Variable
+	Blocks     []Block
+
+	uniforms   map[string]Variable
+	attributes map[string]Variable
+	set        map[string]bool
+	warned     map[string]bool
+}
+
+// Reflect enumerates the active uniforms and attributes of a linked
+// program.
+func Reflect(program uint32) *Program {
+	p := &Program{}
+	p.reflect(program)
+	return p
+}
+
+func (p *Program) reflect(program uint32) {
+	p.ID = program
+	p.Uniforms = activeUniforms(program)
+	p.Attributes = activeAttributes(program)
+	p.Blocks = activeBlocks(program)
+	p.uniforms = map[string]Variable{}
+	p.attributes = map[string]Variable{}
+	p.warned = map[string]bool{}
+	if p.set == nil {
+		p.set = map[string]bool{}
+	}
+	for _, u := range p.Uniforms {
+		p.uniforms[u.Name] = u
+		// Arrays are reported as "name[0]"; accept the bare name too.
+		if base := strings.TrimSuffix(u.Name, "[0]"); base != u.Name {
+			p.uniforms[base] = u
+		}
+	}
+	for _, a := range p.Attributes {
+		p.attributes[a.Name] = a
+	}
+}
+
+// Block returns the active uniform block called name.
+func (p *Program) Block(name string) (Block, bool) {
+	for _, b := range p.Blocks {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return Block{}, false
+}
+
+// Delete deletes the program object.
+func (p *Program) Delete() {
+	gl.DeleteProgram(p.ID)
+}
+
+// Use binds the program.
+func (p *Program) Use() {
+	gl.UseProgram(p.ID)
+}
+
+// Uniform returns the active uniform called name. Elements of uniform
+// arrays, e.g. "lights[2]", are looked up on first use.
+func (p *Program) Uniform(name string) (Variable, bool) {
+	if u, ok := p.uniforms[name]; ok {
+		return u, true
+	}
+	i := strings.IndexByte(name, '[')
+	if i < 0 {
+		return Variable{}, false
+	}
+	array, ok := p.uniforms[name[:i]]
+	if !ok {
+		return Variable{}, false
+	}
+	loc := gl.GetUniformLocation(p.ID, gl.Str(name+"\x00"))
+	if loc < 0 {
+		return Variable{}, false
+	}
+	u := Variable{Name: name, Type: array.Type, Size: 1, Location: loc}
+	p.uniforms[name] = u
+	return u, true
+}
+
+// Attrib returns the location of the active attribute called name, or -1
+// with a warning if the program has no such attribute.
+func (p *Program) Attrib(name string) int32 {
+	a, ok := p.attributes[name]
+	if !ok {
+		p.warn(name, "attribute %q is not active in program %d (misspelled or optimised out)", name, p.ID)
+		return -1
+	}
+	return a.Location
+}
+
+// VertexAttrib is VertexAttrib for the program's attribute called name. An
+// unknown name is reported and skipped rather than enabling attribute -1.
+func (p *Program) VertexAttrib(name string, size, stride, offset int) {
+	attrib := p.Attrib(name)
+	if attrib < 0 {
+		return
+	}
+	gl.EnableVertexAttribArray(uint32(attrib))
+	gl.VertexAttribPointer(uint32(attrib), int32(size), gl.FLOAT, false, int32(stride*4), gl.PtrOffset(offset*4))
+}
+
+// Unset returns the active uniforms that no setter has written to, other
+// than samplers, which default to texture unit 0, and members of uniform
+// blocks, which are filled from buffers.
+func (p *Program) Unset() []string {
+	var names []string
+	for _, u := range p.Uniforms {
+		if glslTypes[u.Type].kind == kindSampler || u.Location < 0 || p.set[u.Name] {
+			continue
+		}
+		names = append(names, u.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check logs the uniforms Unset returns, once per program. Call it after
+// the program has drawn its first frame, when everything it reads should
+// have been set.
+func (p *Program) Check() {
+	if names := p.Unset(); len(names) > 0 {
+		p.warn(" unset", "program %d: uniforms never set: %s", p.ID, strings.Join(names, ", "))
+	}
+}
+
+// SetFloat sets a float uniform.
+func (p *Program) SetFloat(name string, v float32) {
+	if loc := p.location(name, "SetFloat", gl.FLOAT); loc >= 0 {
+		gl.ProgramUniform1f(p.ID, loc, v)
+	}
+}
+
+// SetVec2 sets a vec2 uniform.
+func (p *Program) SetVec2(name string, v mgl32.Vec2) {
+	if loc := p.location(name, "SetVec2", gl.FLOAT_VEC2); loc >= 0 {
+		gl.ProgramUniform2f(p.ID, loc, v[0], v[1])
+	}
+}
+
+// SetVec3 sets a vec3 uniform.
+func (p *Program) SetVec3(name string, v mgl32.Vec3) {
+	if loc := p.location(name, "SetVec3", gl.FLOAT_VEC3); loc >= 0 {
+		gl.ProgramUniform3f(p.ID, loc, v[0], v[1], v[2])
+	}
+}
+
+// SetVec4 sets a vec4 uniform.
+func (p *Program) SetVec4(name string, v mgl32.Vec4) {
+	if loc := p.location(name, "SetVec4", gl.FLOAT_VEC4); loc >= 0 {
+		gl.ProgramUniform4f(p.ID, loc, v[0], v[1], v[2], v[3])
+	}
+}
+
+// SetMat3 sets a mat3 uniform.
+func (p *Program) SetMat3(name string, m mgl32.Mat3) {
+	if loc := p.location(name, "SetMat3", gl.FLOAT_MAT3); loc >= 0 {
+		gl.ProgramUniformMatrix3fv(p.ID, loc, 1, false, &m[0])
+	}
+}
+
+// SetMat4 sets a mat4 uniform.
+func (p *Program) SetMat4(name string, m mgl32.Mat4) {
+	if loc := p.location(name, "SetMat4", gl.FLOAT_MAT4); loc >= 0 {
+		gl.ProgramUniformMatrix4fv(p.ID, loc, 1, false, &m[0])
+	}
+}
+
+// SetInt sets an int uniform, or the texture unit of a sampler.
+func (p *Program) SetInt(name string, v int32) {
+	if loc := p.location(name, "SetInt", gl.INT); loc >= 0 {
+		gl.ProgramUniform1i(p.ID, loc, v)
+	}
+}
+
+// SetUint sets a uint uniform.
+func (p *Program) SetUint(name string, v uint32) {
+	if loc := p.location(name, "SetUint", gl.UNSIGNED_INT); loc >= 0 {
+		gl.ProgramUniform1ui(p.ID, loc, v)
+	}
+}
+
+// SetBool sets a bool uniform.
+func (p *Program) SetBool(name string, v bool) {
+	var i int32
+	if v {
+		i = 1
+	}
+	if loc := p.location(name, "SetBool", gl.BOOL); loc >= 0 {
+		gl.ProgramUniform1i(p.ID, loc, i)
+	}
+}
+
+// location returns the location of the uniform called name if its type is
+// want, and -1 after warning otherwise. SetInt also accepts samplers.
+func (p *Program) location(name, setter string, want uint32) int32 {
+	u, ok := p.Uniform(name)
+	if !ok {
+		p.warn(name, "%s: uniform %q is not active in program %d (misspelled or optimised out)", setter, name, p.ID)
+		return -1
+	}
+	if u.Type != want && !(want == gl.INT && glslTypes[u.Type].kind == kindSampler) {
+		p.warn(name, "%s: uniform %q in program %d is a %s", setter, name, p.ID, u.TypeName())
+		return -1
+	}
+	if u.Location < 0 {
+		p.warn(name, "%s: uniform %q in program %d is in a uniform block", setter, name, p.ID)
+		return -1
+	}
+	p.set[strings.TrimSuffix(u.Name, "[0]")] = true
+	p.set[u.Name] = true
+	return u.Location
+}
+
+// warn logs a problem with name once per program.
+func (p *Program) warn(name, format string, args ...interface{}) {
+	if p.warned[name] {
+		return
+	}
+	p.warned[name] = true
+	log.Printf(format, args...)
+}
+
+func activeUniforms(program uint32) []Variable {
+	return activeVariables(program, gl.ACTIVE_UNIFORMS, gl.ACTIVE_UNIFORM_MAX_LENGTH, gl.GetActiveUniform, gl.GetUniformLocation)
+}
+
+func activeAttributes(program uint32) []Variable {
+	return activeVariables(program, gl.ACTIVE_ATTRIBUTES, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH, gl.GetActiveAttrib, gl.GetAttribLocation)
+}
+
+func activeVariables(program, count, maxLength uint32,
+	getActive func(program, index uint32, bufSize int32, length, size *int32, xtype *uint32, name *uint8),
+	getLocation func(program uint32, name *uint8) int32) []Variable {
+
+	var n, max int32
+	gl.GetProgramiv(program, count, &n)
+	gl.GetProgramiv(program, maxLength, &max)
+
+	vars := make([]Variable, 0, n)
+	buf := make([]uint8, max+1)
+	for i := int32(0); i < n; i++ {
+		var length, size int32
+		var xtype uint32
+		getActive(program, uint32(i), max+1, &length, &size, &xtype, &buf[0])
+		name := string(buf[:length])
+		vars = append(vars, Variable{
+			Name:     name,
+			Type:     xtype,
+			Size:     size,
+			Location: getLocation(program, gl.Str(name+"\x00")),
+		})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+func activeBlocks(program uint32) []Block {
+	var n, max int32
+	gl.GetProgramiv(program, gl.ACTIVE_UNIFORM_BLOCKS, &n)
+	gl.GetProgramiv(program, gl.ACTIVE_UNIFORM_BLOCK_MAX_NAME_LENGTH, &max)
+
+	blocks := make([]Block, 0, n)
+	buf := make([]uint8, max+1)
+	for i := uint32(0); i < uint32(n); i++ {
+		var length, size, binding int32
+		gl.GetActiveUniformBlockName(program, i, max+1, &length, &buf[0])
+		gl.GetActiveUniformBlockiv(program, i, gl.UNIFORM_BLOCK_DATA_SIZE, &size)
+		gl.GetActiveUniformBlockiv(program, i, gl.UNIFORM_BLOCK_BINDING, &binding)
+		blocks = append(blocks, Block{
+			Name:    string(buf[:length]),
+			Index:   i,
+			Size:    size,
+			Binding: uint32(binding),
+		})
+	}
+	return blocks
+}
@@ -0,0 +1,49 @@
+package glutil
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Resources records how to free GL objects and frees them in the reverse
+// order they were added, so objects are released before the ones they
+// were built from. The zero value is ready to use.
+type Resources struct {
+	frees []func()
+}
+
+// Add registers free to be called by Free.
+func (r *Resources) Add(free func()) {
+	r.frees = append(r.frees, free)
+}
+
+// Program registers program for deletion and returns it.
+func (r *Resources) Program(program uint32) uint32 {
+	r.Add(func() { gl.DeleteProgram(program) })
+	return program
+}
+
+// Texture registers texture for deletion and returns it.
+func (r *Resources) Texture(texture uint32) uint32 {
+	r.Add(func() { gl.DeleteTextures(1, &texture) })
+	return texture
+}
+
+// Buffer registers buffer for deletion and returns it.
+func (r *Resources) Buffer(buffer uint32) uint32 {
+	r.Add(func() { gl.DeleteBuffers(1, &buffer) })
+	return buffer
+}
+
+// VertexArray registers vao for deletion and returns it.
+func (r *Resources) VertexArray(vao uint32) uint32 {
+	r.Add(func() { gl.DeleteVertexArrays(1, &vao) })
+	return vao
+}
+
+// Free releases everything added so far, most recent first, and empties r.
+func (r *Resources) Free() {
+	for i := len(r.frees) - 1; i >= 0; i-- {
+		r.frees[i]()
+	}
+	r.frees = nil
+}
@@ -0,0 +1,183 @@
+package glutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// excerptContext is the number of source lines shown either side of the
+// line a diagnostic points at.
+const excerptContext = 2
+
+// ShaderDiagnostic is one message parsed from a shader info log.
+type ShaderDiagnostic struct {
+	Stage    string // "vertex", "fragment", ...
+	File     string // source string number as reported by the driver
+	Line     int    // 1-based, 0 if the driver gave none
+	Column   int    // 1-based, 0 if the driver gave none
+	Severity string // "error" or "warning"
+	Message  string
+}
+
+func (d ShaderDiagnostic) String() string {
+	pos := d.File
+	if d.Line > 0 {
+		pos += ":" + strconv.Itoa(d.Line)
+		if d.Column > 0 {
+			pos += ":" + strconv.Itoa(d.Column)
+		}
+	}
+	return fmt.Sprintf("%s shader %s: %s: %s", d.Stage, pos, d.Severity, d.Message)
+}
+
+// ShaderError is returned when a shader stage fails to compile. Error
+// prints every diagnostic followed by the source lines around it.
+type ShaderError struct {
+	Stage       string
+	Source      string
+	Log         string
+	Diagnostics []ShaderDiagnostic
+
+	// Files, when set, are the files Source was preprocessed from, indexed
+	// by the source string numbers in its #line directives.
+	Files []ShaderFile
+}
+
+func (e *ShaderError) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return fmt.Sprintf("failed to compile %s shader: %s", e.Stage, strings.TrimSpace(e.Log))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to compile %s shader:", e.Stage)
+	for _, d := range e.Diagnostics {
+		source := e.Source
+		if i, ok := fileIndex(d.File, e.Files); ok {
+			d.File = e.Files[i].Name
+			source = e.Files[i].Text
+		}
+		b.WriteString("\n")
+		b.WriteString(d.String())
+		if excerpt := SourceExcerpt(source, d.Line, d.Column, excerptContext); excerpt != "" {
+			b.WriteString("\n")
+			b.WriteString(excerpt)
+		}
+	}
+	return b.String()
+}
+
+var (
+	// Mesa: 0:12(5): error: `foo' undeclared
+	mesaLog = regexp.MustCompile(`^(\d+):(\d+)\((\d+)\): (error|warning): (.*)$`)
+	// NVIDIA: 0(12) : error C1008: undefined variable "foo"
+	nvidiaLog = regexp.MustCompile(`^(\d+)\((\d+)\) : (error|warning) ?(?:[A-Z]\d+)?: (.*)$`)
+	// AMD, Intel on Windows and Apple: ERROR: 0:12: 'foo' : undeclared identifier
+	amdLog = regexp.MustCompile(`^(ERROR|WARNING): (\d+):(\d+): (.*)$`)
+)
+
+// ParseShaderLog splits a shader info log into diagnostics. It understands
+// the Mesa, NVIDIA and AMD log formats. Lines in none of them, such as
+// summary lines, are skipped.
+func ParseShaderLog(stage, log string) []ShaderDiagnostic {
+	var diags []ShaderDiagnostic
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\x00"))
+		if line == "" {
+			continue
+		}
+
+		d := ShaderDiagnostic{Stage: stage}
+		if m := mesaLog.FindStringSubmatch(line); m != nil {
+			d.File = m[1]
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+			d.Severity = m[4]
+			d.Message = m[5]
+		} else if m := nvidiaLog.FindStringSubmatch(line); m != nil {
+			d.File = m[1]
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Severity = m[3]
+			d.Message = m[4]
+		} else if m := amdLog.FindStringSubmatch(line); m != nil {
+			d.Severity = strings.ToLower(m[1])
+			d.File = m[2]
+			d.Line, _ = strconv.Atoi(m[3])
+			d.Message = m[4]
+		} else {
+			continue
+		}
+		d.Message = strings.TrimSpace(d.Message)
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// SourceExcerpt returns the lines of source around line, numbered and with
+// the line itself marked. When column is known a caret points at it.
+func SourceExcerpt(source string, line, column, context int) string {
+	lines := strings.Split(strings.TrimSuffix(strings.TrimRight(source, "\x00"), "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	first, last := line-context, line+context
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		marker := "  "
+		if n == line {
+			marker = "> "
+		}
+		fmt.Fprintf(&b, "%s%*d | %s\n", marker, width, n, lines[n-1])
+		if n == line && column > 0 {
+			fmt.Fprintf(&b, "  %*s | %s^\n", width, "", caretPadding(lines[n-1], column))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// caretPadding returns the whitespace that puts a caret under column,
+// keeping tabs so it lines up however they are displayed.
+func caretPadding(line string, column int) string {
+	var pad strings.Builder
+	for i, r := range line {
+		if i >= column-1 {
+			break
+		}
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	return pad.String()
+}
+
+// StageName returns the lower case name of a shader type such as
+// gl.VERTEX_SHADER.
+func StageName(shaderType uint32) string {
+	switch shaderType {
+	case gl.VERTEX_SHADER:
+		return "vertex"
+	case gl.FRAGMENT_SHADER:
+		return "fragment"
+	case gl.GEOMETRY_SHADER:
+		return "geometry"
+	case gl.TESS_CONTROL_SHADER:
+		return "tessellation control"
+	case gl.TESS_EVALUATION_SHADER:
+		return "tessellation evaluation"
+	}
+	return fmt.Sprintf("0x%x", shaderType)
+}
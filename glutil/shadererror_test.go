@@ -0,0 +1,114 @@
+package glutil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseShaderLog(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want []ShaderDiagnostic
+	}{
+		{
+			name: "mesa",
+			log: "0:12(5): error: `colour' undeclared\n" +
+				"0:14(22): warning: `unused' declared but never used\n" +
+				"0:12(5): error: type mismatch\x00",
+			want: []ShaderDiagnostic{
+				{Stage: "fragment", File: "0", Line: 12, Column: 5, Severity: "error", Message: "`colour' undeclared"},
+				{Stage: "fragment", File: "0", Line: 14, Column: 22, Severity: "warning", Message: "`unused' declared but never used"},
+				{Stage: "fragment", File: "0", Line: 12, Column: 5, Severity: "error", Message: "type mismatch"},
+			},
+		},
+		{
+			name: "nvidia",
+			log: "1(7) : error C1008: undefined variable \"colour\"\n" +
+				"0(3) : warning C7022: unrecognized profile specifier \"precision\"\n",
+			want: []ShaderDiagnostic{
+				{Stage: "fragment", File: "1", Line: 7, Severity: "error", Message: "undefined variable \"colour\""},
+				{Stage: "fragment", File: "0", Line: 3, Severity: "warning", Message: "unrecognized profile specifier \"precision\""},
+			},
+		},
+		{
+			name: "amd",
+			log: "ERROR: 0:9: 'colour' : undeclared identifier \n" +
+				"WARNING: 2:30: 'f' : implicit conversion\n" +
+				"ERROR: 2 compilation errors.  No code generated.\n",
+			want: []ShaderDiagnostic{
+				{Stage: "fragment", File: "0", Line: 9, Severity: "error", Message: "'colour' : undeclared identifier"},
+				{Stage: "fragment", File: "2", Line: 30, Severity: "warning", Message: "'f' : implicit conversion"},
+			},
+		},
+		{
+			name: "unknown lines",
+			log:  "Fragment info\n-------------\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseShaderLog("fragment", tt.log)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseShaderLog:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceExcerpt(t *testing.T) {
+	source := "#version 410 core\nin vec2 uv;\nout vec4 colour;\n\tvoid main() {\n\tcolour = texture(tex, uv);\n}\n"
+	tests := []struct {
+		name                  string
+		line, column, context int
+		want                  string
+	}{
+		{
+			name: "middle", line: 3, context: 1,
+			want: "  2 | in vec2 uv;\n" +
+				"> 3 | out vec4 colour;\n" +
+				"  4 | \tvoid main() {",
+		},
+		{
+			name: "caret keeps tabs", line: 5, column: 11, context: 0,
+			want: "> 5 | \tcolour = texture(tex, uv);\n" +
+				"    | \t         ^",
+		},
+		{
+			name: "clipped at start", line: 1, context: 2,
+			want: "> 1 | #version 410 core\n" +
+				"  2 | in vec2 uv;\n" +
+				"  3 | out vec4 colour;",
+		},
+		{name: "past end", line: 7, context: 2},
+		{name: "no line", line: 0, context: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SourceExcerpt(source, tt.line, tt.column, tt.context)
+			if got != tt.want {
+				t.Errorf("SourceExcerpt:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShaderErrorUsesFiles(t *testing.T) {
+	e := &ShaderError{
+		Stage:  "fragment",
+		Source: "#line 1 1\nvoid main() {}\n",
+		Log:    "0:2(1): error: syntax error\n",
+		Files: []ShaderFile{
+			{Name: "main.frag", Text: "void main() {}\n"},
+			{Name: "lib.glsl", Text: "float f()\n{\n"},
+		},
+		Diagnostics: []ShaderDiagnostic{{Stage: "fragment", File: "1", Line: 2, Column: 1, Severity: "error", Message: "syntax error"}},
+	}
+	got := e.Error()
+	for _, want := range []string{"fragment shader lib.glsl:2:1: error: syntax error", "> 2 | {"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Error() = %q, missing %q", got, want)
+		}
+	}
+}
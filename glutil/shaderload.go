@@ -0,0 +1,223 @@
+package glutil
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// ShaderFile is one file that went into a ShaderSource.
+type ShaderFile struct {
+	Name string
+	Text string
+}
+
+// ShaderSource is preprocessed shader code. Code carries #line directives
+// whose source string numbers index Files, so driver messages can be
+// mapped back to the file they came from.
+type ShaderSource struct {
+	Code  string
+	Files []ShaderFile
+}
+
+// ShaderLoader reads shader sources from a file system, such as
+// os.DirFS("shaders") or an embed.FS, resolving #include directives and
+// injecting Defines after the #version line.
+//
+// Included paths are relative to the including file:
+//
+//	#include "lighting.glsl"
+//
+// Programs built through the loader are looked up in and added to Cache
+// when it is set. With a Dialect, each stage is rewritten for it, and
+// fragment outputs named in FragData get those locations.
+type ShaderLoader struct {
+	FS       fs.FS
+	Defines  map[string]string
+	Cache    *ProgramCache
+	Dialect  *Dialect
+	FragData map[string]int
+}
+
+// NewShaderLoader returns a loader reading from fsys with no defines,
+// caching programs in DefaultProgramCache.
+func NewShaderLoader(fsys fs.FS) *ShaderLoader {
+	return &ShaderLoader{FS: fsys, Cache: DefaultProgramCache}
+}
+
+// Load reads and preprocesses the named shader.
+func (l *ShaderLoader) Load(name string) (*ShaderSource, error) {
+	p := &preprocessor{fsys: l.FS, index: map[string]int{}}
+	var b strings.Builder
+	if err := p.expand(&b, name, nil, l.Defines); err != nil {
+		return nil, err
+	}
+	return &ShaderSource{Code: b.String(), Files: p.files}, nil
+}
+
+// LoadStage loads the named shader and rewrites it for l.Dialect, if set,
+// as a shader of the given type.
+func (l *ShaderLoader) LoadStage(shaderType uint32, name string) (*ShaderSource, error) {
+	source, err := l.Load(name)
+	if err != nil || l.Dialect == nil {
+		return source, err
+	}
+	code, err := l.Dialect.Rewrite(shaderType, source.Code, l.FragData)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	source.Code = code
+	return source, nil
+}
+
+// NewProgram loads, compiles and links a vertex and a fragment shader.
+func (l *ShaderLoader) NewProgram(vertexFile, fragmentFile string) (uint32, error) {
+	return new(ProgramBuilder).
+		File(l, gl.VERTEX_SHADER, vertexFile).
+		File(l, gl.FRAGMENT_SHADER, fragmentFile).
+		Link()
+}
+
+// Compile compiles s as a shader of the given type. A *ShaderError from it
+// reports positions in the original files.
+func (s *ShaderSource) Compile(shaderType uint32) (uint32, error) {
+	shader, err := CompileShader(s.Code, shaderType)
+	if serr, ok := err.(*ShaderError); ok {
+		serr.Files = s.Files
+	}
+	return shader, err
+}
+
+// ShaderType guesses the shader type from a file extension: .vert, .frag,
+// .geom, .tesc or .tese.
+func ShaderType(name string) (uint32, bool) {
+	switch path.Ext(name) {
+	case ".vert":
+		return gl.VERTEX_SHADER, true
+	case ".frag":
+		return gl.FRAGMENT_SHADER, true
+	case ".geom":
+		return gl.GEOMETRY_SHADER, true
+	case ".tesc":
+		return gl.TESS_CONTROL_SHADER, true
+	case ".tese":
+		return gl.TESS_EVALUATION_SHADER, true
+	}
+	return 0, false
+}
+
+type preprocessor struct {
+	fsys  fs.FS
+	files []ShaderFile
+	index map[string]int
+}
+
+// expand writes name to b, following includes. stack holds the files
+// being expanded, outermost first, to detect include cycles.
+func (p *preprocessor) expand(b *strings.Builder, name string, stack []string, defines map[string]string) error {
+	for _, s := range stack {
+		if s == name {
+			return fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), name)
+		}
+	}
+	stack = append(stack, name)
+
+	data, err := fs.ReadFile(p.fsys, name)
+	if err != nil {
+		return err
+	}
+	text := strings.TrimRight(string(data), "\x00")
+
+	id, ok := p.index[name]
+	if !ok {
+		id = len(p.files)
+		p.index[name] = id
+		p.files = append(p.files, ShaderFile{Name: name, Text: text})
+	}
+
+	versionLine := 0
+	if len(stack) == 1 {
+		versionLine = findVersion(text)
+		if versionLine == 0 {
+			writeDefines(b, defines)
+			fmt.Fprintf(b, "#line 1 %d\n", id)
+		}
+	} else {
+		fmt.Fprintf(b, "#line 1 %d\n", id)
+	}
+
+	lineNo := 0
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNo++
+		directive := strings.TrimSpace(line)
+
+		switch {
+		case lineNo == versionLine:
+			b.WriteString(line + "\n")
+			writeDefines(b, defines)
+			fmt.Fprintf(b, "#line %d %d\n", lineNo+1, id)
+		case strings.HasPrefix(directive, "#include"):
+			include, err := includeName(directive)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %v", name, lineNo, err)
+			}
+			if err := p.expand(b, path.Join(path.Dir(name), include), stack, nil); err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "#line %d %d\n", lineNo+1, id)
+		default:
+			b.WriteString(line + "\n")
+		}
+	}
+	return scanner.Err()
+}
+
+// findVersion returns the 1-based line of the #version directive, or 0.
+func findVersion(text string) int {
+	for i, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#version") {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func includeName(directive string) (string, error) {
+	arg := strings.TrimSpace(strings.TrimPrefix(directive, "#include"))
+	if len(arg) < 2 || !(arg[0] == '"' && arg[len(arg)-1] == '"' || arg[0] == '<' && arg[len(arg)-1] == '>') {
+		return "", fmt.Errorf("malformed #include %s", arg)
+	}
+	return arg[1 : len(arg)-1], nil
+}
+
+func writeDefines(b *strings.Builder, defines map[string]string) {
+	names := make([]string, 0, len(defines))
+	for name := range defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value := defines[name]; value != "" {
+			fmt.Fprintf(b, "#define %s %s\n", name, value)
+		} else {
+			fmt.Fprintf(b, "#define %s\n", name)
+		}
+	}
+}
+
+// fileIndex parses a driver's source string number.
+func fileIndex(file string, files []ShaderFile) (int, bool) {
+	i, err := strconv.Atoi(file)
+	if err != nil || i < 0 || i >= len(files) {
+		return 0, false
+	}
+	return i, true
+}
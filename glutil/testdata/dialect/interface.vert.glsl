@@ -0,0 +1,10 @@
+layout(location = 0) in vec3 position;
+layout(location = 1) in vec3 normal;
+layout(location = 0) out vec3 fragNormal;
+flat out int instance;
+
+void main() {
+	fragNormal = normal;
+	instance = gl_InstanceID;
+	gl_Position = vec4(position, 1.0);
+}
@@ -0,0 +1,10 @@
+#version 120
+varying vec2 fragUV;
+uniform sampler2D tex;
+uniform sampler3D noise;
+
+void main() {
+	// gl_FragColor = vec4(1.0);
+	/* gl_FragColor = texture2D(tex, fragUV); */
+	gl_FragColor = texture2D(tex, fragUV) * texture3D(noise, vec3(fragUV, 0.0)).r;
+}
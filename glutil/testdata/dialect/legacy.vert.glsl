@@ -0,0 +1,14 @@
+#version 120
+/* Inputs from before GLSL 1.30:
+attribute vec4 unused;
+*/
+attribute vec3 position; // object space
+attribute vec2 uv;
+varying vec2 fragUV; /* passed to
+varying vec3 stillComment; */
+uniform mat4 mvp;
+
+void main() {
+	fragUV = uv;
+	gl_Position = mvp * vec4(position, 1.0);
+}
@@ -0,0 +1,11 @@
+#version 410 core
+layout(location = 0) in vec3 normal;
+layout(location = 1) in vec2 uv;
+out vec4 albedo;
+out vec4 packedNormal;
+uniform sampler2D tex;
+
+void main() {
+	albedo = texture(tex, uv);
+	packedNormal = vec4(normalize(normal) * 0.5 + 0.5, 1.0);
+}
@@ -0,0 +1,508 @@
+package glutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// The S3TC sRGB formats of GL_EXT_texture_sRGB, which the gl package
+// lacks.
+const (
+	compressedSRGBS3TCDXT1      = 0x8C4C
+	compressedSRGBAlphaS3TCDXT1 = 0x8C4D
+	compressedSRGBAlphaS3TCDXT3 = 0x8C4E
+	compressedSRGBAlphaS3TCDXT5 = 0x8C4F
+)
+
+// TexelFormat describes how the texels of a texture file are stored.
+type TexelFormat struct {
+	Name string
+	// InternalFormat is the GL internal format, such as
+	// gl.COMPRESSED_RGBA_BPTC_UNORM_ARB or gl.RGBA8.
+	InternalFormat uint32
+	// Compressed formats store 4x4 blocks of BlockBytes each. Other
+	// formats store BlockBytes per texel, in Format and Type.
+	Compressed   bool
+	BlockBytes   int
+	Format, Type uint32
+	// SRGB formats hold sRGB-encoded colour.
+	SRGB bool
+}
+
+var texelFormats = map[uint32]TexelFormat{}
+
+func init() {
+	for _, f := range []TexelFormat{
+		{Name: "BC1 RGB", InternalFormat: gl.COMPRESSED_RGB_S3TC_DXT1_EXT, Compressed: true, BlockBytes: 8},
+		{Name: "BC1 RGB sRGB", InternalFormat: compressedSRGBS3TCDXT1, Compressed: true, BlockBytes: 8, SRGB: true},
+		{Name: "BC1 RGBA", InternalFormat: gl.COMPRESSED_RGBA_S3TC_DXT1_EXT, Compressed: true, BlockBytes: 8},
+		{Name: "BC1 RGBA sRGB", InternalFormat: compressedSRGBAlphaS3TCDXT1, Compressed: true, BlockBytes: 8, SRGB: true},
+		{Name: "BC2", InternalFormat: gl.COMPRESSED_RGBA_S3TC_DXT3_EXT, Compressed: true, BlockBytes: 16},
+		{Name: "BC2 sRGB", InternalFormat: compressedSRGBAlphaS3TCDXT3, Compressed: true, BlockBytes: 16, SRGB: true},
+		{Name: "BC3", InternalFormat: gl.COMPRESSED_RGBA_S3TC_DXT5_EXT, Compressed: true, BlockBytes: 16},
+		{Name: "BC3 sRGB", InternalFormat: compressedSRGBAlphaS3TCDXT5, Compressed: true, BlockBytes: 16, SRGB: true},
+		{Name: "BC4", InternalFormat: gl.COMPRESSED_RED_RGTC1, Compressed: true, BlockBytes: 8},
+		{Name: "BC4 signed", InternalFormat: gl.COMPRESSED_SIGNED_RED_RGTC1, Compressed: true, BlockBytes: 8},
+		{Name: "BC5", InternalFormat: gl.COMPRESSED_RG_RGTC2, Compressed: true, BlockBytes: 16},
+		{Name: "BC5 signed", InternalFormat: gl.COMPRESSED_SIGNED_RG_RGTC2, Compressed: true, BlockBytes: 16},
+		{Name: "BC6H unsigned", InternalFormat: gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB, Compressed: true, BlockBytes: 16},
+		{Name: "BC6H signed", InternalFormat: gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB, Compressed: true, BlockBytes: 16},
+		{Name: "BC7", InternalFormat: gl.COMPRESSED_RGBA_BPTC_UNORM_ARB, Compressed: true, BlockBytes: 16},
+		{Name: "BC7 sRGB", InternalFormat: gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM_ARB, Compressed: true, BlockBytes: 16, SRGB: true},
+		{Name: "ETC2 RGB", InternalFormat: gl.COMPRESSED_RGB8_ETC2, Compressed: true, BlockBytes: 8},
+		{Name: "ETC2 RGB sRGB", InternalFormat: gl.COMPRESSED_SRGB8_ETC2, Compressed: true, BlockBytes: 8, SRGB: true},
+		{Name: "ETC2 RGB A1", InternalFormat: gl.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2, Compressed: true, BlockBytes: 8},
+		{Name: "ETC2 RGB A1 sRGB", InternalFormat: gl.COMPRESSED_SRGB8_PUNCHTHROUGH_ALPHA1_ETC2, Compressed: true, BlockBytes: 8, SRGB: true},
+		{Name: "ETC2 RGBA", InternalFormat: gl.COMPRESSED_RGBA8_ETC2_EAC, Compressed: true, BlockBytes: 16},
+		{Name: "ETC2 RGBA sRGB", InternalFormat: gl.COMPRESSED_SRGB8_ALPHA8_ETC2_EAC, Compressed: true, BlockBytes: 16, SRGB: true},
+		{Name: "EAC R11", InternalFormat: gl.COMPRESSED_R11_EAC, Compressed: true, BlockBytes: 8},
+		{Name: "EAC R11 signed", InternalFormat: gl.COMPRESSED_SIGNED_R11_EAC, Compressed: true, BlockBytes: 8},
+		{Name: "EAC RG11", InternalFormat: gl.COMPRESSED_RG11_EAC, Compressed: true, BlockBytes: 16},
+		{Name: "EAC RG11 signed", InternalFormat: gl.COMPRESSED_SIGNED_RG11_EAC, Compressed: true, BlockBytes: 16},
+		{Name: "RGBA8", InternalFormat: gl.RGBA8, BlockBytes: 4, Format: gl.RGBA, Type: gl.UNSIGNED_BYTE},
+		{Name: "RGBA8 sRGB", InternalFormat: gl.SRGB8_ALPHA8, BlockBytes: 4, Format: gl.RGBA, Type: gl.UNSIGNED_BYTE, SRGB: true},
+		{Name: "RGBA16F", InternalFormat: gl.RGBA16F, BlockBytes: 8, Format: gl.RGBA, Type: gl.HALF_FLOAT},
+		{Name: "RGBA32F", InternalFormat: gl.RGBA32F, BlockBytes: 16, Format: gl.RGBA, Type: gl.FLOAT},
+	} {
+		texelFormats[f.InternalFormat] = f
+	}
+}
+
+// srgbFormats maps linear formats to their sRGB variants.
+var srgbFormats = map[uint32]uint32{
+	gl.COMPRESSED_RGB_S3TC_DXT1_EXT:             compressedSRGBS3TCDXT1,
+	gl.COMPRESSED_RGBA_S3TC_DXT1_EXT:            compressedSRGBAlphaS3TCDXT1,
+	gl.COMPRESSED_RGBA_S3TC_DXT3_EXT:            compressedSRGBAlphaS3TCDXT3,
+	gl.COMPRESSED_RGBA_S3TC_DXT5_EXT:            compressedSRGBAlphaS3TCDXT5,
+	gl.COMPRESSED_RGBA_BPTC_UNORM_ARB:           gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM_ARB,
+	gl.COMPRESSED_RGB8_ETC2:                     gl.COMPRESSED_SRGB8_ETC2,
+	gl.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2: gl.COMPRESSED_SRGB8_PUNCHTHROUGH_ALPHA1_ETC2,
+	gl.COMPRESSED_RGBA8_ETC2_EAC:                gl.COMPRESSED_SRGB8_ALPHA8_ETC2_EAC,
+	gl.RGBA8:                                    gl.SRGB8_ALPHA8,
+}
+
+// LevelSize returns the size in bytes of a width x height image.
+func (f TexelFormat) LevelSize(width, height int) int {
+	if f.Compressed {
+		return (width + 3) / 4 * ((height + 3) / 4) * f.BlockBytes
+	}
+	return width * height * f.BlockBytes
+}
+
+// TextureFile is a texture read from a KTX, KTX2 or DDS container, with
+// every mip level as stored.
+type TextureFile struct {
+	Format        TexelFormat
+	Width, Height int
+	// Faces is 1, or 6 for a cube map in the order +X, -X, +Y, -Y, +Z, -Z.
+	Faces int
+	// Levels holds the data of each mip level, largest first, and within a
+	// level of each face.
+	Levels [][][]byte
+}
+
+// LevelSize returns the width and height of mip level.
+func (f *TextureFile) LevelSize(level int) (width, height int) {
+	width, height = f.Width>>level, f.Height>>level
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	return width, height
+}
+
+// maxImageLength is the most texels a texture file's base level may have,
+// so that sizes computed from a crafted header cannot overflow.
+const maxImageLength = 1 << 28
+
+// validImageSize reports whether a texture file's header gives a usable
+// base level size.
+func validImageSize(width, height int) bool {
+	return width > 0 && height > 0 && width <= maxImageLength && height <= maxImageLength &&
+		width*height <= maxImageLength
+}
+
+// check verifies that every level and face holds as many bytes as its
+// size needs.
+func (f *TextureFile) check() error {
+	if f.Width <= 0 || f.Height <= 0 {
+		return fmt.Errorf("bad size %dx%d", f.Width, f.Height)
+	}
+	for level, faces := range f.Levels {
+		w, h := f.LevelSize(level)
+		for _, data := range faces {
+			if len(data) < f.Format.LevelSize(w, h) {
+				return fmt.Errorf("level %d holds %d bytes, want %d", level, len(data), f.Format.LevelSize(w, h))
+			}
+		}
+	}
+	return nil
+}
+
+// Decode decodes one level and face in software: 8-bit formats to an
+// *image.NRGBA, unsigned 11-bit EAC to an *image.NRGBA64, and signed and
+// float formats to an *RGBA32F. One and two-channel formats leave the
+// other channels 0 and alpha 1, as GL samples them.
+func (f *TextureFile) Decode(level, face int) (image.Image, error) {
+	if level < 0 || level >= len(f.Levels) || face < 0 || face >= len(f.Levels[level]) {
+		return nil, fmt.Errorf("texture: no level %d face %d", level, face)
+	}
+	w, h := f.LevelSize(level)
+	return DecodeTexels(f.Format.InternalFormat, w, h, f.Levels[level][face])
+}
+
+// DecodeTexels decodes width x height texels stored in the given GL
+// internal format, as TextureFile.Decode does. It has software decoders
+// for every compressed format.
+func DecodeTexels(internalFormat uint32, width, height int, data []byte) (image.Image, error) {
+	f, ok := texelFormats[internalFormat]
+	if !ok {
+		return nil, fmt.Errorf("texture: unknown format 0x%X", internalFormat)
+	}
+	if len(data) < f.LevelSize(width, height) {
+		return nil, fmt.Errorf("texture: %s data holds %d bytes, want %d", f.Name, len(data), f.LevelSize(width, height))
+	}
+	rect := image.Rect(0, 0, width, height)
+	if !f.Compressed {
+		switch f.Type {
+		case gl.UNSIGNED_BYTE:
+			img := image.NewNRGBA(rect)
+			copy(img.Pix, data)
+			return img, nil
+		case gl.FLOAT:
+			img := NewRGBA32F(rect)
+			for i := range img.Pix {
+				img.Pix[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+			}
+			return img, nil
+		case gl.HALF_FLOAT:
+			img := NewRGBA32F(rect)
+			for i := range img.Pix {
+				img.Pix[i] = halfToFloat(binary.LittleEndian.Uint16(data[2*i:]))
+			}
+			return img, nil
+		}
+	}
+
+	// Each decoder writes one 4x4 block at bx, by, cropped to the image.
+	var decode func(block []byte, bx, by int)
+	switch internalFormat {
+	case gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB, gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB:
+		img := NewRGBA32F(rect)
+		signed := internalFormat == gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB
+		decode = func(block []byte, bx, by int) {
+			var out [16][3]float32
+			decodeBC6H(block, signed, &out)
+			for i, v := range out {
+				img.SetRGBA32F(bx+i%4, by+i/4, [4]float32{v[0], v[1], v[2], 1})
+			}
+		}
+		return decodeBlocks(f, width, height, data, decode, img)
+
+	case gl.COMPRESSED_SIGNED_RED_RGTC1, gl.COMPRESSED_SIGNED_RG_RGTC2,
+		gl.COMPRESSED_SIGNED_R11_EAC, gl.COMPRESSED_SIGNED_RG11_EAC:
+		img := NewRGBA32F(rect)
+		channel := decodeBC4ChannelSigned
+		if internalFormat == gl.COMPRESSED_SIGNED_R11_EAC || internalFormat == gl.COMPRESSED_SIGNED_RG11_EAC {
+			channel = decodeEAC11Signed
+		}
+		decode = func(block []byte, bx, by int) {
+			var r, g [16]float32
+			channel(block, &r)
+			if len(block) == 16 {
+				channel(block[8:], &g)
+			}
+			for i := 0; i < 16; i++ {
+				img.SetRGBA32F(bx+i%4, by+i/4, [4]float32{r[i], g[i], 0, 1})
+			}
+		}
+		return decodeBlocks(f, width, height, data, decode, img)
+
+	case gl.COMPRESSED_R11_EAC, gl.COMPRESSED_RG11_EAC:
+		img := image.NewNRGBA64(rect)
+		decode = func(block []byte, bx, by int) {
+			var r, g [16]uint16
+			decodeEAC11(block, &r)
+			if len(block) == 16 {
+				decodeEAC11(block[8:], &g)
+			}
+			for i := 0; i < 16; i++ {
+				x, y := bx+i%4, by+i/4
+				if x < width && y < height {
+					p := img.Pix[img.PixOffset(x, y):]
+					binary.BigEndian.PutUint16(p, r[i])
+					binary.BigEndian.PutUint16(p[2:], g[i])
+					binary.BigEndian.PutUint16(p[6:], 0xffff)
+				}
+			}
+		}
+		return decodeBlocks(f, width, height, data, decode, img)
+	}
+
+	var block4x4 func(block []byte, out *texel4x4)
+	switch internalFormat {
+	case gl.COMPRESSED_RGB_S3TC_DXT1_EXT, compressedSRGBS3TCDXT1:
+		block4x4 = func(b []byte, out *texel4x4) { decodeBC1(b, out, false, false) }
+	case gl.COMPRESSED_RGBA_S3TC_DXT1_EXT, compressedSRGBAlphaS3TCDXT1:
+		block4x4 = func(b []byte, out *texel4x4) { decodeBC1(b, out, true, false) }
+	case gl.COMPRESSED_RGBA_S3TC_DXT3_EXT, compressedSRGBAlphaS3TCDXT3:
+		block4x4 = decodeBC2
+	case gl.COMPRESSED_RGBA_S3TC_DXT5_EXT, compressedSRGBAlphaS3TCDXT5:
+		block4x4 = decodeBC3
+	case gl.COMPRESSED_RED_RGTC1, gl.COMPRESSED_RG_RGTC2:
+		block4x4 = func(b []byte, out *texel4x4) {
+			var r, g [16]uint8
+			decodeBC4Channel(b, &r)
+			if len(b) == 16 {
+				decodeBC4Channel(b[8:], &g)
+			}
+			for i := range out {
+				out[i] = [4]uint8{r[i], g[i], 0, 0xff}
+			}
+		}
+	case gl.COMPRESSED_RGBA_BPTC_UNORM_ARB, gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM_ARB:
+		block4x4 = decodeBC7
+	case gl.COMPRESSED_RGB8_ETC2, gl.COMPRESSED_SRGB8_ETC2:
+		block4x4 = func(b []byte, out *texel4x4) { decodeETC2(b, out, false) }
+	case gl.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2, gl.COMPRESSED_SRGB8_PUNCHTHROUGH_ALPHA1_ETC2:
+		block4x4 = func(b []byte, out *texel4x4) { decodeETC2(b, out, true) }
+	case gl.COMPRESSED_RGBA8_ETC2_EAC, gl.COMPRESSED_SRGB8_ALPHA8_ETC2_EAC:
+		block4x4 = func(b []byte, out *texel4x4) {
+			decodeETC2(b[8:], out, false)
+			var alpha [16]uint8
+			decodeEACAlpha(b, &alpha)
+			for i := range out {
+				out[i][3] = alpha[i]
+			}
+		}
+	default:
+		return nil, fmt.Errorf("texture: no software decoder for %s", f.Name)
+	}
+	img := image.NewNRGBA(rect)
+	decode = func(block []byte, bx, by int) {
+		var out texel4x4
+		block4x4(block, &out)
+		for i := 0; i < 16; i++ {
+			x, y := bx+i%4, by+i/4
+			if x < width && y < height {
+				copy(img.Pix[img.PixOffset(x, y):], out[i][:])
+			}
+		}
+	}
+	return decodeBlocks(f, width, height, data, decode, img)
+}
+
+// decodeBlocks calls decode for each block of data in row order and
+// returns img.
+func decodeBlocks(f TexelFormat, width, height int, data []byte, decode func(block []byte, bx, by int), img image.Image) (image.Image, error) {
+	n := 0
+	for by := 0; by < height; by += 4 {
+		for bx := 0; bx < width; bx += 4 {
+			decode(data[n:n+f.BlockBytes], bx, by)
+			n += f.BlockBytes
+		}
+	}
+	return img, nil
+}
+
+// halfToFloat converts an IEEE 754 half-precision value.
+func halfToFloat(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := int(h >> 10 & 0x1f)
+	mant := uint32(h & 0x3ff)
+	switch {
+	case exp == 0 && mant == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// Subnormal: renormalise.
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		exp++
+		mant &= 0x3ff
+	case exp == 31:
+		return math.Float32frombits(sign | 0xff<<23 | mant<<13)
+	}
+	return math.Float32frombits(sign | uint32(exp+127-15)<<23 | mant<<13)
+}
+
+// ReadTextureFile reads a KTX, KTX2 or DDS file, by its extension.
+func ReadTextureFile(file string) (*TextureFile, error) {
+	r, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("texture %q not found on disk: %v", file, err)
+	}
+	defer r.Close()
+	var f *TextureFile
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".ktx", ".ktx2":
+		f, err = ReadKTX(r)
+	case ".dds":
+		f, err = ReadDDS(r)
+	default:
+		return nil, fmt.Errorf("texture %q: not a KTX, KTX2 or DDS file", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("texture %q: %v", file, err)
+	}
+	return f, nil
+}
+
+// isTextureFile reports whether file has the extension of a container
+// that ReadTextureFile reads.
+func isTextureFile(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".ktx", ".ktx2", ".dds":
+		return true
+	}
+	return false
+}
+
+// Upload creates a texture from every level of f, a 2D texture or a cube
+// map, bound to texture unit 0. Compressed levels are uploaded as they
+// are when the driver supports the format, and otherwise decoded in
+// software first. A file with several levels needs no Mipmaps option;
+// a compressed file must bring its own. SRGB picks the sRGB variant of
+// the format, and FlipY works only for uncompressed files.
+func (f *TextureFile) Upload(opts TextureOptions) (uint32, error) {
+	format := f.Format
+	if opts.SRGB && !format.SRGB {
+		srgb, ok := srgbFormats[format.InternalFormat]
+		if !ok {
+			return 0, fmt.Errorf("texture: %s has no sRGB variant", format.Name)
+		}
+		format = texelFormats[srgb]
+	}
+	switch {
+	case opts.InternalFormat != 0:
+		return 0, fmt.Errorf("texture: InternalFormat does not apply to %s files", format.Name)
+	case opts.FlipY && format.Compressed:
+		return 0, fmt.Errorf("texture: FlipY cannot flip %s blocks", format.Name)
+	case opts.Mipmaps && len(f.Levels) == 1 && format.Compressed:
+		return 0, fmt.Errorf("texture: cannot generate mipmaps for %s", format.Name)
+	}
+	generate := opts.Mipmaps && len(f.Levels) == 1
+	if len(f.Levels) > 1 {
+		opts.Mipmaps = true
+	}
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	target := uint32(gl.TEXTURE_2D)
+	if f.Faces == 6 {
+		target = gl.TEXTURE_CUBE_MAP
+	}
+	software := format.Compressed && !CompressedFormatSupported(format.InternalFormat)
+	if software {
+		log.Printf("texture: no driver support for %s, decoding it in software", format.Name)
+	}
+
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(target, texture)
+	opts.apply(target)
+	if !generate {
+		gl.TexParameteri(target, gl.TEXTURE_MAX_LEVEL, int32(len(f.Levels)-1))
+	}
+	for level, faces := range f.Levels {
+		w, h := f.LevelSize(level)
+		for face, data := range faces {
+			faceTarget := target
+			if f.Faces == 6 {
+				faceTarget = gl.TEXTURE_CUBE_MAP_POSITIVE_X + uint32(face)
+			}
+			switch {
+			case software:
+				img, err := DecodeTexels(format.InternalFormat, w, h, data)
+				if err != nil {
+					gl.DeleteTextures(1, &texture)
+					return 0, err
+				}
+				t := straightTexels(img)
+				internalFormat, err := TextureOptions{SRGB: format.SRGB}.internalFormat(t.xtype)
+				if err != nil {
+					gl.DeleteTextures(1, &texture)
+					return 0, err
+				}
+				t.upload(faceTarget, int32(level), internalFormat)
+			case format.Compressed:
+				size := format.LevelSize(w, h)
+				gl.CompressedTexImage2D(faceTarget, int32(level), format.InternalFormat, int32(w), int32(h), 0, int32(size), gl.Ptr(data))
+			default:
+				t := texels{pix: data, stride: w * format.BlockBytes, width: w, height: h, xtype: format.Type}
+				if opts.FlipY {
+					t.pix = append([]byte(nil), data[:format.LevelSize(w, h)]...)
+					flipRows(t.pix, t.stride)
+				}
+				t.upload(faceTarget, int32(level), int32(format.InternalFormat))
+			}
+		}
+	}
+	if generate {
+		gl.GenerateMipmap(target)
+	}
+	return texture, nil
+}
+
+// straightTexels is newTexels without premultiplying 8-bit alpha, as the
+// GPU would decode the blocks.
+func straightTexels(img image.Image) texels {
+	if img, ok := img.(*image.NRGBA); ok {
+		b := img.Bounds()
+		return texels{pix: img.Pix, stride: img.Stride, width: b.Dx(), height: b.Dy(), xtype: gl.UNSIGNED_BYTE}
+	}
+	return newTexels(img)
+}
+
+// CompressedFormatSupported reports whether the current context can
+// store textures in a compressed internal format: the driver lists it in
+// GL_COMPRESSED_TEXTURE_FORMATS, or its GL version or an extension
+// provides it.
+func CompressedFormatSupported(internalFormat uint32) bool {
+	var n int32
+	gl.GetIntegerv(gl.NUM_COMPRESSED_TEXTURE_FORMATS, &n)
+	if n > 0 {
+		formats := make([]int32, n)
+		gl.GetIntegerv(gl.COMPRESSED_TEXTURE_FORMATS, &formats[0])
+		for _, f := range formats {
+			if uint32(f) == internalFormat {
+				return true
+			}
+		}
+	}
+	var major, minor int32
+	gl.GetIntegerv(gl.MAJOR_VERSION, &major)
+	gl.GetIntegerv(gl.MINOR_VERSION, &minor)
+	version := major*10 + minor
+	switch internalFormat {
+	case gl.COMPRESSED_RED_RGTC1, gl.COMPRESSED_SIGNED_RED_RGTC1, gl.COMPRESSED_RG_RGTC2, gl.COMPRESSED_SIGNED_RG_RGTC2:
+		return version >= 30 || HasExtension("GL_ARB_texture_compression_rgtc")
+	case gl.COMPRESSED_RGB_S3TC_DXT1_EXT, gl.COMPRESSED_RGBA_S3TC_DXT1_EXT, gl.COMPRESSED_RGBA_S3TC_DXT3_EXT, gl.COMPRESSED_RGBA_S3TC_DXT5_EXT:
+		return HasExtension("GL_EXT_texture_compression_s3tc")
+	case compressedSRGBS3TCDXT1, compressedSRGBAlphaS3TCDXT1, compressedSRGBAlphaS3TCDXT3, compressedSRGBAlphaS3TCDXT5:
+		return HasExtension("GL_EXT_texture_compression_s3tc") &&
+			(HasExtension("GL_EXT_texture_sRGB") || HasExtension("GL_EXT_texture_compression_s3tc_srgb"))
+	case gl.COMPRESSED_RGBA_BPTC_UNORM_ARB, gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM_ARB,
+		gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB, gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB:
+		return version >= 42 || HasExtension("GL_ARB_texture_compression_bptc")
+	case gl.COMPRESSED_RGB8_ETC2, gl.COMPRESSED_SRGB8_ETC2,
+		gl.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2, gl.COMPRESSED_SRGB8_PUNCHTHROUGH_ALPHA1_ETC2,
+		gl.COMPRESSED_RGBA8_ETC2_EAC, gl.COMPRESSED_SRGB8_ALPHA8_ETC2_EAC,
+		gl.COMPRESSED_R11_EAC, gl.COMPRESSED_SIGNED_R11_EAC, gl.COMPRESSED_RG11_EAC, gl.COMPRESSED_SIGNED_RG11_EAC:
+		return version >= 43 || HasExtension("GL_ARB_ES3_compatibility")
+	}
+	return false
+}
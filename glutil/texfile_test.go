@@ -0,0 +1,557 @@
+package glutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// The blocks below are built by hand and their texels worked out from the
+// BCn, BPTC and ETC2 specifications, not from the decoders.
+
+// bitWriter packs fields into a 128-bit BC7 block, least significant bit
+// first.
+type bitWriter struct {
+	b [16]byte
+	n uint
+}
+
+func (w *bitWriter) put(v uint64, bits uint) {
+	for i := uint(0); i < bits; i++ {
+		if v>>i&1 != 0 {
+			w.b[w.n/8] |= 1 << (w.n % 8)
+		}
+		w.n++
+	}
+}
+
+// bc4Block returns a BC4 block with endpoints a0, a1 and the given 3-bit
+// indices in row-major order.
+func bc4Block(a0, a1 byte, idx [16]uint64) []byte {
+	bits := uint64(a1)<<8 | uint64(a0)
+	for i, v := range idx {
+		bits |= v << (16 + 3*i)
+	}
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, bits)
+	return b
+}
+
+// etcIndices returns the index half of an ETC block for 2-bit indices in
+// row-major order. ETC stores them column by column, split into a plane
+// of high bits and a plane of low bits.
+func etcIndices(idx [16]uint32) []byte {
+	var msb, lsb uint32
+	for i, v := range idx {
+		j := i%4*4 + i/4
+		msb |= (v >> 1 & 1) << j
+		lsb |= (v & 1) << j
+	}
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, msb<<16|lsb)
+	return b
+}
+
+// eacBlock returns an EAC block with the given base, multiplier, modifier
+// table and 3-bit indices in row-major order.
+func eacBlock(base, mult, table byte, idx [16]uint64) []byte {
+	var bits uint64
+	for i, v := range idx {
+		j := i%4*4 + i/4
+		bits |= v << (45 - 3*j)
+	}
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, bits)
+	b[0], b[1] = base, mult<<4|table
+	return b
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// rows fills a block with the given rows of four texels, repeating them
+// in turn.
+func rows(r ...[4][4]uint8) [16][4]uint8 {
+	var t [16][4]uint8
+	for i := range t {
+		t[i] = r[i/4%len(r)][i%4]
+	}
+	return t
+}
+
+func TestDecodeTexels8(t *testing.T) {
+	red := [4]uint8{255, 0, 0, 255}
+	blue := [4]uint8{0, 0, 255, 255}
+	clear := [4]uint8{}
+	black := [4]uint8{0, 0, 0, 255}
+	clearRow := [4][4]uint8{clear, clear, clear, clear}
+	blackRow := [4][4]uint8{black, black, black, black}
+
+	// Texel i of the BC7 mode 6 block has index i; the first is the
+	// anchor and has only 3 bits.
+	var mode6 bitWriter
+	mode6.put(1<<6, 7)
+	for _, e := range []uint64{0, 0x7f, 0x7f, 0, 0x40, 0x40, 0x7f, 0x7f} {
+		mode6.put(e, 7)
+	}
+	mode6.put(0, 1) // p-bit of endpoint 0
+	mode6.put(1, 1) // p-bit of endpoint 1
+	mode6.put(0, 3)
+	for i := uint64(1); i < 16; i++ {
+		mode6.put(i, 4)
+	}
+
+	// BC7 mode 5 with rotation 1, which swaps red and alpha. Red runs
+	// from 0 to 255 with colour index i%4; alpha is 32 everywhere.
+	var mode5 bitWriter
+	mode5.put(1<<5, 6)
+	mode5.put(1, 2)
+	for _, e := range []uint64{0, 0x7f, 0x40, 0x40, 0, 0} {
+		mode5.put(e, 7)
+	}
+	mode5.put(0x20, 8)
+	mode5.put(0x20, 8)
+	mode5.put(0, 1)
+	for i := 1; i < 16; i++ {
+		mode5.put(uint64(i%4), 2)
+	}
+	mode5.put(0, 31)
+
+	rowIndices := [16]uint32{0, 1, 2, 3}
+	alphaIndices := [16]uint64{0, 4, 7, 3}
+	ramp := [16]uint64{0, 1, 2, 3, 4, 5, 6, 7, 0, 1, 2, 3, 4, 5, 6, 7}
+
+	tests := []struct {
+		name   string
+		format uint32
+		block  []byte
+		want   [16][4]uint8
+	}{
+		{
+			name:   "BC1 four colours",
+			format: gl.COMPRESSED_RGB_S3TC_DXT1_EXT,
+			block:  []byte{0x00, 0xf8, 0x1f, 0x00, 0xe4, 0xe4, 0xe4, 0xe4},
+			want:   rows([4][4]uint8{red, blue, {170, 0, 85, 255}, {85, 0, 170, 255}}),
+		},
+		{
+			name:   "BC1 three colours with punchthrough",
+			format: gl.COMPRESSED_RGBA_S3TC_DXT1_EXT,
+			block:  []byte{0x1f, 0x00, 0x00, 0xf8, 0xe4, 0xff, 0xff, 0xff},
+			want:   rows([4][4]uint8{blue, red, {127, 0, 127, 255}, clear}, clearRow, clearRow, clearRow),
+		},
+		{
+			name:   "BC1 three colours without alpha",
+			format: gl.COMPRESSED_RGB_S3TC_DXT1_EXT,
+			block:  []byte{0x1f, 0x00, 0x00, 0xf8, 0xe4, 0xff, 0xff, 0xff},
+			want:   rows([4][4]uint8{blue, red, {127, 0, 127, 255}, black}, blackRow, blackRow, blackRow),
+		},
+		{
+			// The colour half is in three-colour order, but BC3 always
+			// uses four colours.
+			name:   "BC3",
+			format: gl.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+			block:  concat(bc4Block(255, 0, ramp), []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}),
+			want: rows(
+				[4][4]uint8{{255, 255, 255, 255}, {255, 255, 255, 0}, {255, 255, 255, 219}, {255, 255, 255, 182}},
+				[4][4]uint8{{255, 255, 255, 146}, {255, 255, 255, 109}, {255, 255, 255, 73}, {255, 255, 255, 36}},
+			),
+		},
+		{
+			name:   "BC4 six values",
+			format: gl.COMPRESSED_RED_RGTC1,
+			block:  bc4Block(16, 240, ramp),
+			want: rows(
+				[4][4]uint8{{16, 0, 0, 255}, {240, 0, 0, 255}, {61, 0, 0, 255}, {106, 0, 0, 255}},
+				[4][4]uint8{{150, 0, 0, 255}, {195, 0, 0, 255}, {0, 0, 0, 255}, {255, 0, 0, 255}},
+			),
+		},
+		{
+			name:   "BC5",
+			format: gl.COMPRESSED_RG_RGTC2,
+			block:  concat(bc4Block(16, 240, ramp), bc4Block(200, 100, [16]uint64{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2})),
+			want: rows(
+				[4][4]uint8{{16, 186, 0, 255}, {240, 186, 0, 255}, {61, 186, 0, 255}, {106, 186, 0, 255}},
+				[4][4]uint8{{150, 186, 0, 255}, {195, 186, 0, 255}, {0, 186, 0, 255}, {255, 186, 0, 255}},
+			),
+		},
+		{
+			name:   "BC7 mode 5 rotation",
+			format: gl.COMPRESSED_RGBA_BPTC_UNORM_ARB,
+			block:  mode5.b[:],
+			want:   rows([4][4]uint8{{32, 129, 0, 0}, {32, 129, 0, 84}, {32, 129, 0, 171}, {32, 129, 0, 255}}),
+		},
+		{
+			name:   "BC7 reserved mode",
+			format: gl.COMPRESSED_RGBA_BPTC_UNORM_ARB,
+			block:  make([]byte, 16),
+		},
+		{
+			// Individual mode: the left half is (136, 68, 0) with
+			// modifiers ±2 and ±8, the right half (136, 204, 0) with ±47
+			// and ±183.
+			name:   "ETC2 individual",
+			format: gl.COMPRESSED_RGB8_ETC2,
+			block:  concat([]byte{0x88, 0x4c, 0x00, 0x1c}, etcIndices(rowIndices)),
+			want: rows(
+				[4][4]uint8{{138, 70, 2, 255}, {144, 76, 8, 255}, {89, 157, 0, 255}, {0, 21, 0, 255}},
+				[4][4]uint8{{138, 70, 2, 255}, {138, 70, 2, 255}, {183, 251, 47, 255}, {183, 251, 47, 255}},
+				[4][4]uint8{{138, 70, 2, 255}, {138, 70, 2, 255}, {183, 251, 47, 255}, {183, 251, 47, 255}},
+				[4][4]uint8{{138, 70, 2, 255}, {138, 70, 2, 255}, {183, 251, 47, 255}, {183, 251, 47, 255}},
+			),
+		},
+		{
+			// Differential mode, flipped: the top half is (132, 66, 255)
+			// with ±5, the bottom (148, 57, 255) with ±13.
+			name:   "ETC2 differential",
+			format: gl.COMPRESSED_RGB8_ETC2,
+			block:  []byte{0x82, 0x47, 0xf8, 0x2f, 0, 0, 0, 0},
+			want: [16][4]uint8{
+				{137, 71, 255, 255}, {137, 71, 255, 255}, {137, 71, 255, 255}, {137, 71, 255, 255},
+				{137, 71, 255, 255}, {137, 71, 255, 255}, {137, 71, 255, 255}, {137, 71, 255, 255},
+				{161, 70, 255, 255}, {161, 70, 255, 255}, {161, 70, 255, 255}, {161, 70, 255, 255},
+				{161, 70, 255, 255}, {161, 70, 255, 255}, {161, 70, 255, 255}, {161, 70, 255, 255},
+			},
+		},
+		{
+			// The same block without the opaque bit: index 0 has no
+			// modifier and index 2 is transparent.
+			name:   "ETC2 punchthrough",
+			format: gl.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2,
+			block:  concat([]byte{0x82, 0x47, 0xf8, 0x2d}, etcIndices(rowIndices)),
+			want: [16][4]uint8{
+				{132, 66, 255, 255}, {149, 83, 255, 255}, clear, {115, 49, 238, 255},
+				{132, 66, 255, 255}, {132, 66, 255, 255}, {132, 66, 255, 255}, {132, 66, 255, 255},
+				{148, 57, 255, 255}, {148, 57, 255, 255}, {148, 57, 255, 255}, {148, 57, 255, 255},
+				{148, 57, 255, 255}, {148, 57, 255, 255}, {148, 57, 255, 255}, {148, 57, 255, 255},
+			},
+		},
+		{
+			name:   "ETC2 with EAC alpha",
+			format: gl.COMPRESSED_RGBA8_ETC2_EAC,
+			block:  concat(eacBlock(128, 2, 0, alphaIndices), make([]byte, 8)),
+			want: rows(
+				[4][4]uint8{{2, 2, 2, 122}, {2, 2, 2, 132}, {2, 2, 2, 156}, {2, 2, 2, 98}},
+				[4][4]uint8{{2, 2, 2, 122}, {2, 2, 2, 122}, {2, 2, 2, 122}, {2, 2, 2, 122}},
+				[4][4]uint8{{2, 2, 2, 122}, {2, 2, 2, 122}, {2, 2, 2, 122}, {2, 2, 2, 122}},
+				[4][4]uint8{{2, 2, 2, 122}, {2, 2, 2, 122}, {2, 2, 2, 122}, {2, 2, 2, 122}},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := DecodeTexels(tt.format, 4, 4, tt.block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nrgba, ok := img.(*image.NRGBA)
+			if !ok {
+				t.Fatalf("decoded to %T, want *image.NRGBA", img)
+			}
+			var got [16][4]uint8
+			for i := range got {
+				copy(got[i][:], nrgba.Pix[4*i:])
+			}
+			if got != tt.want {
+				t.Errorf("texels:\n%v\nwant:\n%v", got, tt.want)
+			}
+		})
+	}
+
+	// The mode 6 endpoints are (0, 254, 128, 254) and (255, 1, 129, 255)
+	// once their p-bits are added.
+	img, err := DecodeTexels(gl.COMPRESSED_RGBA_BPTC_UNORM_ARB, 4, 4, mode6.b[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		index int
+		want  [4]uint8
+	}{
+		{0, [4]uint8{0, 254, 128, 254}},
+		{4, [4]uint8{68, 187, 128, 254}},
+		{8, [4]uint8{135, 120, 129, 255}},
+		{15, [4]uint8{255, 1, 129, 255}},
+	} {
+		var got [4]uint8
+		copy(got[:], img.(*image.NRGBA).Pix[4*tt.index:])
+		if got != tt.want {
+			t.Errorf("BC7 mode 6 texel %d = %v, want %v", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeTexelsEAC11(t *testing.T) {
+	// Unsigned: base 128*8+4 with multiplier 2*8 and table 0.
+	img, err := DecodeTexels(gl.COMPRESSED_R11_EAC, 4, 4, eacBlock(128, 2, 0, [16]uint64{0, 4, 7, 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []uint16
+	for x := 0; x < 4; x++ {
+		r, _, _, _ := img.At(x, 0).RGBA()
+		got = append(got, uint16(r>>5))
+	}
+	if want := []uint16{980, 1060, 1252, 788}; !reflect.DeepEqual(got, want) {
+		t.Errorf("R11 values = %v, want %v", got, want)
+	}
+
+	// Signed: base -64*8 with multiplier 8 and table 0.
+	img, err = DecodeTexels(gl.COMPRESSED_SIGNED_R11_EAC, 4, 4, eacBlock(0xc0, 1, 0, [16]uint64{0, 7}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := img.(*RGBA32F)
+	if got, want := f.RGBA32FAt(0, 0), [4]float32{-536.0 / 1023, 0, 0, 1}; got != want {
+		t.Errorf("signed R11 texel 0 = %v, want %v", got, want)
+	}
+	if got, want := f.RGBA32FAt(1, 0), [4]float32{-400.0 / 1023, 0, 0, 1}; got != want {
+		t.Errorf("signed R11 texel 1 = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeTexelsCrop(t *testing.T) {
+	// A 5x3 BC4 image is two blocks wide and one high; texels beyond the
+	// image are dropped.
+	block := bc4Block(16, 240, [16]uint64{0, 1, 2, 3, 4, 5, 6, 7, 0, 1, 2, 3, 4, 5, 6, 7})
+	img, err := DecodeTexels(gl.COMPRESSED_RED_RGTC1, 5, 3, concat(block, bc4Block(0, 0, [16]uint64{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Bounds(), image.Rect(0, 0, 5, 3); got != want {
+		t.Fatalf("bounds = %v, want %v", got, want)
+	}
+	var got []uint8
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 5; x++ {
+			got = append(got, img.(*image.NRGBA).NRGBAAt(x, y).R)
+		}
+	}
+	want := []uint8{16, 240, 61, 106, 0, 150, 195, 0, 255, 0, 16, 240, 61, 106, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("red = %v, want %v", got, want)
+	}
+
+	if _, err := DecodeTexels(gl.COMPRESSED_RED_RGTC1, 5, 3, block); err == nil {
+		t.Error("DecodeTexels accepted one block for a 5x3 image")
+	}
+}
+
+func TestDecodeTexelsBC6H(t *testing.T) {
+	// Mode 11: one region with raw 10-bit endpoints (0, 1023, 512) and
+	// (1023, 0, 512); texel i has index i.
+	var raw bitWriter
+	raw.put(0x03, 5)
+	for _, e := range []uint64{0, 1023, 512, 1023, 0, 512} {
+		raw.put(e, 10)
+	}
+	raw.put(0, 3)
+	for i := uint64(1); i < 16; i++ {
+		raw.put(i, 4)
+	}
+
+	// Mode 14: a 16-bit first endpoint, whose top six bits are stored
+	// reversed, and 4-bit deltas -8, 7 and 1. Blue wraps to 0. Texels 0
+	// and 15 pick the two endpoints.
+	var wide bitWriter
+	wide.put(0x0f, 5)
+	for _, e := range []uint64{0x021, 0x234, 0x3ff} {
+		wide.put(e, 10)
+	}
+	wide.put(0x8, 4)
+	wide.put(0x21, 6) // 0x8421 >> 10, a palindrome
+	wide.put(0x7, 4)
+	wide.put(0x08, 6) // 0x1234 >> 10 = 0b000100, reversed
+	wide.put(0x1, 4)
+	wide.put(0x3f, 6)
+	wide.put(0, 3)
+	wide.put(0, 4*14)
+	wide.put(15, 4)
+
+	// Mode 1: two regions in partition 13, whose bottom half is region 1
+	// and anchored at texel 15. Endpoint 0 is 512 in every channel and
+	// the others are 5-bit deltas from it: (15, -16, 0), (-1, 1, -16) and
+	// (0, -1, -11), scattered through the block.
+	var deltas bitWriter
+	deltas.put(0, 2)
+	deltas.put(0, 1) // g2[4]
+	deltas.put(1, 1) // b2[4]
+	deltas.put(1, 1) // b3[4]
+	deltas.put(512, 10)
+	deltas.put(512, 10)
+	deltas.put(512, 10)
+	deltas.put(15, 5)   // r1
+	deltas.put(1, 1)    // g3[4]
+	deltas.put(1, 4)    // g2[3:0]
+	deltas.put(0x10, 5) // g1
+	deltas.put(1, 1)    // b3[0]
+	deltas.put(0xf, 4)  // g3[3:0]
+	deltas.put(0, 5)    // b1
+	deltas.put(0, 1)    // b3[1]
+	deltas.put(0, 4)    // b2[3:0]
+	deltas.put(0x1f, 5) // r2
+	deltas.put(1, 1)    // b3[2]
+	deltas.put(0, 5)    // r3
+	deltas.put(0, 1)    // b3[3]
+	deltas.put(13, 5)
+	deltas.put(0, 2)
+	for i := 1; i < 15; i++ {
+		switch i {
+		case 7, 8:
+			deltas.put(7, 3)
+		default:
+			deltas.put(0, 3)
+		}
+	}
+	deltas.put(0, 2)
+
+	// Signed mode 11: endpoints (-1, -512, 511) and (0, 256, -511).
+	var signed bitWriter
+	signed.put(0x03, 5)
+	for _, e := range []uint64{0x3ff, 0x200, 0x1ff, 0, 0x100, 0x201} {
+		signed.put(e, 10)
+	}
+	signed.put(0, 3)
+	signed.put(0, 4*14)
+	signed.put(15, 4)
+
+	tests := []struct {
+		name   string
+		format uint32
+		block  []byte
+		want   map[int][3]uint16 // half-float bits of some texels
+	}{
+		{
+			name:   "mode 11",
+			format: gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB,
+			block:  raw.b[:],
+			want: map[int][3]uint16{
+				0:  {0, 0x7bff, 0x3e0f},
+				8:  {0x41df, 0x3a20, 0x3e0f},
+				15: {0x7bff, 0, 0x3e0f},
+			},
+		},
+		{
+			name:   "mode 14",
+			format: gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB,
+			block:  wide.b[:],
+			want: map[int][3]uint16{
+				0:  {0x3fff, 0x08d1, 0x7bff},
+				15: {0x3ffc, 0x08d4, 0},
+			},
+		},
+		{
+			name:   "mode 1",
+			format: gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB,
+			block:  deltas.b[:],
+			want: map[int][3]uint16{
+				0:  {0x3e0f, 0x3e0f, 0x3e0f},
+				7:  {0x3fe0, 0x3c1f, 0x3e0f},
+				8:  {0x3e0f, 0x3df0, 0x3cba},
+				9:  {0x3df0, 0x3e2e, 0x3c1f},
+				15: {0x3df0, 0x3e2e, 0x3c1f},
+			},
+		},
+		{
+			name:   "signed mode 11",
+			format: gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB,
+			block:  signed.b[:],
+			want: map[int][3]uint16{
+				0:  {0x805d, 0xfbff, 0x7bff},
+				15: {0, 0x3e1f, 0xfbff},
+			},
+		},
+		{
+			name:   "reserved mode",
+			format: gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB,
+			block:  concat([]byte{0xf3}, bytes.Repeat([]byte{0xff}, 15)),
+			want:   map[int][3]uint16{0: {}, 15: {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := DecodeTexels(tt.format, 4, 4, tt.block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hdr, ok := img.(*RGBA32F)
+			if !ok {
+				t.Fatalf("decoded to %T, want *RGBA32F", img)
+			}
+			for i, h := range tt.want {
+				want := [4]float32{halfToFloat(h[0]), halfToFloat(h[1]), halfToFloat(h[2]), 1}
+				if got := hdr.RGBA32FAt(i%4, i/4); got != want {
+					t.Errorf("texel %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// ktx2File returns a KTX2 file with one level of data in the given Vulkan
+// format.
+func ktx2File(vkFormat, width, height uint32, data []byte) []byte {
+	var b bytes.Buffer
+	b.Write(ktx2Identifier)
+	binary.Write(&b, binary.LittleEndian, []uint32{vkFormat, 1, width, height, 0, 0, 1, 1, 0, 0, 0, 0, 0})
+	b.Write(make([]byte, 16)) // no supercompression global data
+	offset := uint64(b.Len() + 24)
+	binary.Write(&b, binary.LittleEndian, []uint64{offset, uint64(len(data)), uint64(len(data))})
+	b.Write(data)
+	return b.Bytes()
+}
+
+// ktx1File returns a little-endian KTX file with one level of RGBA8 data.
+func ktx1File(width, height uint32, data []byte) []byte {
+	var b bytes.Buffer
+	b.Write(ktx1Identifier)
+	binary.Write(&b, binary.LittleEndian, []uint32{0x04030201, gl.UNSIGNED_BYTE, 1, gl.RGBA, gl.RGBA8, gl.RGBA, width, height, 0, 0, 1, 1, 0, uint32(len(data))})
+	b.Write(data)
+	return b.Bytes()
+}
+
+func TestReadTextureFileSizes(t *testing.T) {
+	pixels := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	for name, b := range map[string][]byte{"KTX": ktx1File(2, 2, pixels), "KTX2": ktx2File(37, 2, 2, pixels)} {
+		f, err := ReadKTX(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if f.Width != 2 || f.Height != 2 || !bytes.Equal(f.Levels[0][0], pixels) {
+			t.Errorf("%s: read %dx%d %v, want 2x2 %v", name, f.Width, f.Height, f.Levels[0][0], pixels)
+		}
+	}
+
+	// Sizes whose level sizes overflow int must be rejected, not panic.
+	huge := map[string][]byte{
+		// 168 bytes: RGBA8, 0xFFFFFFFF x 0xFFFFFFFF, one level.
+		"KTX2 huge":      ktx2File(37, 0xffffffff, 0xffffffff, make([]byte, 64)),
+		"KTX2 too wide":  ktx2File(37, 1<<29, 1, make([]byte, 64)),
+		"KTX huge":       ktx1File(0xffffffff, 0xffffffff, make([]byte, 64)),
+		"KTX zero width": ktx1File(0, 2, pixels),
+	}
+	if b := huge["KTX2 huge"]; len(b) != 168 {
+		t.Fatalf("KTX2 regression file is %d bytes, want 168", len(b))
+	}
+	for name, b := range huge {
+		if _, err := ReadKTX(bytes.NewReader(b)); err == nil {
+			t.Errorf("%s: ReadKTX succeeded", name)
+		}
+	}
+
+	dds := make([]byte, ddsHeaderLength)
+	copy(dds, "DDS ")
+	binary.LittleEndian.PutUint32(dds[12:], 0xffffffff)
+	binary.LittleEndian.PutUint32(dds[16:], 0xffffffff)
+	if _, err := ReadDDS(bytes.NewReader(dds)); err == nil {
+		t.Error("DDS huge: ReadDDS succeeded")
+	}
+}
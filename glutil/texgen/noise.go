@@ -0,0 +1,127 @@
+package texgen
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Noise is gradient and value noise over the plane from a seeded
+// permutation table. Its methods return values in about [-1, 1] and repeat
+// every 256 units.
+type Noise struct {
+	perm [512]uint8
+	// values holds the lattice values of Value.
+	values [256]float64
+}
+
+// NewNoise returns the noise for seed. The same seed gives the same noise
+// on every platform and run.
+func NewNoise(seed int64) *Noise {
+	r := rand.New(rand.NewSource(seed))
+	n := &Noise{}
+	for i, p := range r.Perm(256) {
+		n.perm[i] = uint8(p)
+		n.perm[i+256] = uint8(p)
+	}
+	for i := range n.values {
+		n.values[i] = r.Float64()*2 - 1
+	}
+	return n
+}
+
+// hash returns the permutation hash of lattice point i, j.
+func (n *Noise) hash(i, j int) uint8 {
+	return n.perm[int(n.perm[i&255])+j&255]
+}
+
+// Value returns smoothly interpolated random values set at the integer
+// lattice points.
+func (n *Noise) Value(x, y float64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	i, j := int(x0), int(y0)
+	u, v := fade(x-x0), fade(y-y0)
+	at := func(di, dj int) float64 { return n.values[n.hash(i+di, j+dj)] }
+	return lerp(lerp(at(0, 0), at(1, 0), u), lerp(at(0, 1), at(1, 1), u), v)
+}
+
+// gradients2 are the gradient directions of Perlin and Simplex.
+var gradients2 = [8][2]float64{
+	{1, 1}, {-1, 1}, {1, -1}, {-1, -1},
+	{1, 0}, {-1, 0}, {0, 1}, {0, -1},
+}
+
+func (n *Noise) grad(i, j int, x, y float64) float64 {
+	g := gradients2[n.hash(i, j)&7]
+	return g[0]*x + g[1]*y
+}
+
+// Perlin returns Ken Perlin's improved gradient noise, zero at the integer
+// lattice points.
+func (n *Noise) Perlin(x, y float64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	i, j := int(x0), int(y0)
+	fx, fy := x-x0, y-y0
+	u, v := fade(fx), fade(fy)
+	a := lerp(n.grad(i, j, fx, fy), n.grad(i+1, j, fx-1, fy), u)
+	b := lerp(n.grad(i, j+1, fx, fy-1), n.grad(i+1, j+1, fx-1, fy-1), u)
+	return lerp(a, b, v)
+}
+
+// Skew factors between the square lattice and the simplex grid.
+var (
+	simplexF2 = 0.5 * (math.Sqrt(3) - 1)
+	simplexG2 = (3 - math.Sqrt(3)) / 6
+)
+
+// Simplex returns 2D simplex noise, which has fewer directional artefacts
+// than Perlin noise and sums three corners instead of four.
+func (n *Noise) Simplex(x, y float64) float64 {
+	s := (x + y) * simplexF2
+	i, j := int(math.Floor(x+s)), int(math.Floor(y+s))
+	t := float64(i+j) * simplexG2
+	x0, y0 := x-(float64(i)-t), y-(float64(j)-t)
+	di, dj := 0, 1
+	if x0 > y0 {
+		di, dj = 1, 0
+	}
+	x1, y1 := x0-float64(di)+simplexG2, y0-float64(dj)+simplexG2
+	x2, y2 := x0-1+2*simplexG2, y0-1+2*simplexG2
+	corner := func(i, j int, x, y float64) float64 {
+		t := 0.5 - x*x - y*y
+		if t < 0 {
+			return 0
+		}
+		t *= t
+		return t * t * n.grad(i, j, x, y)
+	}
+	return 70 * (corner(i, j, x0, y0) + corner(i+di, j+dj, x1, y1) + corner(i+1, j+1, x2, y2))
+}
+
+// FBM sums octaves of f, fractional Brownian motion: each octave scales
+// the frequency by lacunarity and the amplitude by gain. The sum is
+// divided by the total amplitude so it stays in f's range.
+func FBM(f Field, octaves int, lacunarity, gain float64) Field {
+	return func(x, y float64) float64 {
+		sum, amplitude, total, frequency := 0.0, 1.0, 0.0, 1.0
+		for o := 0; o < octaves; o++ {
+			// Offset each octave so their lattices do not line up at 0.
+			sum += amplitude * f(x*frequency+float64(o)*19.19, y*frequency+float64(o)*7.73)
+			total += amplitude
+			amplitude *= gain
+			frequency *= lacunarity
+		}
+		if total == 0 {
+			return 0
+		}
+		return sum / total
+	}
+}
+
+// fade is Perlin's quintic smoothstep.
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
@@ -0,0 +1,95 @@
+package texgen
+
+import (
+	"math"
+	"testing"
+)
+
+// The noise values are checked to a tolerance rather than exactly, as
+// platforms that fuse multiply-adds may differ in the last bits.
+const noiseTolerance = 1e-9
+
+func TestNewNoise(t *testing.T) {
+	n := NewNoise(1)
+	want := [8]uint8{130, 4, 133, 49, 108, 178, 125, 95}
+	var got [8]uint8
+	copy(got[:], n.perm[:])
+	if got != want {
+		t.Errorf("NewNoise(1) permutation starts %v, want %v", got, want)
+	}
+	if n.perm[256] != n.perm[0] || n.perm[511] != n.perm[255] {
+		t.Error("permutation is not repeated in its second half")
+	}
+	if math.Abs(n.values[0]-0.32278636116668524) > noiseTolerance {
+		t.Errorf("NewNoise(1) first value = %v, want 0.32278636116668524", n.values[0])
+	}
+	if *NewNoise(2) == *n {
+		t.Error("NewNoise(2) equals NewNoise(1)")
+	}
+	if *NewNoise(1) != *n {
+		t.Error("NewNoise(1) differs between calls")
+	}
+}
+
+func TestNoiseGolden(t *testing.T) {
+	n := NewNoise(1)
+	fbm := FBM(n.Perlin, 4, 2, 0.5)
+	tests := []struct {
+		x, y                          float64
+		value, perlin, simplex, fbmed float64
+	}{
+		{0.3, 0.7, -0.151166310667423, 0.17786547408, -0.674752241069751, 0.0417033903291945},
+		{1.25, 3.75, 0.488954406753969, -0.0312366485595703, 0.78248520972724, 0.137663539354118},
+		{-2.3, 7.1, -0.623453091374128, 0.1584639648, 0.737891663818672, 0.0578325922038496},
+		{100.9, -40.2, -0.440560590636417, 0.152787363839994, -0.365325225082849, 0.00699182109791422},
+	}
+	for _, tt := range tests {
+		for _, c := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"Value", n.Value(tt.x, tt.y), tt.value},
+			{"Perlin", n.Perlin(tt.x, tt.y), tt.perlin},
+			{"Simplex", n.Simplex(tt.x, tt.y), tt.simplex},
+			{"FBM(Perlin)", fbm(tt.x, tt.y), tt.fbmed},
+		} {
+			if math.Abs(c.got-c.want) > noiseTolerance {
+				t.Errorf("%s(%v, %v) = %.15g, want %.15g", c.name, tt.x, tt.y, c.got, c.want)
+			}
+		}
+	}
+}
+
+func TestNoiseProperties(t *testing.T) {
+	n := NewNoise(7)
+	for _, p := range [][2]float64{{0, 0}, {3, -5}, {255, 17}} {
+		if v := n.Perlin(p[0], p[1]); v != 0 {
+			t.Errorf("Perlin(%v, %v) = %v, want 0 at a lattice point", p[0], p[1], v)
+		}
+	}
+	for _, p := range [][2]float64{{0.3, 0.7}, {12.5, -3.25}} {
+		x, y := p[0], p[1]
+		for _, f := range []struct {
+			name string
+			f    Field
+		}{{"Value", n.Value}, {"Perlin", n.Perlin}} {
+			if a, b := f.f(x, y), f.f(x+256, y-256); math.Abs(a-b) > noiseTolerance {
+				t.Errorf("%s does not repeat every 256 units at (%v, %v): %v and %v", f.name, x, y, a, b)
+			}
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		x, y := float64(i)*0.37, float64(i)*-0.53
+		for _, v := range []float64{n.Value(x, y), n.Perlin(x, y), n.Simplex(x, y)} {
+			if v < -1.0001 || v > 1.0001 {
+				t.Fatalf("noise at (%v, %v) = %v, outside [-1, 1]", x, y, v)
+			}
+		}
+	}
+	if v := FBM(n.Perlin, 0, 2, 0.5)(0.3, 0.7); v != 0 {
+		t.Errorf("FBM with no octaves = %v, want 0", v)
+	}
+	if a, b := FBM(n.Perlin, 1, 2, 0.5)(0.3, 0.7), n.Perlin(0.3, 0.7); a != b {
+		t.Errorf("FBM with one octave = %v, want Perlin's %v", a, b)
+	}
+}
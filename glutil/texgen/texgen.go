@@ -0,0 +1,216 @@
+// Package texgen generates textures in Go: test patterns, gradients,
+// noise and normal maps. Every generator returns an image.Image ready for
+// glutil.NewTextureFromImage, and the noise generators return the same
+// pixels for the same seed. It needs no GL context.
+package texgen
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Checker returns a width x height checkerboard of cell-pixel squares,
+// starting with a in the top-left corner.
+func Checker(width, height, cell int, a, b color.Color) *image.NRGBA {
+	if cell < 1 {
+		cell = 1
+	}
+	ca, cb := nrgba(a), nrgba(b)
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := ca
+			if (x/cell+y/cell)%2 == 1 {
+				c = cb
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// Grid returns a width x height image of background with line-pixel lines
+// of colour fg every cell pixels, including along the top and left edges.
+func Grid(width, height, cell, line int, fg, background color.Color) *image.NRGBA {
+	if cell < 1 {
+		cell = 1
+	}
+	cf, cb := nrgba(fg), nrgba(background)
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := cb
+			if x%cell < line || y%cell < line {
+				c = cf
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// UVDebug returns a width x height pattern for checking texture
+// coordinates. Red rises with u to the right and green with v towards the
+// bottom row, which is v = 0 once uploaded with FlipY. A cells x cells
+// checkerboard in blue shows the scale and a white border marks the edges.
+func UVDebug(width, height, cells int) *image.NRGBA {
+	if cells < 1 {
+		cells = 1
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			u := (float64(x) + 0.5) / float64(width)
+			v := 1 - (float64(y)+0.5)/float64(height)
+			c := color.NRGBA{unit8(u), unit8(v), 0x40, 0xff}
+			if (x*cells/width+y*cells/height)%2 == 0 {
+				c.B = 0xc0
+			}
+			if x == 0 || y == 0 || x == width-1 || y == height-1 {
+				c = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// Stop is a colour at a position along a gradient, from 0 to 1.
+type Stop struct {
+	At    float64
+	Color color.Color
+}
+
+// LinearGradient returns a width x height gradient through stops, sorted
+// by At, running from pixel from to pixel to. Pixels beyond either end
+// take the colour of the nearest stop. Colours are blended straight, in
+// the colour space they are given in.
+func LinearGradient(width, height int, from, to image.Point, stops []Stop) *image.NRGBA {
+	dx, dy := float64(to.X-from.X), float64(to.Y-from.Y)
+	length2 := dx*dx + dy*dy
+	if length2 == 0 {
+		length2 = 1
+	}
+	return gradient(width, height, stops, func(x, y float64) float64 {
+		return ((x-float64(from.X))*dx + (y-float64(from.Y))*dy) / length2
+	})
+}
+
+// RadialGradient returns a width x height gradient through stops, sorted
+// by At, running from centre out to radius pixels.
+func RadialGradient(width, height int, centre image.Point, radius float64, stops []Stop) *image.NRGBA {
+	if radius <= 0 {
+		radius = 1
+	}
+	return gradient(width, height, stops, func(x, y float64) float64 {
+		return math.Hypot(x-float64(centre.X), y-float64(centre.Y)) / radius
+	})
+}
+
+// gradient colours each pixel by the stops at position t(x, y) of the
+// pixel centre.
+func gradient(width, height int, stops []Stop, t func(x, y float64) float64) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if len(stops) == 0 {
+		return img
+	}
+	colors := make([]color.NRGBA, len(stops))
+	for i, s := range stops {
+		colors[i] = nrgba(s.Color)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			at := t(float64(x)+0.5, float64(y)+0.5)
+			i := 0
+			for i < len(stops) && stops[i].At <= at {
+				i++
+			}
+			var c color.NRGBA
+			switch {
+			case i == 0:
+				c = colors[0]
+			case i == len(stops):
+				c = colors[len(stops)-1]
+			default:
+				a, b := stops[i-1], stops[i]
+				c = lerpNRGBA(colors[i-1], colors[i], (at-a.At)/(b.At-a.At))
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// Field is a scalar function of the plane, such as a noise function.
+type Field func(x, y float64) float64
+
+// Gray samples f at the centre of each pixel of a width x height image,
+// scale pixels to one unit of the field, and maps values from [-1, 1] to
+// black through white. Values outside the range are clamped.
+func Gray(width, height int, scale float64, f Field) *image.Gray16 {
+	if scale <= 0 {
+		scale = 1
+	}
+	img := image.NewGray16(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := f((float64(x)+0.5)/scale, (float64(y)+0.5)/scale)
+			img.SetGray16(x, y, color.Gray16{unit16(v*0.5 + 0.5)})
+		}
+	}
+	return img
+}
+
+// NormalMap converts a height map, black low and white high, into a
+// tangent-space normal map with +Y up the image, as OpenGL expects.
+// strength scales the slopes; the map wraps around at the edges so tiling
+// height maps give tiling normal maps.
+func NormalMap(height image.Image, strength float64) *image.NRGBA {
+	b := height.Bounds()
+	w, h := b.Dx(), b.Dy()
+	heights := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gray := color.Gray16Model.Convert(height.At(b.Min.X+x, b.Min.Y+y)).(color.Gray16)
+			heights[y*w+x] = float64(gray.Y) / 0xffff
+		}
+	}
+	at := func(x, y int) float64 {
+		return heights[((y+h)%h)*w+(x+w)%w]
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Sobel filter; image rows run down, so the row slope is negated
+			// for +Y up.
+			sx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			sy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			nx, ny, nz := -sx*strength, sy*strength, 1.0
+			l := math.Sqrt(nx*nx + ny*ny + nz*nz)
+			img.SetNRGBA(x, y, color.NRGBA{unit8(nx/l*0.5 + 0.5), unit8(ny/l*0.5 + 0.5), unit8(nz/l*0.5 + 0.5), 0xff})
+		}
+	}
+	return img
+}
+
+func nrgba(c color.Color) color.NRGBA {
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
+
+func lerpNRGBA(a, b color.NRGBA, t float64) color.NRGBA {
+	l := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return color.NRGBA{l(a.R, b.R), l(a.G, b.G), l(a.B, b.B), l(a.A, b.A)}
+}
+
+// unit8 maps [0, 1] to a byte, clamping.
+func unit8(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 0xff))
+}
+
+// unit16 maps [0, 1] to a 16-bit value, clamping.
+func unit16(v float64) uint16 {
+	return uint16(math.Round(math.Max(0, math.Min(1, v)) * 0xffff))
+}
@@ -0,0 +1,91 @@
+package texgen
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	// Hashes of the pixels. After an intended change, update them from
+	// the failure messages.
+	tests := []struct {
+		name string
+		img  *image.NRGBA
+		want string
+	}{
+		{"UVDebug", UVDebug(16, 8, 4), "3a09b952977ff4c293cec62e196ee180ecdc94f0e84ba19ccf3a4355cd25b086"},
+		{"NormalMap", NormalMap(Checker(16, 16, 4, color.Black, color.White), 1), "7682588e73ba04b0d2347d6a4347f5292a0e93f917f9ecf982b76ec3d584bce6"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%x", sha256.Sum256(tt.img.Pix)); got != tt.want {
+			t.Errorf("%s pixels hash to %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUVDebug(t *testing.T) {
+	img := UVDebug(16, 8, 4)
+	white := color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	for _, p := range []image.Point{{0, 0}, {15, 3}, {7, 7}, {0, 5}} {
+		if got := img.NRGBAAt(p.X, p.Y); got != white {
+			t.Errorf("border pixel %v = %v, want white", p, got)
+		}
+	}
+	tests := []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		// u = 1.5/16, v = 1 - 1.5/8; cell (0, 0) is light.
+		{1, 1, color.NRGBA{24, 207, 0xc0, 0xff}},
+		// u = 4.5/16, v = 1 - 1.5/8; cell (1, 0) is dark.
+		{4, 1, color.NRGBA{72, 207, 0x40, 0xff}},
+		// u = 14.5/16, v = 1 - 6.5/8; cell (3, 3) is light.
+		{14, 6, color.NRGBA{231, 48, 0xc0, 0xff}},
+	}
+	for _, tt := range tests {
+		if got := img.NRGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("UVDebug pixel (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestNormalMap(t *testing.T) {
+	// A white ridge down the middle column of a 3-wide map that wraps:
+	// the left column slopes up to the right, the right column down.
+	height := image.NewGray(image.Rect(0, 0, 3, 3))
+	for y := 0; y < 3; y++ {
+		height.SetGray(1, y, color.Gray{0xff})
+	}
+	// With strength 1/4 the Sobel slope of 4 gives normals at 45 degrees.
+	img := NormalMap(height, 0.25)
+	want := [3]color.NRGBA{
+		{37, 128, 218, 0xff},
+		{128, 128, 255, 0xff},
+		{218, 128, 218, 0xff},
+	}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			if got := img.NRGBAAt(x, y); got != want[x] {
+				t.Errorf("normal (%d, %d) = %v, want %v", x, y, got, want[x])
+			}
+		}
+	}
+
+	// Rotated a quarter turn, the ridge runs across and the map tilts in
+	// green. +Y is up the image, so the row above the ridge rises towards
+	// -Y and its normal leans to +Y.
+	height = image.NewGray(image.Rect(0, 0, 3, 3))
+	for x := 0; x < 3; x++ {
+		height.SetGray(x, 1, color.Gray{0xff})
+	}
+	img = NormalMap(height, 0.25)
+	if got, want := img.NRGBAAt(0, 0), (color.NRGBA{128, 218, 218, 0xff}); got != want {
+		t.Errorf("normal above the ridge = %v, want %v", got, want)
+	}
+	if got, want := img.NRGBAAt(0, 2), (color.NRGBA{128, 37, 218, 0xff}); got != want {
+		t.Errorf("normal below the ridge = %v, want %v", got, want)
+	}
+}
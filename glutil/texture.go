@@ -0,0 +1,225 @@
+package glutil
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// TextureOptions configures the sampling and storage of a texture. The
+// zero value gives LINEAR filtering, CLAMP_TO_EDGE wrapping, storage
+// matching the image and no mipmaps.
+type TextureOptions struct {
+	// MinFilter and MagFilter are gl.NEAREST, gl.LINEAR or, for MinFilter
+	// with Mipmaps, one of the gl.*_MIPMAP_* filters. Zero means
+	// gl.LINEAR, or gl.LINEAR_MIPMAP_LINEAR for MinFilter with Mipmaps.
+	MinFilter, MagFilter int32
+	// Mipmaps generates the full mipmap chain after upload.
+	Mipmaps bool
+	// WrapS, WrapT and WrapR are gl.REPEAT, gl.MIRRORED_REPEAT,
+	// gl.CLAMP_TO_EDGE or gl.CLAMP_TO_BORDER. Zero means gl.CLAMP_TO_EDGE.
+	WrapS, WrapT, WrapR int32
+	// BorderColor is sampled outside the texture with gl.CLAMP_TO_BORDER.
+	BorderColor [4]float32
+	// Anisotropy is the maximum anisotropic filtering ratio. It is clamped
+	// to what the driver supports and ignored without the anisotropic
+	// filtering extension. Values up to 1 disable it.
+	Anisotropy float32
+	// InternalFormat is how the texels are stored, such as gl.RGBA8 or
+	// gl.RGB8. Zero means gl.RGBA for 8-bit images, gl.RGBA16 for 16-bit
+	// images and gl.RGBA16F for float images such as Radiance HDR files.
+	InternalFormat int32
+	// FlipY flips the image on load. Image files store the top row first
+	// but GL puts texture coordinate (0, 0) at the first row it is given,
+	// so with FlipY (0, 0) is the bottom-left corner of the picture.
+	FlipY bool
+	// SRGB stores colour textures in the matching sRGB internal format, so
+	// sampling returns linear values for lighting and blending. There are
+	// only 8-bit sRGB formats. Leave it off for linear data such as normal
+	// maps, height maps and float images.
+	SRGB bool
+}
+
+// internalFormat returns the internal format to store texels of the
+// given component type in.
+func (o TextureOptions) internalFormat(xtype uint32) (int32, error) {
+	var format int32
+	switch xtype {
+	case gl.UNSIGNED_SHORT:
+		format = orDefault(o.InternalFormat, gl.RGBA16)
+	case gl.FLOAT:
+		format = orDefault(o.InternalFormat, gl.RGBA16F)
+		if o.SRGB {
+			return 0, fmt.Errorf("texture: float images are linear and cannot be sRGB")
+		}
+	default:
+		format = orDefault(o.InternalFormat, gl.RGBA)
+	}
+	if !o.SRGB {
+		return format, nil
+	}
+	switch format {
+	case gl.RGBA, gl.RGBA8, gl.RGBA16, gl.SRGB8_ALPHA8:
+		return gl.SRGB8_ALPHA8, nil
+	case gl.RGB, gl.RGB8, gl.RGB16, gl.SRGB8:
+		return gl.SRGB8, nil
+	}
+	return 0, fmt.Errorf("texture: internal format 0x%X has no sRGB variant", format)
+}
+
+// flipRows reverses the order of the rows of stride bytes in pix.
+func flipRows(pix []byte, stride int) {
+	tmp := make([]byte, stride)
+	for top, bottom := 0, len(pix)-stride; top < bottom; top, bottom = top+stride, bottom-stride {
+		copy(tmp, pix[top:top+stride])
+		copy(pix[top:top+stride], pix[bottom:bottom+stride])
+		copy(pix[bottom:bottom+stride], tmp)
+	}
+}
+
+func (o TextureOptions) minFilter() int32 {
+	switch {
+	case o.MinFilter != 0:
+		return o.MinFilter
+	case o.Mipmaps:
+		return gl.LINEAR_MIPMAP_LINEAR
+	}
+	return gl.LINEAR
+}
+
+func orDefault(v, def int32) int32 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// validate rejects a mipmap MinFilter without mipmaps, which would leave
+// the texture incomplete and sampling black, and SRGB with an internal
+// format that has no sRGB variant.
+func (o TextureOptions) validate() error {
+	switch o.minFilter() {
+	case gl.NEAREST_MIPMAP_NEAREST, gl.NEAREST_MIPMAP_LINEAR, gl.LINEAR_MIPMAP_NEAREST, gl.LINEAR_MIPMAP_LINEAR:
+		if !o.Mipmaps {
+			return fmt.Errorf("texture: mipmap MinFilter 0x%X needs Mipmaps", o.MinFilter)
+		}
+	}
+	if o.InternalFormat != 0 {
+		_, err := o.internalFormat(gl.UNSIGNED_BYTE)
+		return err
+	}
+	return nil
+}
+
+// apply sets the sampling parameters of the texture bound to target.
+func (o TextureOptions) apply(target uint32) {
+	gl.TexParameteri(target, gl.TEXTURE_MIN_FILTER, o.minFilter())
+	gl.TexParameteri(target, gl.TEXTURE_MAG_FILTER, orDefault(o.MagFilter, gl.LINEAR))
+	gl.TexParameteri(target, gl.TEXTURE_WRAP_S, orDefault(o.WrapS, gl.CLAMP_TO_EDGE))
+	gl.TexParameteri(target, gl.TEXTURE_WRAP_T, orDefault(o.WrapT, gl.CLAMP_TO_EDGE))
+	if target == gl.TEXTURE_3D || target == gl.TEXTURE_CUBE_MAP {
+		gl.TexParameteri(target, gl.TEXTURE_WRAP_R, orDefault(o.WrapR, gl.CLAMP_TO_EDGE))
+	}
+	if o.BorderColor != ([4]float32{}) {
+		gl.TexParameterfv(target, gl.TEXTURE_BORDER_COLOR, &o.BorderColor[0])
+	}
+	if o.Anisotropy > 1 && (HasExtension("GL_EXT_texture_filter_anisotropic") || HasExtension("GL_ARB_texture_filter_anisotropic")) {
+		var max float32
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &max)
+		if o.Anisotropy < max {
+			max = o.Anisotropy
+		}
+		gl.TexParameterf(target, gl.TEXTURE_MAX_ANISOTROPY, max)
+	}
+}
+
+// HasExtension reports whether the current context supports the named
+// extension, such as "GL_EXT_texture_filter_anisotropic".
+func HasExtension(name string) bool {
+	var n int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &n)
+	for i := uint32(0); i < uint32(n); i++ {
+		if gl.GoStr(gl.GetStringi(gl.EXTENSIONS, i)) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTexture loads an image file into a new RGBA 2D texture bound to
+// texture unit 0, with the default TextureOptions.
+func NewTexture(file string) (uint32, error) {
+	return LoadTexture(file, TextureOptions{})
+}
+
+// LoadTexture loads an image file into a new 2D texture bound to texture
+// unit 0, sampled and stored as opts says. The file is decoded with
+// DecodeImage; 16-bit images keep their precision and HDR images become
+// float textures. KTX, KTX2 and DDS files are read with ReadTextureFile
+// and keep their format and mip levels, as TextureFile.Upload describes.
+func LoadTexture(file string, opts TextureOptions) (uint32, error) {
+	if isTextureFile(file) {
+		f, err := ReadTextureFile(file)
+		if err != nil {
+			return 0, err
+		}
+		texture, err := f.Upload(opts)
+		if err != nil {
+			return 0, fmt.Errorf("texture %q: %v", file, err)
+		}
+		return texture, nil
+	}
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	imgFile, err := os.Open(file)
+	if err != nil {
+		return 0, fmt.Errorf("texture %q not found on disk: %v", file, err)
+	}
+	defer imgFile.Close()
+	img, err := DecodeImage(file, imgFile)
+	if err != nil {
+		return 0, err
+	}
+	texture, err := NewTextureFromImage(img, opts)
+	if err != nil {
+		return 0, fmt.Errorf("texture %q: %v", file, err)
+	}
+	return texture, nil
+}
+
+// NewTextureFromImage uploads img into a new 2D texture bound to texture
+// unit 0, as LoadTexture does for image files.
+func NewTextureFromImage(img image.Image, opts TextureOptions) (uint32, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	t := newTexels(img)
+	if opts.FlipY {
+		flipRows(t.pix, t.stride)
+	}
+	return t.newTexture(opts)
+}
+
+// newTexture uploads t into a new 2D texture bound to texture unit 0. The
+// rows must already be flipped if opts asks for it.
+func (t texels) newTexture(opts TextureOptions) (uint32, error) {
+	format, err := opts.internalFormat(t.xtype)
+	if err != nil {
+		return 0, err
+	}
+
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	opts.apply(gl.TEXTURE_2D)
+	t.upload(gl.TEXTURE_2D, 0, format)
+	if opts.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+
+	return texture, nil
+}
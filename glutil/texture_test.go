@@ -0,0 +1,101 @@
+package glutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+func TestFlipRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		pix    string
+		stride int
+		want   string
+	}{
+		{"even rows", "aabbccdd", 2, "ddccbbaa"},
+		{"odd rows", "aabbccddee", 2, "eeddccbbaa"},
+		{"one row", "abc", 3, "abc"},
+		{"empty", "", 4, ""},
+		// Rows of 3 pixels padded to a 4-byte stride move with their
+		// padding.
+		{"padded stride", "123.456.789.", 4, "789.456.123."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pix := []byte(tt.pix)
+			flipRows(pix, tt.stride)
+			if !bytes.Equal(pix, []byte(tt.want)) {
+				t.Errorf("flipRows(%q, %d) = %q, want %q", tt.pix, tt.stride, pix, tt.want)
+			}
+		})
+	}
+}
+
+func TestInternalFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  TextureOptions
+		xtype uint32
+		want  int32 // 0 when an error is expected
+	}{
+		{"8-bit default", TextureOptions{}, gl.UNSIGNED_BYTE, gl.RGBA},
+		{"16-bit default", TextureOptions{}, gl.UNSIGNED_SHORT, gl.RGBA16},
+		{"float default", TextureOptions{}, gl.FLOAT, gl.RGBA16F},
+		{"explicit", TextureOptions{InternalFormat: gl.RGB8}, gl.UNSIGNED_BYTE, gl.RGB8},
+		{"sRGB default", TextureOptions{SRGB: true}, gl.UNSIGNED_BYTE, gl.SRGB8_ALPHA8},
+		{"sRGB RGBA", TextureOptions{SRGB: true, InternalFormat: gl.RGBA}, gl.UNSIGNED_BYTE, gl.SRGB8_ALPHA8},
+		{"sRGB RGBA8", TextureOptions{SRGB: true, InternalFormat: gl.RGBA8}, gl.UNSIGNED_BYTE, gl.SRGB8_ALPHA8},
+		{"sRGB RGB8", TextureOptions{SRGB: true, InternalFormat: gl.RGB8}, gl.UNSIGNED_BYTE, gl.SRGB8},
+		// sRGB formats are 8-bit, so 16-bit images are stored in 8 bits.
+		{"sRGB RGBA16", TextureOptions{SRGB: true, InternalFormat: gl.RGBA16}, gl.UNSIGNED_BYTE, gl.SRGB8_ALPHA8},
+		{"sRGB 16-bit image", TextureOptions{SRGB: true}, gl.UNSIGNED_SHORT, gl.SRGB8_ALPHA8},
+		{"sRGB float image", TextureOptions{SRGB: true}, gl.FLOAT, 0},
+		{"sRGB float format", TextureOptions{SRGB: true, InternalFormat: gl.RGBA16F}, gl.UNSIGNED_BYTE, 0},
+		{"sRGB red", TextureOptions{SRGB: true, InternalFormat: gl.R8}, gl.UNSIGNED_BYTE, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.internalFormat(tt.xtype)
+			if tt.want == 0 {
+				if err == nil {
+					t.Fatalf("internalFormat = 0x%X, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("internalFormat = 0x%X, want 0x%X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextureOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TextureOptions
+		ok   bool
+	}{
+		{"zero", TextureOptions{}, true},
+		{"mipmaps default filter", TextureOptions{Mipmaps: true}, true},
+		{"mipmap filter with mipmaps", TextureOptions{MinFilter: gl.NEAREST_MIPMAP_LINEAR, Mipmaps: true}, true},
+		{"linear without mipmaps", TextureOptions{MinFilter: gl.LINEAR}, true},
+		{"LINEAR_MIPMAP_LINEAR without mipmaps", TextureOptions{MinFilter: gl.LINEAR_MIPMAP_LINEAR}, false},
+		{"NEAREST_MIPMAP_NEAREST without mipmaps", TextureOptions{MinFilter: gl.NEAREST_MIPMAP_NEAREST}, false},
+		{"LINEAR_MIPMAP_NEAREST without mipmaps", TextureOptions{MinFilter: gl.LINEAR_MIPMAP_NEAREST}, false},
+		{"NEAREST_MIPMAP_LINEAR without mipmaps", TextureOptions{MinFilter: gl.NEAREST_MIPMAP_LINEAR}, false},
+		{"sRGB RGB8", TextureOptions{SRGB: true, InternalFormat: gl.RGB8}, true},
+		{"sRGB float format", TextureOptions{SRGB: true, InternalFormat: gl.RGBA32F}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.validate(); (err == nil) != tt.ok {
+				t.Errorf("validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
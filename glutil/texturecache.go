@@ -0,0 +1,144 @@
+package glutil
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// TextureCache shares textures loaded from files. Loading the same file
+// with the same options again returns the same texture and adds a
+// reference; the texture is deleted when the last reference is released.
+// The zero value is ready to use. It must only be used on the thread that
+// owns the GL context.
+type TextureCache struct {
+	byKey     map[textureKey]*cachedTexture
+	byTexture map[uint32]*cachedTexture
+}
+
+type textureKey struct {
+	file string
+	opts TextureOptions
+}
+
+type cachedTexture struct {
+	key     textureKey
+	texture uint32
+	refs    int
+	bytes   int64
+}
+
+// Load returns the texture for file loaded with opts, loading it with
+// LoadTexture the first time. Each Load must be paired with a Release.
+func (c *TextureCache) Load(file string, opts TextureOptions) (uint32, error) {
+	if c.byKey == nil {
+		c.byKey = map[textureKey]*cachedTexture{}
+		c.byTexture = map[uint32]*cachedTexture{}
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	key := textureKey{file, opts}
+	if e, ok := c.byKey[key]; ok {
+		e.refs++
+		return e.texture, nil
+	}
+	texture, err := LoadTexture(file, opts)
+	if err != nil {
+		return 0, err
+	}
+	target := uint32(gl.TEXTURE_2D)
+	if isTextureFile(file) {
+		// A container file may hold a cube map.
+		var binding int32
+		gl.GetIntegerv(gl.TEXTURE_BINDING_CUBE_MAP, &binding)
+		if uint32(binding) == texture {
+			target = gl.TEXTURE_CUBE_MAP
+		}
+	}
+	e := &cachedTexture{key: key, texture: texture, refs: 1, bytes: TextureMemory(texture, target)}
+	c.byKey[key] = e
+	c.byTexture[texture] = e
+	return texture, nil
+}
+
+// Release drops a reference to texture and deletes it when none are left.
+func (c *TextureCache) Release(texture uint32) {
+	e, ok := c.byTexture[texture]
+	if !ok {
+		log.Printf("texture cache: release of unknown texture %d", texture)
+		return
+	}
+	e.refs--
+	if e.refs > 0 {
+		return
+	}
+	gl.DeleteTextures(1, &e.texture)
+	delete(c.byKey, e.key)
+	delete(c.byTexture, texture)
+}
+
+// Len returns the number of textures the cache holds.
+func (c *TextureCache) Len() int {
+	return len(c.byTexture)
+}
+
+// MemoryUsage returns the estimated GPU memory, in bytes, of the textures
+// the cache holds, mip levels included.
+func (c *TextureCache) MemoryUsage() int64 {
+	var total int64
+	for _, e := range c.byTexture {
+		total += e.bytes
+	}
+	return total
+}
+
+// TextureMemory estimates the GPU memory, in bytes, of every level of
+// texture, a gl.TEXTURE_2D or gl.TEXTURE_CUBE_MAP, from the sizes and
+// internal formats the driver reports. It binds texture to target on the
+// active texture unit.
+func TextureMemory(texture, target uint32) int64 {
+	gl.BindTexture(target, texture)
+	levelTarget, faces := target, int64(1)
+	if target == gl.TEXTURE_CUBE_MAP {
+		levelTarget, faces = gl.TEXTURE_CUBE_MAP_POSITIVE_X, 6
+	}
+	var total int64
+	for level := int32(0); level < 32; level++ {
+		var width, height, compressed, format int32
+		gl.GetTexLevelParameteriv(levelTarget, level, gl.TEXTURE_WIDTH, &width)
+		gl.GetTexLevelParameteriv(levelTarget, level, gl.TEXTURE_HEIGHT, &height)
+		if width == 0 || height == 0 {
+			break
+		}
+		gl.GetTexLevelParameteriv(levelTarget, level, gl.TEXTURE_COMPRESSED, &compressed)
+		if compressed != 0 {
+			var size int32
+			gl.GetTexLevelParameteriv(levelTarget, level, gl.TEXTURE_COMPRESSED_IMAGE_SIZE, &size)
+			total += int64(size) * faces
+			continue
+		}
+		gl.GetTexLevelParameteriv(levelTarget, level, gl.TEXTURE_INTERNAL_FORMAT, &format)
+		total += int64(width) * int64(height) * int64(texelBytes(uint32(format))) * faces
+	}
+	return total
+}
+
+// texelBytes estimates the bytes a driver stores per texel of an
+// uncompressed internal format. Three-channel formats are assumed padded
+// to four.
+func texelBytes(internalFormat uint32) int {
+	switch internalFormat {
+	case gl.R8, gl.RED:
+		return 1
+	case gl.RG8, gl.RG, gl.R16, gl.R16F:
+		return 2
+	case gl.RGBA16, gl.RGBA16F, gl.RGB16, gl.RGB16F, gl.RG32F:
+		return 8
+	case gl.RGBA32F, gl.RGB32F:
+		return 16
+	}
+	// RGBA8, SRGB8_ALPHA8, RGB8 and the 32-bit depth formats.
+	return 4
+}
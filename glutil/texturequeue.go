@@ -0,0 +1,194 @@
+package glutil
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// TextureQueue loads textures without stalling the render thread. Files
+// are read and decoded on worker goroutines; Upload, called once a frame
+// on the thread that owns the GL context, turns the decoded images into
+// textures. Until then a PendingTexture hands out a placeholder texture.
+type TextureQueue struct {
+	// Budget is the time one Upload call may spend uploading. At least one
+	// texture is uploaded per call, so a texture that takes longer than
+	// the budget still gets through. Zero means no limit.
+	Budget time.Duration
+
+	placeholder uint32
+	workers     chan struct{}
+
+	mu      sync.Mutex
+	queued  int
+	decoded []*PendingTexture
+	closed  bool
+}
+
+// PendingTexture is a texture a TextureQueue is loading.
+type PendingTexture struct {
+	File string
+
+	opts        TextureOptions
+	placeholder uint32
+	texture     uint32
+	err         error
+	deleted     bool
+
+	// Set by the worker before the texture is handed to Upload.
+	texels    *texels
+	file      *TextureFile
+	decodeErr error
+}
+
+// NewTextureQueue returns a queue decoding on up to workers goroutines at
+// once, at least one. It creates the placeholder texture, a grey and
+// magenta checkerboard, so it must be called on the GL thread.
+func NewTextureQueue(workers int) *TextureQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	const a, b = "\x80\x80\x80\xff", "\xff\x00\xff\xff"
+	checker := texels{pix: []byte(a + b + b + a), stride: 8, width: 2, height: 2, xtype: gl.UNSIGNED_BYTE}
+	placeholder, _ := checker.newTexture(TextureOptions{MinFilter: gl.NEAREST, MagFilter: gl.NEAREST, WrapS: gl.REPEAT, WrapT: gl.REPEAT})
+	return &TextureQueue{placeholder: placeholder, workers: make(chan struct{}, workers)}
+}
+
+// Load starts loading file as LoadTexture would and returns at once.
+func (q *TextureQueue) Load(file string, opts TextureOptions) *PendingTexture {
+	t := &PendingTexture{File: file, opts: opts, placeholder: q.placeholder}
+	q.mu.Lock()
+	q.queued++
+	q.mu.Unlock()
+	go func() {
+		q.workers <- struct{}{}
+		t.decode()
+		<-q.workers
+		q.mu.Lock()
+		q.queued--
+		q.decoded = append(q.decoded, t)
+		q.mu.Unlock()
+	}()
+	return t
+}
+
+// decode reads and decodes the file, leaving the texels or texture file
+// for Upload, or the error.
+func (t *PendingTexture) decode() {
+	if isTextureFile(t.File) {
+		t.file, t.decodeErr = ReadTextureFile(t.File)
+		return
+	}
+	if t.decodeErr = t.opts.validate(); t.decodeErr != nil {
+		return
+	}
+	r, err := os.Open(t.File)
+	if err != nil {
+		t.decodeErr = fmt.Errorf("texture %q not found on disk: %v", t.File, err)
+		return
+	}
+	defer r.Close()
+	img, err := DecodeImage(t.File, r)
+	if err != nil {
+		t.decodeErr = err
+		return
+	}
+	texels := newTexels(img)
+	if t.opts.FlipY {
+		flipRows(texels.pix, texels.stride)
+	}
+	t.texels = &texels
+}
+
+// Upload uploads decoded textures until the budget runs out and returns
+// how many it uploaded. Textures are left bound to texture unit 0.
+func (q *TextureQueue) Upload() int {
+	start := time.Now()
+	n := 0
+	for {
+		q.mu.Lock()
+		if q.closed || len(q.decoded) == 0 || (n > 0 && q.Budget > 0 && time.Since(start) >= q.Budget) {
+			q.mu.Unlock()
+			return n
+		}
+		t := q.decoded[0]
+		q.decoded = q.decoded[1:]
+		q.mu.Unlock()
+
+		t.upload()
+		n++
+	}
+}
+
+// upload creates the texture from what the worker decoded. Failures are
+// logged, as the placeholder stays in use.
+func (t *PendingTexture) upload() {
+	var err error
+	switch {
+	case t.deleted:
+	case t.decodeErr != nil:
+		t.err = t.decodeErr
+	case t.file != nil:
+		t.texture, err = t.file.Upload(t.opts)
+	default:
+		t.texture, err = t.texels.newTexture(t.opts)
+	}
+	if err != nil {
+		t.err = fmt.Errorf("texture %q: %v", t.File, err)
+	}
+	if t.err != nil {
+		log.Println(t.err)
+	}
+	t.texels, t.file = nil, nil
+}
+
+// Pending returns the number of textures still being decoded or waiting
+// for Upload.
+func (q *TextureQueue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queued + len(q.decoded)
+}
+
+// Delete deletes the placeholder texture and drops textures that were not
+// uploaded yet. Textures already uploaded belong to their PendingTexture.
+func (q *TextureQueue) Delete() {
+	q.mu.Lock()
+	q.closed = true
+	q.decoded = nil
+	q.mu.Unlock()
+	gl.DeleteTextures(1, &q.placeholder)
+}
+
+// Texture returns the loaded texture, or the placeholder while it is
+// loading or if it failed to load.
+func (t *PendingTexture) Texture() uint32 {
+	if t.texture != 0 {
+		return t.texture
+	}
+	return t.placeholder
+}
+
+// Ready reports whether the texture has been uploaded.
+func (t *PendingTexture) Ready() bool {
+	return t.texture != 0
+}
+
+// Err returns the error loading the texture, once Upload has reached it.
+func (t *PendingTexture) Err() error {
+	return t.err
+}
+
+// Delete deletes the loaded texture, or stops it being uploaded if it is
+// still loading.
+func (t *PendingTexture) Delete() {
+	t.deleted = true
+	if t.texture != 0 {
+		gl.DeleteTextures(1, &t.texture)
+		t.texture = 0
+	}
+}
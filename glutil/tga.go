@@ -0,0 +1,172 @@
+package glutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TGA image types.
+const (
+	tgaColorMapped    = 1
+	tgaTrueColor      = 2
+	tgaGray           = 3
+	tgaRLE            = 8 // added to the types above
+	tgaTopToBottom    = 0x20
+	tgaRightToLeft    = 0x10
+	tgaAlphaBitsMask  = 0x0f
+	tgaHeaderLength   = 18
+	tgaMaxImageLength = 1 << 28
+)
+
+// DecodeTGA decodes a Truevision TGA image: colour-mapped, true-colour or
+// grayscale, raw or run-length encoded, at 8, 15, 16, 24 or 32 bits per
+// pixel.
+func DecodeTGA(r io.Reader) (image.Image, error) {
+	var h [tgaHeaderLength]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return nil, fmt.Errorf("tga: reading header: %v", err)
+	}
+	idLength := int(h[0])
+	colorMapType := h[1]
+	imageType := h[2]
+	mapFirst := int(binary.LittleEndian.Uint16(h[3:]))
+	mapLength := int(binary.LittleEndian.Uint16(h[5:]))
+	mapDepth := int(h[7])
+	width := int(binary.LittleEndian.Uint16(h[12:]))
+	height := int(binary.LittleEndian.Uint16(h[14:]))
+	depth := int(h[16])
+	descriptor := h[17]
+
+	rle := imageType&tgaRLE != 0
+	kind := imageType &^ tgaRLE
+	switch {
+	case kind == tgaColorMapped && depth == 8 && colorMapType == 1:
+	case kind == tgaTrueColor && (depth == 15 || depth == 16 || depth == 24 || depth == 32):
+	case kind == tgaGray && (depth == 8 || depth == 16):
+	default:
+		return nil, fmt.Errorf("tga: unsupported image type %d at %d bits per pixel", imageType, depth)
+	}
+	if width == 0 || height == 0 || width*height > tgaMaxImageLength {
+		return nil, fmt.Errorf("tga: bad size %dx%d", width, height)
+	}
+
+	if _, err := io.CopyN(io.Discard, r, int64(idLength)); err != nil {
+		return nil, fmt.Errorf("tga: reading image ID: %v", err)
+	}
+	var palette []color.NRGBA
+	if colorMapType == 1 {
+		entry := (mapDepth + 7) / 8
+		raw := make([]byte, mapLength*entry)
+		if _, err := io.ReadFull(r, raw); err != nil {
+			return nil, fmt.Errorf("tga: reading color map: %v", err)
+		}
+		for i := 0; i < mapLength; i++ {
+			palette = append(palette, tgaColor(raw[i*entry:], mapDepth, true))
+		}
+	}
+
+	bytesPerPixel := (depth + 7) / 8
+	data := make([]byte, width*height*bytesPerPixel)
+	var err error
+	if rle {
+		err = tgaReadRLE(r, data, bytesPerPixel)
+	} else {
+		_, err = io.ReadFull(r, data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tga: reading pixels: %v", err)
+	}
+
+	// 32-bit files that say they have no alpha bits often store garbage
+	// there.
+	hasAlpha := descriptor&tgaAlphaBitsMask != 0
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < width*height; i++ {
+		px := data[i*bytesPerPixel:]
+		var c color.NRGBA
+		switch kind {
+		case tgaColorMapped:
+			index := int(px[0]) - mapFirst
+			if index < 0 || index >= len(palette) {
+				return nil, errors.New("tga: color index out of range")
+			}
+			c = palette[index]
+		case tgaGray:
+			c = color.NRGBA{px[0], px[0], px[0], 0xff}
+			if depth == 16 {
+				c.A = px[1]
+			}
+		default:
+			c = tgaColor(px, depth, hasAlpha)
+		}
+
+		// Pixels are stored bottom row first unless the descriptor says
+		// otherwise.
+		x, y := i%width, i/width
+		if descriptor&tgaRightToLeft != 0 {
+			x = width - 1 - x
+		}
+		if descriptor&tgaTopToBottom == 0 {
+			y = height - 1 - y
+		}
+		img.SetNRGBA(x, y, c)
+	}
+	return img, nil
+}
+
+// tgaColor decodes a little-endian BGR(A) or 5-5-5(-1) pixel.
+func tgaColor(px []byte, depth int, hasAlpha bool) color.NRGBA {
+	switch depth {
+	case 15, 16:
+		v := binary.LittleEndian.Uint16(px)
+		expand := func(c uint16) uint8 { return uint8(c<<3 | c>>2) }
+		c := color.NRGBA{expand(v >> 10 & 0x1f), expand(v >> 5 & 0x1f), expand(v & 0x1f), 0xff}
+		if depth == 16 && hasAlpha && v&0x8000 == 0 {
+			c.A = 0
+		}
+		return c
+	case 24:
+		return color.NRGBA{px[2], px[1], px[0], 0xff}
+	case 32:
+		c := color.NRGBA{px[2], px[1], px[0], px[3]}
+		if !hasAlpha {
+			c.A = 0xff
+		}
+		return c
+	}
+	return color.NRGBA{}
+}
+
+// tgaReadRLE fills data from run-length encoded packets: a header byte
+// whose top bit marks a run, then one pixel repeated or the raw pixels.
+func tgaReadRLE(r io.Reader, data []byte, bytesPerPixel int) error {
+	var header [1]byte
+	pixel := make([]byte, bytesPerPixel)
+	for n := 0; n < len(data); {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return err
+		}
+		count := int(header[0]&0x7f) + 1
+		if n+count*bytesPerPixel > len(data) {
+			return errors.New("run-length packet overflows the image")
+		}
+		if header[0]&0x80 != 0 {
+			if _, err := io.ReadFull(r, pixel); err != nil {
+				return err
+			}
+			for i := 0; i < count; i++ {
+				n += copy(data[n:], pixel)
+			}
+		} else {
+			if _, err := io.ReadFull(r, data[n:n+count*bytesPerPixel]); err != nil {
+				return err
+			}
+			n += count * bytesPerPixel
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package glutil
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// UniformBuffer is a uniform buffer object holding a Go struct encoded
+// with Layout and attached to a binding point, from which any number of
+// programs can read it as the uniform block called Block.
+type UniformBuffer struct {
+	ID      uint32
+	Block   string
+	Binding uint32
+	Layout  Layout
+	Size    int
+}
+
+// NewUniformBuffer creates a buffer sized for v, uploads v and attaches
+// the buffer to binding.
+func NewUniformBuffer(block string, binding uint32, layout Layout, v interface{}) (*UniformBuffer, error) {
+	data, err := layout.Encode(v)
+	if err != nil {
+		return nil, fmt.Errorf("uniform block %s: %v", block, err)
+	}
+	b := &UniformBuffer{Block: block, Binding: binding, Layout: layout, Size: len(data)}
+
+	gl.GenBuffers(1, &b.ID)
+	gl.BindBuffer(gl.UNIFORM_BUFFER, b.ID)
+	gl.BufferData(gl.UNIFORM_BUFFER, len(data), gl.Ptr(data), gl.DYNAMIC_DRAW)
+	gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
+	gl.BindBufferBase(gl.UNIFORM_BUFFER, binding, b.ID)
+
+	return b, nil
+}
+
+// Update encodes v and replaces the buffer contents. v must have the type
+// the buffer was created with, or at least the same size.
+func (b *UniformBuffer) Update(v interface{}) error {
+	data, err := b.Layout.Encode(v)
+	if err != nil {
+		return fmt.Errorf("uniform block %s: %v", b.Block, err)
+	}
+	if len(data) != b.Size {
+		return fmt.Errorf("uniform block %s: %d bytes, buffer holds %d", b.Block, len(data), b.Size)
+	}
+	gl.BindBuffer(gl.UNIFORM_BUFFER, b.ID)
+	gl.BufferSubData(gl.UNIFORM_BUFFER, 0, len(data), gl.Ptr(data))
+	gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
+	return nil
+}
+
+// Attach points the program's block at the buffer's binding. It reports
+// whether the program declares the block, and warns if the block is
+// larger than the buffer, which usually means the Go struct and the GLSL
+// declaration have drifted apart.
+func (b *UniformBuffer) Attach(p *Program) bool {
+	block, ok := p.Block(b.Block)
+	if !ok {
+		return false
+	}
+	if int(block.Size) > b.Size {
+		log.Printf("uniform block %s in program %d is %d bytes, buffer holds %d", b.Block, p.ID, block.Size, b.Size)
+	}
+	gl.UniformBlockBinding(p.ID, block.Index, b.Binding)
+	for i := range p.Blocks {
+		if p.Blocks[i].Index == block.Index {
+			p.Blocks[i].Binding = b.Binding
+		}
+	}
+	return true
+}
+
+// Delete deletes the buffer.
+func (b *UniformBuffer) Delete() {
+	gl.DeleteBuffers(1, &b.ID)
+}
+
+// UniformBlocks hands out binding points to uniform buffers by block name
+// so programs can share them without agreeing on numbers up front.
+type UniformBlocks struct {
+	buffers []*UniformBuffer
+}
+
+// New creates a uniform buffer for block on the next free binding point.
+func (m *UniformBlocks) New(block string, layout Layout, v interface{}) (*UniformBuffer, error) {
+	if b := m.Get(block); b != nil {
+		return nil, fmt.Errorf("uniform block %s already has a buffer", block)
+	}
+	var max int32
+	gl.GetIntegerv(gl.MAX_UNIFORM_BUFFER_BINDINGS, &max)
+	if len(m.buffers) >= int(max) {
+		return nil, fmt.Errorf("uniform block %s: all %d binding points in use", block, max)
+	}
+	b, err := NewUniformBuffer(block, uint32(len(m.buffers)), layout, v)
+	if err != nil {
+		return nil, err
+	}
+	m.buffers = append(m.buffers, b)
+	return b, nil
+}
+
+// Get returns the buffer for block, or nil.
+func (m *UniformBlocks) Get(block string) *UniformBuffer {
+	for _, b := range m.buffers {
+		if b.Block == block {
+			return b
+		}
+	}
+	return nil
+}
+
+// Attach attaches every buffer to each program that declares its block,
+// and warns about blocks a program declares that have no buffer.
+func (m *UniformBlocks) Attach(programs ...*Program) {
+	for _, p := range programs {
+		for _, block := range p.Blocks {
+			if b := m.Get(block.Name); b != nil {
+				b.Attach(p)
+			} else {
+				log.Printf("uniform block %s in program %d has no buffer", block.Name, p.ID)
+			}
+		}
+	}
+}
+
+// Delete deletes every buffer.
+func (m *UniformBlocks) Delete() {
+	for _, b := range m.buffers {
+		b.Delete()
+	}
+	m.buffers = nil
+}
@@ -0,0 +1,140 @@
+package glutil
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+type glslKind int
+
+const (
+	kindFloat glslKind = iota
+	kindInt
+	kindUint
+	kindBool
+	kindSampler
+)
+
+// glslType describes a GLSL type as reported by glGetActiveUniform and
+// glGetActiveAttrib.
+type glslType struct {
+	name       string
+	kind       glslKind
+	components int // total scalars, e.g. 16 for mat4
+	columns    int // matrix columns, 0 for non-matrices
+}
+
+var glslTypes = map[uint32]glslType{
+	gl.FLOAT:      {"float", kindFloat, 1, 0},
+	gl.FLOAT_VEC2: {"vec2", kindFloat, 2, 0},
+	gl.FLOAT_VEC3: {"vec3", kindFloat, 3, 0},
+	gl.FLOAT_VEC4: {"vec4", kindFloat, 4, 0},
+
+	gl.INT:      {"int", kindInt, 1, 0},
+	gl.INT_VEC2: {"ivec2", kindInt, 2, 0},
+	gl.INT_VEC3: {"ivec3", kindInt, 3, 0},
+	gl.INT_VEC4: {"ivec4", kindInt, 4, 0},
+
+	gl.UNSIGNED_INT:      {"uint", kindUint, 1, 0},
+	gl.UNSIGNED_INT_VEC2: {"uvec2", kindUint, 2, 0},
+	gl.UNSIGNED_INT_VEC3: {"uvec3", kindUint, 3, 0},
+	gl.UNSIGNED_INT_VEC4: {"uvec4", kindUint, 4, 0},
+
+	gl.BOOL:      {"bool", kindBool, 1, 0},
+	gl.BOOL_VEC2: {"bvec2", kindBool, 2, 0},
+	gl.BOOL_VEC3: {"bvec3", kindBool, 3, 0},
+	gl.BOOL_VEC4: {"bvec4", kindBool, 4, 0},
+
+	gl.FLOAT_MAT2:   {"mat2", kindFloat, 4, 2},
+	gl.FLOAT_MAT3:   {"mat3", kindFloat, 9, 3},
+	gl.FLOAT_MAT4:   {"mat4", kindFloat, 16, 4},
+	gl.FLOAT_MAT2x3: {"mat2x3", kindFloat, 6, 2},
+	gl.FLOAT_MAT2x4: {"mat2x4", kindFloat, 8, 2},
+	gl.FLOAT_MAT3x2: {"mat3x2", kindFloat, 6, 3},
+	gl.FLOAT_MAT3x4: {"mat3x4", kindFloat, 12, 3},
+	gl.FLOAT_MAT4x2: {"mat4x2", kindFloat, 8, 4},
+	gl.FLOAT_MAT4x3: {"mat4x3", kindFloat, 12, 4},
+
+	gl.SAMPLER_1D:                {"sampler1D", kindSampler, 1, 0},
+	gl.SAMPLER_2D:                {"sampler2D", kindSampler, 1, 0},
+	gl.SAMPLER_3D:                {"sampler3D", kindSampler, 1, 0},
+	gl.SAMPLER_CUBE:              {"samplerCube", kindSampler, 1, 0},
+	gl.SAMPLER_2D_SHADOW:         {"sampler2DShadow", kindSampler, 1, 0},
+	gl.SAMPLER_2D_ARRAY:          {"sampler2DArray", kindSampler, 1, 0},
+	gl.SAMPLER_2D_MULTISAMPLE:    {"sampler2DMS", kindSampler, 1, 0},
+	gl.SAMPLER_BUFFER:            {"samplerBuffer", kindSampler, 1, 0},
+	gl.INT_SAMPLER_2D:            {"isampler2D", kindSampler, 1, 0},
+	gl.UNSIGNED_INT_SAMPLER_2D:   {"usampler2D", kindSampler, 1, 0},
+	gl.SAMPLER_CUBE_SHADOW:       {"samplerCubeShadow", kindSampler, 1, 0},
+	gl.SAMPLER_2D_ARRAY_SHADOW:   {"sampler2DArrayShadow", kindSampler, 1, 0},
+	gl.SAMPLER_CUBE_MAP_ARRAY:    {"samplerCubeArray", kindSampler, 1, 0},
+	gl.SAMPLER_2D_RECT:           {"sampler2DRect", kindSampler, 1, 0},
+	gl.INT_SAMPLER_3D:            {"isampler3D", kindSampler, 1, 0},
+	gl.UNSIGNED_INT_SAMPLER_3D:   {"usampler3D", kindSampler, 1, 0},
+	gl.INT_SAMPLER_CUBE:          {"isamplerCube", kindSampler, 1, 0},
+	gl.UNSIGNED_INT_SAMPLER_CUBE: {"usamplerCube", kindSampler, 1, 0},
+}
+
+// copyUniform reads the value of a uniform at location src in program from
+// and writes it to location dst in program to.
+func copyUniform(from uint32, src int32, to uint32, dst int32, t glslType) {
+	switch t.kind {
+	case kindFloat:
+		v := make([]float32, t.components)
+		gl.GetUniformfv(from, src, &v[0])
+		switch {
+		case t.columns == 0 && t.components == 1:
+			gl.ProgramUniform1fv(to, dst, 1, &v[0])
+		case t.columns == 0 && t.components == 2:
+			gl.ProgramUniform2fv(to, dst, 1, &v[0])
+		case t.columns == 0 && t.components == 3:
+			gl.ProgramUniform3fv(to, dst, 1, &v[0])
+		case t.columns == 0:
+			gl.ProgramUniform4fv(to, dst, 1, &v[0])
+		case t.name == "mat2":
+			gl.ProgramUniformMatrix2fv(to, dst, 1, false, &v[0])
+		case t.name == "mat3":
+			gl.ProgramUniformMatrix3fv(to, dst, 1, false, &v[0])
+		case t.name == "mat4":
+			gl.ProgramUniformMatrix4fv(to, dst, 1, false, &v[0])
+		case t.name == "mat2x3":
+			gl.ProgramUniformMatrix2x3fv(to, dst, 1, false, &v[0])
+		case t.name == "mat2x4":
+			gl.ProgramUniformMatrix2x4fv(to, dst, 1, false, &v[0])
+		case t.name == "mat3x2":
+			gl.ProgramUniformMatrix3x2fv(to, dst, 1, false, &v[0])
+		case t.name == "mat3x4":
+			gl.ProgramUniformMatrix3x4fv(to, dst, 1, false, &v[0])
+		case t.name == "mat4x2":
+			gl.ProgramUniformMatrix4x2fv(to, dst, 1, false, &v[0])
+		case t.name == "mat4x3":
+			gl.ProgramUniformMatrix4x3fv(to, dst, 1, false, &v[0])
+		}
+	case kindUint:
+		v := make([]uint32, t.components)
+		gl.GetUniformuiv(from, src, &v[0])
+		switch t.components {
+		case 1:
+			gl.ProgramUniform1uiv(to, dst, 1, &v[0])
+		case 2:
+			gl.ProgramUniform2uiv(to, dst, 1, &v[0])
+		case 3:
+			gl.ProgramUniform3uiv(to, dst, 1, &v[0])
+		case 4:
+			gl.ProgramUniform4uiv(to, dst, 1, &v[0])
+		}
+	default:
+		// Ints, bools and sampler units are all set through the int calls.
+		v := make([]int32, t.components)
+		gl.GetUniformiv(from, src, &v[0])
+		switch t.components {
+		case 1:
+			gl.ProgramUniform1iv(to, dst, 1, &v[0])
+		case 2:
+			gl.ProgramUniform2iv(to, dst, 1, &v[0])
+		case 3:
+			gl.ProgramUniform3iv(to, dst, 1, &v[0])
+		case 4:
+			gl.ProgramUniform4iv(to, dst, 1, &v[0])
+		}
+	}
+}
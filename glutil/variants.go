@@ -0,0 +1,104 @@
+package glutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ShaderVariants compiles one vertex and fragment source into programs
+// specialised by feature flags, each flag becoming a #define, and caches
+// them by flag set. Asking for the same features twice, in any order,
+// returns the same *Program.
+//
+//	variants := glutil.NewShaderVariants(loader, "uber.vert", "uber.frag", "TEXTURED", "LIT")
+//	lit, err := variants.Get("LIT", "TEXTURED")
+type ShaderVariants struct {
+	Loader                   *ShaderLoader
+	VertexFile, FragmentFile string
+	// Features lists the flags the sources understand. If it is not empty,
+	// Get rejects any other flag, which catches typos that would otherwise
+	// silently compile the wrong variant.
+	Features []string
+
+	programs map[string]*Program
+}
+
+// NewShaderVariants returns an empty cache for the given sources.
+func NewShaderVariants(loader *ShaderLoader, vertexFile, fragmentFile string, features ...string) *ShaderVariants {
+	return &ShaderVariants{
+		Loader:       loader,
+		VertexFile:   vertexFile,
+		FragmentFile: fragmentFile,
+		Features:     features,
+		programs:     map[string]*Program{},
+	}
+}
+
+// Get returns the program compiled with the given features defined,
+// building it on first use.
+func (v *ShaderVariants) Get(features ...string) (*Program, error) {
+	key, err := v.key(features)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := v.programs[key]; ok {
+		return p, nil
+	}
+
+	loader := *v.Loader
+	loader.Defines = map[string]string{}
+	for name, value := range v.Loader.Defines {
+		loader.Defines[name] = value
+	}
+	for _, f := range features {
+		loader.Defines[f] = ""
+	}
+	id, err := loader.NewProgram(v.VertexFile, v.FragmentFile)
+	if err != nil {
+		return nil, fmt.Errorf("variant [%s]: %v", key, err)
+	}
+	p := Reflect(id)
+	v.programs[key] = p
+	return p, nil
+}
+
+// Len returns the number of compiled variants.
+func (v *ShaderVariants) Len() int {
+	return len(v.programs)
+}
+
+// Delete deletes every compiled variant.
+func (v *ShaderVariants) Delete() {
+	for key, p := range v.programs {
+		p.Delete()
+		delete(v.programs, key)
+	}
+}
+
+// key returns the sorted, de-duplicated feature list joined by commas.
+func (v *ShaderVariants) key(features []string) (string, error) {
+	seen := map[string]bool{}
+	var set []string
+	for _, f := range features {
+		if len(v.Features) > 0 && !contains(v.Features, f) {
+			return "", fmt.Errorf("%s + %s: unknown feature %q, want one of %s",
+				v.VertexFile, v.FragmentFile, f, strings.Join(v.Features, ", "))
+		}
+		if !seen[f] {
+			seen[f] = true
+			set = append(set, f)
+		}
+	}
+	sort.Strings(set)
+	return strings.Join(set, ","), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+// Package glutil holds the window, shader program, texture and vertex
+// buffer helpers shared by the examples.
+package glutil
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// NewWindow initializes GLFW, opens a window with an OpenGL 4.1 core
+// context, makes it current and loads the GL function pointers.
+// The caller owns GLFW afterwards and must call glfw.Terminate.
+func NewWindow(width, height int, title string) (*glfw.Window, error) {
+	return newWindow(width, height, title, false)
+}
+
+func newWindow(width, height int, title string, resizable bool) (*glfw.Window, error) {
+	if err := glfw.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize glfw: %v", err)
+	}
+
+	if resizable {
+		glfw.WindowHint(glfw.Resizable, glfw.True)
+	} else {
+		glfw.WindowHint(glfw.Resizable, glfw.False)
+	}
+	glfw.WindowHint(glfw.ContextVersionMajor, 4)
+	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	window, err := glfw.CreateWindow(width, height, title, nil, nil)
+	if err != nil {
+		glfw.Terminate()
+		return nil, err
+	}
+	window.MakeContextCurrent()
+
+	// Initialize Glow
+	if err := gl.Init(); err != nil {
+		glfw.Terminate()
+		return nil, err
+	}
+
+	version := gl.GoStr(gl.GetString(gl.VERSION))
+	fmt.Println("OpenGL version", version)
+
+	return window, nil
+}
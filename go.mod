@@ -0,0 +1,9 @@
+module github.com/henghuang/opengl-go
+
+go 1.16
+
+require (
+	github.com/go-gl/gl v0.0.0-20231021071112-07e5d0ea2e71
+	github.com/go-gl/glfw v0.0.0-20190409004039-e6da0acd62b1
+	github.com/go-gl/mathgl v1.2.0
+)